@@ -0,0 +1,19 @@
+package main
+
+import (
+	app "Dormitory_Booking/internal/application"
+	"context"
+	"log"
+	"os/signal"
+	"syscall"
+	_ "time/tzdata" // Europe/Moscow и в контейнере без системной базы часовых поясов
+)
+
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := app.RunBot(ctx); err != nil {
+		log.Fatalf("bot stopped with error: %v", err)
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	app "Dormitory_Booking/internal/application"
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// migrate up | down [шагов] | status | baseline <версия>
+func main() {
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if err := app.RunMigrate(ctx, os.Args[1:]); err != nil {
+		log.Fatalf("migrate: %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os/signal"
 	"syscall"
+	_ "time/tzdata" // Europe/Moscow для уведомлений и в контейнере без системной базы часовых поясов
 )
 
 func main() {
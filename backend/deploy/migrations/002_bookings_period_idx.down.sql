@@ -0,0 +1 @@
+DROP INDEX IF EXISTS bookings_period_idx;
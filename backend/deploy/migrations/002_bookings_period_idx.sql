@@ -0,0 +1,4 @@
+-- Индекс для выборки броней по диапазону времени без фильтра по комнате
+-- (у room_time_no_overlap первым идёт room, для таких запросов он не подходит).
+CREATE INDEX IF NOT EXISTS bookings_period_idx
+    ON bookings USING gist (tstzrange(start_at, end_at, '[)'));
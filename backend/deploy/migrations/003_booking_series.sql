@@ -0,0 +1,20 @@
+-- Повторяющиеся брони: родительская запись серии, занятия ссылаются на неё из bookings.
+CREATE TABLE IF NOT EXISTS booking_series (
+    id           TEXT PRIMARY KEY,
+    start_at     TIMESTAMPTZ NOT NULL,
+    end_at       TIMESTAMPTZ NOT NULL,
+    room         INTEGER NOT NULL,
+    title        TEXT NOT NULL,
+    description  TEXT,
+    telegram_id  TEXT NOT NULL,
+    is_private   BOOLEAN NOT NULL DEFAULT false,
+    freq         TEXT NOT NULL CHECK (freq IN ('weekly', 'biweekly')),
+    weekdays     INTEGER[] NOT NULL DEFAULT '{}',
+    until_at     TIMESTAMPTZ,
+    occurrences  INTEGER NOT NULL DEFAULT 0
+);
+
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS series_id TEXT REFERENCES booking_series(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS bookings_series_idx ON bookings(series_id);
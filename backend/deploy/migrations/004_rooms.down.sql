@@ -0,0 +1,6 @@
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_fk;
+DROP TABLE IF EXISTS rooms;
+
+-- NOT VALID: брони в комнатах не из старого списка откат не ломают
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_room_check CHECK (room IN (21,132,256)) NOT VALID;
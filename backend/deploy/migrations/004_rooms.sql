@@ -0,0 +1,23 @@
+-- Каталог комнат вместо CHECK (room IN (21,132,256)).
+-- schedule - часы работы в виде смещений от полуночи, закрытие может быть > 24.
+CREATE TABLE IF NOT EXISTS rooms (
+    number     INTEGER PRIMARY KEY CHECK (number > 0),
+    name       TEXT NOT NULL,
+    building   TEXT,
+    floor      INTEGER NOT NULL DEFAULT 0,
+    capacity   INTEGER NOT NULL DEFAULT 0 CHECK (capacity >= 0),
+    amenities  TEXT[] NOT NULL DEFAULT '{}',
+    active     BOOLEAN NOT NULL DEFAULT true,
+    schedule   JSONB NOT NULL
+);
+
+INSERT INTO rooms (number, name, floor, schedule) VALUES
+    (21,  'Досуговая 21',  0, '{"weekdayOpen":6,"weekdayClose":23,"friSatOpen":6,"friSatClose":25,"sunOpen":6,"sunClose":23}'),
+    (132, 'Досуговая 132', 1, '{"weekdayOpen":6,"weekdayClose":22,"friSatOpen":6,"friSatClose":23,"sunOpen":6,"sunClose":22}'),
+    (256, 'Досуговая 256', 2, '{"weekdayOpen":6,"weekdayClose":23,"friSatOpen":6,"friSatClose":25,"sunOpen":6,"sunClose":23}')
+ON CONFLICT (number) DO NOTHING;
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_check;
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_room_fk FOREIGN KEY (room) REFERENCES rooms(number);
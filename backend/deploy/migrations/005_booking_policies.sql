@@ -0,0 +1,6 @@
+-- Версии политики бронирования. Действует последняя, пока таблица пустая - правила по умолчанию из кода.
+CREATE TABLE IF NOT EXISTS booking_policies (
+    version     SERIAL PRIMARY KEY,
+    body        JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
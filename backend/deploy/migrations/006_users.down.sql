@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS login_codes;
+DROP TABLE IF EXISTS users;
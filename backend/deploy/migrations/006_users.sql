@@ -0,0 +1,15 @@
+-- Пользователи, вошедшие по корпоративной почте, и одноразовые коды входа.
+CREATE TABLE IF NOT EXISTS users (
+    id          UUID PRIMARY KEY,
+    email       TEXT NOT NULL UNIQUE,
+    telegram_id TEXT UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Храним только sha256 от кода; на одну почту - один действующий код.
+CREATE TABLE IF NOT EXISTS login_codes (
+    email       TEXT PRIMARY KEY,
+    code_hash   TEXT NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    attempts    INT NOT NULL DEFAULT 0
+);
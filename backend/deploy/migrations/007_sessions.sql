@@ -0,0 +1,10 @@
+-- Выданные сессии. Cookie подписан, но проверяется ещё и по этой таблице, чтобы сессию можно было отозвать.
+CREATE TABLE IF NOT EXISTS sessions (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_idx ON sessions (user_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS notification_prefs;
+DROP TABLE IF EXISTS notification_outbox;
+ALTER TABLE users DROP COLUMN IF EXISTS telegram_chat_id;
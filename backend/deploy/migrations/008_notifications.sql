@@ -0,0 +1,35 @@
+-- Чат с ботом: бот узнаёт его из первого сообщения пользователя.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS telegram_chat_id BIGINT;
+
+-- Outbox уведомлений. dedup_key не даёт поставить одно и то же напоминание дважды,
+-- claimed_until - чтобы два экземпляра не отправили одно сообщение одновременно.
+CREATE TABLE IF NOT EXISTS notification_outbox (
+    id            UUID PRIMARY KEY,
+    dedup_key     TEXT NOT NULL UNIQUE,
+    kind          TEXT NOT NULL,
+    channel       TEXT NOT NULL,
+    recipient     TEXT NOT NULL,
+    booking_id    TEXT NOT NULL DEFAULT '', -- без FK: бронь могут удалить, а извещение об этом должно уйти
+    subject       TEXT NOT NULL DEFAULT '',
+    body          TEXT NOT NULL,
+    send_after    TIMESTAMPTZ NOT NULL,
+    attempts      INT NOT NULL DEFAULT 0,
+    last_error    TEXT NOT NULL DEFAULT '',
+    claimed_until TIMESTAMPTZ,
+    sent_at       TIMESTAMPTZ,
+    failed_at     TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS notification_outbox_due_idx
+    ON notification_outbox (send_after)
+    WHERE sent_at IS NULL AND failed_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS notification_prefs (
+    user_id          UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    telegram         BOOLEAN NOT NULL,
+    email            BOOLEAN NOT NULL,
+    webhook_url      TEXT NOT NULL DEFAULT '',
+    reminders        BOOLEAN NOT NULL,
+    reminder_minutes INT NOT NULL
+);
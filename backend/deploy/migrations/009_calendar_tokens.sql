@@ -0,0 +1,2 @@
+-- Секрет в адресе личной подписки на календарь (GET /calendar/{token}.ics).
+ALTER TABLE users ADD COLUMN IF NOT EXISTS calendar_token TEXT UNIQUE;
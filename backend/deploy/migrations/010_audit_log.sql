@@ -0,0 +1,31 @@
+-- Журнал изменений броней. Только добавление: UPDATE, DELETE и TRUNCATE запрещены триггерами.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          UUID PRIMARY KEY,
+    at          TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor       TEXT NOT NULL DEFAULT '',
+    admin       BOOLEAN NOT NULL DEFAULT false,
+    action      TEXT NOT NULL,
+    booking_id  TEXT NOT NULL,
+    before      JSONB,
+    after       JSONB,
+    ip          TEXT NOT NULL DEFAULT '',
+    user_agent  TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_at_idx      ON audit_log (at DESC);
+CREATE INDEX IF NOT EXISTS audit_log_booking_idx ON audit_log (booking_id);
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx   ON audit_log (actor);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log: записи журнала нельзя менять и удалять';
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_log_no_update
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+CREATE TRIGGER audit_log_no_truncate
+    BEFORE TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
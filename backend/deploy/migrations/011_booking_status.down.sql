@@ -0,0 +1,16 @@
+-- История отмен при откате теряется: без статуса отменённые брони снова заняли бы время.
+DELETE FROM bookings WHERE status = 'cancelled';
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS room_time_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT room_time_no_overlap
+    EXCLUDE USING gist (
+        room WITH =,
+        tstzrange(start_at, end_at, '[)') WITH &&
+    );
+
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS cancel_reason,
+    DROP COLUMN IF EXISTS cancelled_by,
+    DROP COLUMN IF EXISTS cancelled_at,
+    DROP COLUMN IF EXISTS status;
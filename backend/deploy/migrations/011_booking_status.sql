@@ -0,0 +1,16 @@
+-- Мягкое удаление: отменённая бронь остаётся в таблице со статусом cancelled.
+-- completed не хранится - это активная бронь, которая уже закончилась.
+ALTER TABLE bookings
+    ADD COLUMN IF NOT EXISTS status        TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'cancelled')),
+    ADD COLUMN IF NOT EXISTS cancelled_at  TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS cancelled_by  TEXT,
+    ADD COLUMN IF NOT EXISTS cancel_reason TEXT;
+
+-- отменённые брони время не занимают
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS room_time_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT room_time_no_overlap
+    EXCLUDE USING gist (
+        room WITH =,
+        tstzrange(start_at, end_at, '[)') WITH &&
+    ) WHERE (status <> 'cancelled');
@@ -0,0 +1,23 @@
+-- Очередь ожидания на занятое время. Когда время освобождается, первая подходящая
+-- заявка получает status = 'offered' и держит слот до hold_until.
+CREATE TABLE IF NOT EXISTS waitlist (
+    id           TEXT PRIMARY KEY,
+    start_at     TIMESTAMPTZ NOT NULL,
+    end_at       TIMESTAMPTZ NOT NULL,
+    room         INTEGER NOT NULL REFERENCES rooms(number),
+    title        TEXT NOT NULL,
+    description  TEXT,
+    telegram_id  TEXT NOT NULL,
+    is_private   BOOLEAN NOT NULL DEFAULT false,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    status       TEXT NOT NULL DEFAULT 'waiting'
+                 CHECK (status IN ('waiting', 'offered', 'confirmed', 'expired', 'withdrawn')),
+    hold_until   TIMESTAMPTZ,
+    booking_id   TEXT
+);
+
+-- живые заявки комнаты в порядке очереди
+CREATE INDEX IF NOT EXISTS waitlist_room_idx
+    ON waitlist (room, created_at)
+    WHERE status IN ('waiting', 'offered');
+CREATE INDEX IF NOT EXISTS waitlist_owner_idx ON waitlist (telegram_id);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS no_shows;
+ALTER TABLE rooms DROP COLUMN IF EXISTS checkin_token;
+ALTER TABLE bookings DROP COLUMN IF EXISTS checked_in_at;
@@ -0,0 +1,16 @@
+-- Отметка о приходе и учёт неявок.
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS checked_in_at TIMESTAMPTZ;
+
+-- Секрет в QR-коде на двери комнаты.
+ALTER TABLE rooms ADD COLUMN IF NOT EXISTS checkin_token TEXT UNIQUE;
+
+CREATE TABLE IF NOT EXISTS no_shows (
+    id           TEXT PRIMARY KEY,
+    telegram_id  TEXT NOT NULL,
+    booking_id   TEXT NOT NULL UNIQUE,
+    room         INTEGER NOT NULL,
+    start_at     TIMESTAMPTZ NOT NULL,
+    recorded_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS no_shows_owner_idx ON no_shows (telegram_id, start_at);
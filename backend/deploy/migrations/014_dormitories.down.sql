@@ -0,0 +1,37 @@
+-- Откат возможен, только пока все данные лежат в общежитии 'main':
+-- иначе номера комнат разных общежитий столкнутся в старом ключе.
+DROP INDEX IF EXISTS audit_log_dormitory_idx;
+DROP INDEX IF EXISTS booking_policies_dormitory_idx;
+DROP INDEX IF EXISTS bookings_dormitory_owner_idx;
+
+DROP INDEX IF EXISTS waitlist_room_idx;
+CREATE INDEX IF NOT EXISTS waitlist_room_idx
+    ON waitlist (room, created_at)
+    WHERE status IN ('waiting', 'offered');
+
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS room_time_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT room_time_no_overlap
+    EXCLUDE USING gist (
+        room WITH =,
+        tstzrange(start_at, end_at, '[)') WITH &&
+    ) WHERE (status <> 'cancelled');
+
+ALTER TABLE waitlist DROP CONSTRAINT IF EXISTS waitlist_room_fkey;
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_fk;
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_pkey;
+ALTER TABLE rooms ADD CONSTRAINT rooms_pkey PRIMARY KEY (number);
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_room_fk FOREIGN KEY (room) REFERENCES rooms(number);
+ALTER TABLE waitlist
+    ADD CONSTRAINT waitlist_room_fkey FOREIGN KEY (room) REFERENCES rooms(number);
+
+ALTER TABLE audit_log        DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE no_shows         DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE waitlist         DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE booking_policies DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE booking_series   DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE bookings         DROP COLUMN IF EXISTS dormitory_id;
+ALTER TABLE rooms            DROP COLUMN IF EXISTS dormitory_id;
+
+DROP TABLE IF EXISTS dormitories;
@@ -0,0 +1,52 @@
+-- Несколько общежитий. Номера комнат уникальны только внутри общежития, поэтому
+-- dormitory_id входит в ключ комнат, в ссылки на них и в exclusion constraint броней.
+-- Всё, что было до этой миграции, относится к общежитию 'main'.
+CREATE TABLE IF NOT EXISTS dormitories (
+    id        TEXT PRIMARY KEY CHECK (id ~ '^[a-z0-9][a-z0-9-]{0,31}$'),
+    name      TEXT NOT NULL,
+    hosts     TEXT[] NOT NULL DEFAULT '{}',
+    timezone  TEXT NOT NULL DEFAULT '', -- пусто - пояс из DORM_TIMEZONE
+    admins    TEXT[] NOT NULL DEFAULT '{}'
+);
+
+INSERT INTO dormitories (id, name) VALUES ('main', 'Общежитие')
+ON CONFLICT (id) DO NOTHING;
+
+ALTER TABLE rooms            ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+ALTER TABLE bookings         ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+ALTER TABLE booking_series   ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+ALTER TABLE booking_policies ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+ALTER TABLE waitlist         ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+ALTER TABLE no_shows         ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main' REFERENCES dormitories(id);
+-- журнал только на добавление: новая колонка с DEFAULT строки не переписывает и триггеры не будит
+ALTER TABLE audit_log        ADD COLUMN IF NOT EXISTS dormitory_id TEXT NOT NULL DEFAULT 'main';
+
+-- ключ комнаты - (общежитие, номер)
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS bookings_room_fk;
+ALTER TABLE waitlist DROP CONSTRAINT IF EXISTS waitlist_room_fkey;
+ALTER TABLE rooms DROP CONSTRAINT IF EXISTS rooms_pkey;
+ALTER TABLE rooms ADD CONSTRAINT rooms_pkey PRIMARY KEY (dormitory_id, number);
+
+ALTER TABLE bookings
+    ADD CONSTRAINT bookings_room_fk FOREIGN KEY (dormitory_id, room) REFERENCES rooms(dormitory_id, number);
+ALTER TABLE waitlist
+    ADD CONSTRAINT waitlist_room_fkey FOREIGN KEY (dormitory_id, room) REFERENCES rooms(dormitory_id, number);
+
+-- в разных общежитиях одна и та же комната 21 - это разные комнаты
+ALTER TABLE bookings DROP CONSTRAINT IF EXISTS room_time_no_overlap;
+ALTER TABLE bookings
+    ADD CONSTRAINT room_time_no_overlap
+    EXCLUDE USING gist (
+        dormitory_id WITH =,
+        room WITH =,
+        tstzrange(start_at, end_at, '[)') WITH &&
+    ) WHERE (status <> 'cancelled');
+
+DROP INDEX IF EXISTS waitlist_room_idx;
+CREATE INDEX IF NOT EXISTS waitlist_room_idx
+    ON waitlist (dormitory_id, room, created_at)
+    WHERE status IN ('waiting', 'offered');
+
+CREATE INDEX IF NOT EXISTS bookings_dormitory_owner_idx ON bookings (dormitory_id, telegram_id);
+CREATE INDEX IF NOT EXISTS booking_policies_dormitory_idx ON booking_policies (dormitory_id, version DESC);
+CREATE INDEX IF NOT EXISTS audit_log_dormitory_idx ON audit_log (dormitory_id, at DESC);
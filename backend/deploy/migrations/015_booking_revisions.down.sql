@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS booking_changes_horizon;
+
+DROP TRIGGER IF EXISTS bookings_revision_update ON bookings;
+DROP TRIGGER IF EXISTS bookings_revision_insert ON bookings;
+DROP FUNCTION IF EXISTS bookings_next_revision();
+
+DROP INDEX IF EXISTS bookings_revision_idx;
+ALTER TABLE bookings DROP COLUMN IF EXISTS revision;
+DROP SEQUENCE IF EXISTS booking_revision_seq;
@@ -0,0 +1,38 @@
+-- Ревизии броней для инкрементальной синхронизации (GET /bookings/changes).
+-- Каждая запись в bookings получает следующий номер из общей последовательности;
+-- отменённые брони остаются надгробиями, пока их не удалит очистка.
+CREATE SEQUENCE IF NOT EXISTS booking_revision_seq;
+
+ALTER TABLE bookings ADD COLUMN IF NOT EXISTS revision BIGINT;
+UPDATE bookings SET revision = nextval('booking_revision_seq') WHERE revision IS NULL;
+ALTER TABLE bookings ALTER COLUMN revision SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS bookings_revision_idx ON bookings (dormitory_id, revision);
+
+-- Ревизия выдаётся под общей блокировкой, которая держится до конца транзакции.
+-- Так ревизии видны строго по возрастанию: клиент, получивший N, уже не пропустит
+-- запоздавший коммит с N-1.
+CREATE OR REPLACE FUNCTION bookings_next_revision() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('booking_revision'));
+    NEW.revision := nextval('booking_revision_seq');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bookings_revision_insert
+    BEFORE INSERT ON bookings
+    FOR EACH ROW EXECUTE FUNCTION bookings_next_revision();
+
+-- повторная отметка о приходе ничего не меняет и ревизию не тратит
+CREATE TRIGGER bookings_revision_update
+    BEFORE UPDATE ON bookings
+    FOR EACH ROW WHEN (OLD.* IS DISTINCT FROM NEW.*)
+    EXECUTE FUNCTION bookings_next_revision();
+
+-- Горизонт общежития: ревизия последнего удалённого надгробия. Курсор ниже горизонта
+-- уже не восстановить, клиенту нужна полная синхронизация.
+CREATE TABLE IF NOT EXISTS booking_changes_horizon (
+    dormitory_id TEXT PRIMARY KEY REFERENCES dormitories (id),
+    revision     BIGINT NOT NULL DEFAULT 0
+);
@@ -0,0 +1,9 @@
+-- Неподтверждённые привязки Telegram. Сайт выдаёт одноразовый код, привязка
+-- сохраняется в users, только когда код пришлют боту с того же ника.
+-- Храним только sha256 от кода; на пользователя - один действующий запрос.
+CREATE TABLE IF NOT EXISTS telegram_links (
+    code_hash   TEXT PRIMARY KEY,
+    user_id     UUID NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    telegram_id TEXT NOT NULL,
+    expires_at  TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1 @@
+ALTER TABLE booking_series DROP COLUMN IF EXISTS ended_at;
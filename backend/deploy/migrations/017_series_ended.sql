@@ -0,0 +1,3 @@
+-- Отменённая целиком серия не удаляется, а помечается завершённой: иначе
+-- ON DELETE SET NULL стёр бы series_id у прошедших занятий в истории.
+ALTER TABLE booking_series ADD COLUMN IF NOT EXISTS ended_at TIMESTAMPTZ;
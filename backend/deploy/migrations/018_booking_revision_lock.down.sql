@@ -0,0 +1,7 @@
+CREATE OR REPLACE FUNCTION bookings_next_revision() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('booking_revision'));
+    NEW.revision := nextval('booking_revision_seq');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
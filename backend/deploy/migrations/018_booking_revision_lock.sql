@@ -0,0 +1,17 @@
+-- Блокировка выдачи ревизий теперь своя у каждого общежития, а не одна на всю базу.
+-- Она держится до конца транзакции, поэтому с общей блокировкой любая запись в bookings
+-- ждала коммита любой другой, даже в чужом общежитии. Теперь последовательны только
+-- записи внутри общежития, а разные общежития пишут параллельно.
+--
+-- Ревизии по-прежнему берутся из общей последовательности и уникальны, но по возрастанию
+-- коммитятся только в пределах общежития. Журнал изменений (GET /bookings/changes)
+-- всегда читается по одному общежитию, так что курсор ничего не пропускает; выборка
+-- по всем общежитиям сразу такой гарантии не даёт.
+CREATE OR REPLACE FUNCTION bookings_next_revision() RETURNS trigger AS $$
+BEGIN
+    -- dormitory_id у брони не меняется, так что и UPDATE берёт блокировку своего общежития
+    PERFORM pg_advisory_xact_lock(hashtext('booking_revision/' || NEW.dormitory_id));
+    NEW.revision := nextval('booking_revision_seq');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,8 @@
+// Package migrations - SQL-миграции схемы, вшитые в бинарник.
+// NNN_name.sql накатывает версию NNN, NNN_name.down.sql откатывает её.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
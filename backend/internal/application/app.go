@@ -3,18 +3,14 @@ package app
 // В этом файле основная точка запуска backend-приложения.
 
 import (
-	appbooking "Dormitory_Booking/internal/application/booking"
-	domainbooking "Dormitory_Booking/internal/domain/booking"
-	"Dormitory_Booking/internal/infrastructure/memory"
-	pgrepo "Dormitory_Booking/internal/infrastructure/postgres"
 	"Dormitory_Booking/internal/infrastructure/server"
 	"context"
 	"log"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
-	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/joho/godotenv"
 )
 
@@ -24,27 +20,35 @@ func Run(ctx context.Context) error {
 	_ = godotenv.Load()
 
 	addr := getEnv("HTTP_ADDR", ":8080")
-	dbURL := os.Getenv("DB_URL") // если пусто — работаем в in-memory режиме
 
-	var repo domainbooking.Repository
-	var pool *pgxpool.Pool
-	var err error
+	c, err := build(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
 
-	if dbURL != "" {
-		log.Printf("using Postgres repo: %s\n", dbURL)
-		pool, err = pgxpool.New(ctx, dbURL)
-		if err != nil {
-			return err
-		}
-		defer pool.Close()
-		repo = pgrepo.NewBookingPostgresRepo(pool)
-	} else {
-		log.Println("DB_URL не задан, используем in-memory репозиторий (dev mode)")
-		repo = memory.NewInMemoryBookingRepo()
+	// напоминания и извещения разносит HTTP-процесс; бот только пишет в outbox
+	go c.notify.Run(ctx)
+	go c.dorms.RunWaitlist(ctx, time.Minute)
+	go c.dorms.RunNoShows(ctx, time.Minute)
+	go c.dorms.RunTombstones(ctx, time.Hour, tombstoneRetention())
+	if c.listen != nil {
+		go c.listen(ctx)
+	}
+
+	// за nginx адрес клиента берётся из X-Forwarded-For, но только от этих адресов
+	proxies, err := server.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return err
 	}
 
-	svc := appbooking.NewService(repo)
-	handler := server.NewRouter(svc)
+	handler := server.NewRouter(c.bookings, c.auth,
+		server.WithTrustedProxies(proxies),
+		server.WithNotifications(c.notify),
+		server.WithLocation(c.loc),
+		server.WithDormitories(c.dorms),
+		server.WithEvents(c.events),
+	)
 
 	srv := &http.Server{
 		Addr:         addr,
@@ -78,6 +82,17 @@ func Run(ctx context.Context) error {
 	return nil
 }
 
+// splitList разбирает список через запятую: "a@edu.hse.ru, b@edu.hse.ru".
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
 func getEnv(key, def string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
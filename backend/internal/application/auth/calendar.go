@@ -0,0 +1,39 @@
+package auth
+
+// В этом файле токены личной подписки на календарь. Календарные приложения не умеют
+// в cookie, поэтому пользователь опознаётся по длинному случайному токену в адресе.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+
+	domain "Dormitory_Booking/internal/domain/user"
+)
+
+// CalendarToken возвращает токен подписки пользователя, при необходимости создаёт его.
+// reset - выпустить новый токен, старая ссылка перестанет работать.
+func (s *Service) CalendarToken(ctx context.Context, userID string, reset bool) (string, error) {
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if u.CalendarToken != "" && !reset {
+		return u.CalendarToken, nil
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	u.CalendarToken = base64.RawURLEncoding.EncodeToString(buf)
+	if _, err := s.users.Update(ctx, u); err != nil {
+		return "", err
+	}
+	return u.CalendarToken, nil
+}
+
+// UserByCalendarToken находит владельца подписки.
+func (s *Service) UserByCalendarToken(ctx context.Context, token string) (domain.User, error) {
+	return s.users.GetByCalendarToken(ctx, token)
+}
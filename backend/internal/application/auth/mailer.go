@@ -0,0 +1,17 @@
+package auth
+
+// В этом файле интерфейс отправки писем. Реализации (SMTP, лог) лежат в infrastructure/mail.
+
+import "context"
+
+// Message - простое текстовое письмо.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer отправляет письма со ссылками и кодами входа.
+type Mailer interface {
+	Send(ctx context.Context, m Message) error
+}
@@ -0,0 +1,260 @@
+package auth
+
+// В этом файле сервис входа по корпоративной почте: код на почту, проверка кода, сессия.
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+const (
+	codeTTL        = 15 * time.Minute
+	linkTTL        = 15 * time.Minute
+	maxCodeAttempt = 5
+	sessionTTL     = 30 * 24 * time.Hour
+
+	// не больше 5 писем и 10 неверных кодов на почту в час
+	maxLoginRequests = 5
+	maxLoginFailures = 10
+	throttleWindow   = time.Hour
+)
+
+// Config - настройки входа.
+type Config struct {
+	EmailDomain   string   // например edu.hse.ru
+	SessionSecret []byte   // ключ подписи сессий
+	VerifyURL     string   // куда ведёт ссылка из письма, к ней добавляются ?email=&code=
+	Admins        []string // почты администраторов (env ADMINS)
+	TelegramBot   string   // имя бота без @, для ссылки t.me/<бот>?start=<код>
+}
+
+type Service struct {
+	users    domain.Repository
+	codes    domain.LoginCodeRepository
+	sessions domain.SessionRepository
+	links    domain.TelegramLinkRepository
+	mailer   Mailer
+	cfg      Config
+	signer   signer
+	admins   map[string]bool
+	requests *Throttle // письма с кодом по почте
+	failures *Throttle // неверные коды по почте
+	now      func() time.Time
+}
+
+func NewService(users domain.Repository, codes domain.LoginCodeRepository, sessions domain.SessionRepository, links domain.TelegramLinkRepository, mailer Mailer, cfg Config) *Service {
+	admins := make(map[string]bool, len(cfg.Admins))
+	for _, email := range cfg.Admins {
+		if email = domain.NormalizeEmail(email); email != "" {
+			admins[email] = true
+		}
+	}
+	return &Service{
+		users:    users,
+		codes:    codes,
+		sessions: sessions,
+		links:    links,
+		mailer:   mailer,
+		cfg:      cfg,
+		signer:   signer{secret: cfg.SessionSecret},
+		admins:   admins,
+		requests: NewThrottle(maxLoginRequests, throttleWindow),
+		failures: NewThrottle(maxLoginFailures, throttleWindow),
+		now:      time.Now,
+	}
+}
+
+// Session - выданная сессия. Token кладётся в cookie.
+type Session struct {
+	Token     string
+	ExpiresAt time.Time
+	User      domain.User
+}
+
+// RequestLogin отправляет на почту одноразовый код и ссылку для входа.
+// Повторный запрос заменяет старый код.
+func (s *Service) RequestLogin(ctx context.Context, email string) error {
+	email = domain.NormalizeEmail(email)
+	if !s.allowedEmail(email) {
+		return domain.ErrInvalidEmail
+	}
+	if !s.requests.Allow(email) {
+		return domain.ErrTooManyAttempts
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return err
+	}
+	if err := s.codes.Save(ctx, domain.LoginCode{
+		Email:     email,
+		CodeHash:  hashCode(code),
+		ExpiresAt: s.now().Add(codeTTL),
+	}); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("Код для входа в бронирование досуговых: %s\nКод действует %d минут.\n", code, int(codeTTL.Minutes()))
+	if s.cfg.VerifyURL != "" {
+		link := s.cfg.VerifyURL + "?" + url.Values{"email": {email}, "code": {code}}.Encode()
+		body += "\nИли просто перейдите по ссылке: " + link + "\n"
+	}
+
+	return s.mailer.Send(ctx, Message{
+		To:      email,
+		Subject: "Вход в бронирование досуговых",
+		Body:    body,
+	})
+}
+
+// VerifyLogin проверяет код и выдаёт сессию. При первом входе пользователь создаётся.
+func (s *Service) VerifyLogin(ctx context.Context, email, code string) (Session, error) {
+	email = domain.NormalizeEmail(email)
+	if s.failures.Blocked(email) {
+		return Session{}, domain.ErrTooManyAttempts
+	}
+
+	stored, err := s.codes.Get(ctx, email)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return Session{}, domain.ErrInvalidCode
+		}
+		return Session{}, err
+	}
+
+	if !s.now().Before(stored.ExpiresAt) || stored.Attempts >= maxCodeAttempt {
+		_ = s.codes.Delete(ctx, email)
+		return Session{}, domain.ErrInvalidCode
+	}
+
+	if subtle.ConstantTimeCompare([]byte(hashCode(strings.TrimSpace(code))), []byte(stored.CodeHash)) != 1 {
+		s.failures.Allow(email)
+		stored.Attempts++
+		if err := s.codes.Save(ctx, stored); err != nil {
+			return Session{}, err
+		}
+		return Session{}, domain.ErrInvalidCode
+	}
+
+	if err := s.codes.Delete(ctx, email); err != nil {
+		return Session{}, err
+	}
+	s.failures.Reset(email)
+
+	u, err := s.users.GetByEmail(ctx, email)
+	if errors.Is(err, domain.ErrNotFound) {
+		u, err = s.users.Create(ctx, domain.User{Email: email, CreatedAt: s.now()})
+	}
+	if err != nil {
+		return Session{}, err
+	}
+
+	return s.issue(ctx, u)
+}
+
+// RequestAdminLogin - вход в админку. Код уходит только на почты из ADMINS;
+// на остальные запрос молча игнорируется, чтобы не раскрывать список администраторов.
+func (s *Service) RequestAdminLogin(ctx context.Context, email string) error {
+	if !s.admins[domain.NormalizeEmail(email)] {
+		return nil
+	}
+	return s.RequestLogin(ctx, email)
+}
+
+// IsAdmin говорит, входит ли пользователь в список администраторов.
+func (s *Service) IsAdmin(u domain.User) bool {
+	return s.admins[u.Email]
+}
+
+// Authenticate возвращает пользователя по токену сессии. Отозванные и просроченные сессии не проходят.
+func (s *Service) Authenticate(ctx context.Context, token string) (domain.User, error) {
+	claims, err := s.signer.verify(token, s.now())
+	if err != nil {
+		return domain.User{}, domain.ErrUnauthorized
+	}
+
+	sess, err := s.sessions.Get(ctx, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrUnauthorized
+		}
+		return domain.User{}, err
+	}
+	if sess.Revoked || sess.UserID != claims.UserID || !s.now().Before(sess.ExpiresAt) {
+		return domain.User{}, domain.ErrUnauthorized
+	}
+
+	u, err := s.users.Get(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrUnauthorized
+		}
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+// Logout отзывает сессию, к которой относится токен. Битый токен - не ошибка.
+func (s *Service) Logout(ctx context.Context, token string) error {
+	claims, err := s.signer.verify(token, s.now())
+	if err != nil {
+		return nil
+	}
+	return s.sessions.Revoke(ctx, claims.SessionID)
+}
+
+// RevokeSessions разлогинивает пользователя на всех устройствах.
+func (s *Service) RevokeSessions(ctx context.Context, userID string) error {
+	return s.sessions.RevokeAll(ctx, userID)
+}
+
+func (s *Service) issue(ctx context.Context, u domain.User) (Session, error) {
+	now := s.now()
+	rec := domain.Session{
+		ID:        uuid.NewString(),
+		UserID:    u.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(sessionTTL),
+	}
+	if err := s.sessions.Create(ctx, rec); err != nil {
+		return Session{}, err
+	}
+
+	token, err := s.signer.sign(sessionClaims{SessionID: rec.ID, UserID: u.ID, ExpiresAt: rec.ExpiresAt.Unix()})
+	if err != nil {
+		return Session{}, err
+	}
+	return Session{Token: token, ExpiresAt: rec.ExpiresAt, User: u}, nil
+}
+
+func (s *Service) allowedEmail(email string) bool {
+	local, domainPart, ok := strings.Cut(email, "@")
+	return ok && local != "" && domainPart == s.cfg.EmailDomain
+}
+
+// randomCode - шесть случайных цифр.
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+func hashCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,251 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+
+	appauth "Dormitory_Booking/internal/application/auth"
+	domain "Dormitory_Booking/internal/domain/user"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+type fakeMailer struct {
+	sent []appauth.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg appauth.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+func (m *fakeMailer) lastCode() string {
+	return regexp.MustCompile(`\b\d{6}\b`).FindString(m.sent[len(m.sent)-1].Body)
+}
+
+func newService() (*appauth.Service, *fakeMailer) {
+	mailer := &fakeMailer{}
+	svc := appauth.NewService(memory.NewInMemoryUserRepo(), memory.NewInMemoryLoginCodeRepo(), memory.NewInMemorySessionRepo(), memory.NewInMemoryTelegramLinkRepo(), mailer, appauth.Config{
+		EmailDomain:   "edu.hse.ru",
+		SessionSecret: []byte("secret"),
+		VerifyURL:     "https://dorm.example/api/auth/verify",
+		Admins:        []string{"Admin@edu.hse.ru"},
+		TelegramBot:   "dorm_bot",
+	})
+	return svc, mailer
+}
+
+func TestLogin_FullFlow(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	if err := svc.RequestLogin(ctx, " Student@EDU.hse.ru "); err != nil {
+		t.Fatalf("RequestLogin вернул ошибку: %v", err)
+	}
+	msg := mailer.sent[0]
+	if msg.To != "student@edu.hse.ru" || !strings.Contains(msg.Body, "/api/auth/verify?") {
+		t.Fatalf("неожиданное письмо: %+v", msg)
+	}
+
+	sess, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+
+	u, err := svc.Authenticate(ctx, sess.Token)
+	if err != nil || u.Email != "student@edu.hse.ru" {
+		t.Fatalf("Authenticate: %+v, %v", u, err)
+	}
+
+	// код одноразовый
+	if _, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", mailer.lastCode()); !errors.Is(err, domain.ErrInvalidCode) {
+		t.Fatalf("повторный код: ожидали ErrInvalidCode, получили %v", err)
+	}
+}
+
+func TestRequestLogin_ForeignDomain(t *testing.T) {
+	svc, _ := newService()
+
+	err := svc.RequestLogin(context.Background(), "student@gmail.com")
+	if !errors.Is(err, domain.ErrInvalidEmail) {
+		t.Fatalf("ожидали ErrInvalidEmail, получили %v", err)
+	}
+}
+
+func TestVerifyLogin_TooManyAttempts(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	if err := svc.RequestLogin(ctx, "student@edu.hse.ru"); err != nil {
+		t.Fatalf("RequestLogin вернул ошибку: %v", err)
+	}
+	code := mailer.lastCode()
+
+	for i := 0; i < 5; i++ {
+		if _, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", "000000x"); !errors.Is(err, domain.ErrInvalidCode) {
+			t.Fatalf("попытка %d: ожидали ErrInvalidCode, получили %v", i, err)
+		}
+	}
+	if _, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", code); !errors.Is(err, domain.ErrInvalidCode) {
+		t.Fatalf("после 5 ошибок верный код не должен проходить, получили %v", err)
+	}
+}
+
+func TestAuthenticate_TamperedToken(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	_ = svc.RequestLogin(ctx, "student@edu.hse.ru")
+	sess, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+
+	payload, sig, _ := strings.Cut(sess.Token, ".")
+	for _, token := range []string{"", "garbage", payload + "x." + sig, payload + "." + sig + "x"} {
+		if _, err := svc.Authenticate(ctx, token); !errors.Is(err, domain.ErrUnauthorized) {
+			t.Fatalf("токен %q: ожидали ErrUnauthorized, получили %v", token, err)
+		}
+	}
+}
+
+func TestLinkTelegram_NeedsConfirmation(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	_ = svc.RequestLogin(ctx, "a@edu.hse.ru")
+	sess, err := svc.VerifyLogin(ctx, "a@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+
+	req, err := svc.RequestTelegramLink(ctx, sess.User.ID, "@Someone")
+	if err != nil {
+		t.Fatalf("RequestTelegramLink вернул ошибку: %v", err)
+	}
+	if req.URL != "https://t.me/dorm_bot?start="+req.Code {
+		t.Fatalf("неожиданная ссылка на бота: %q", req.URL)
+	}
+	if u, _ := svc.Authenticate(ctx, sess.Token); u.TelegramID != "" {
+		t.Fatalf("до подтверждения Telegram не должен привязываться: %+v", u)
+	}
+
+	// код прислали с другого ника - привязки нет, и код сгорел
+	if _, err := svc.ConfirmTelegram(ctx, req.Code, "attacker", 1); !errors.Is(err, domain.ErrInvalidLinkCode) {
+		t.Fatalf("чужой ник: ожидали ErrInvalidLinkCode, получили %v", err)
+	}
+	if _, err := svc.ConfirmTelegram(ctx, req.Code, "someone", 1); !errors.Is(err, domain.ErrInvalidLinkCode) {
+		t.Fatalf("повтор кода: ожидали ErrInvalidLinkCode, получили %v", err)
+	}
+
+	req, _ = svc.RequestTelegramLink(ctx, sess.User.ID, "someone")
+	u, err := svc.ConfirmTelegram(ctx, req.Code, "SomeOne", 42)
+	if err != nil {
+		t.Fatalf("ConfirmTelegram вернул ошибку: %v", err)
+	}
+	if u.TelegramID != "someone" || u.TelegramChatID != 42 {
+		t.Fatalf("неожиданный пользователь после привязки: %+v", u)
+	}
+}
+
+func TestLinkTelegram_Taken(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	login := func(email string) string {
+		_ = svc.RequestLogin(ctx, email)
+		sess, err := svc.VerifyLogin(ctx, email, mailer.lastCode())
+		if err != nil {
+			t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+		}
+		return sess.User.ID
+	}
+
+	a := login("a@edu.hse.ru")
+	b := login("b@edu.hse.ru")
+
+	req, err := svc.RequestTelegramLink(ctx, a, "@Same")
+	if err != nil {
+		t.Fatalf("RequestTelegramLink вернул ошибку: %v", err)
+	}
+	if _, err := svc.ConfirmTelegram(ctx, req.Code, "same", 0); err != nil {
+		t.Fatalf("ConfirmTelegram вернул ошибку: %v", err)
+	}
+	if _, err := svc.RequestTelegramLink(ctx, b, "same"); !errors.Is(err, domain.ErrTelegramTaken) {
+		t.Fatalf("ожидали ErrTelegramTaken, получили %v", err)
+	}
+}
+
+func TestLogout_RevokesSession(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	_ = svc.RequestLogin(ctx, "student@edu.hse.ru")
+	first, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+	_ = svc.RequestLogin(ctx, "student@edu.hse.ru")
+	second, err := svc.VerifyLogin(ctx, "student@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+
+	if err := svc.Logout(ctx, first.Token); err != nil {
+		t.Fatalf("Logout вернул ошибку: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, first.Token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("после выхода: ожидали ErrUnauthorized, получили %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, second.Token); err != nil {
+		t.Fatalf("вторая сессия не должна пострадать: %v", err)
+	}
+
+	if err := svc.RevokeSessions(ctx, second.User.ID); err != nil {
+		t.Fatalf("RevokeSessions вернул ошибку: %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, second.Token); !errors.Is(err, domain.ErrUnauthorized) {
+		t.Fatalf("после отзыва всех сессий: ожидали ErrUnauthorized, получили %v", err)
+	}
+}
+
+func TestRequestLogin_Throttled(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	var err error
+	for i := 0; i < 10 && err == nil; i++ {
+		err = svc.RequestLogin(ctx, "student@edu.hse.ru")
+	}
+	if !errors.Is(err, domain.ErrTooManyAttempts) {
+		t.Fatalf("ожидали ErrTooManyAttempts, получили %v", err)
+	}
+	if len(mailer.sent) != 5 {
+		t.Fatalf("ожидали 5 писем, отправлено %d", len(mailer.sent))
+	}
+}
+
+func TestAdmins_AllowList(t *testing.T) {
+	svc, mailer := newService()
+	ctx := context.Background()
+
+	if err := svc.RequestAdminLogin(ctx, "student@edu.hse.ru"); err != nil {
+		t.Fatalf("RequestAdminLogin вернул ошибку: %v", err)
+	}
+	if len(mailer.sent) != 0 {
+		t.Fatalf("не-администратору письмо уходить не должно")
+	}
+
+	if err := svc.RequestAdminLogin(ctx, "admin@edu.hse.ru"); err != nil {
+		t.Fatalf("RequestAdminLogin вернул ошибку: %v", err)
+	}
+	sess, err := svc.VerifyLogin(ctx, "admin@edu.hse.ru", mailer.lastCode())
+	if err != nil {
+		t.Fatalf("VerifyLogin вернул ошибку: %v", err)
+	}
+	if !svc.IsAdmin(sess.User) {
+		t.Fatalf("почта из ADMINS должна давать права администратора")
+	}
+}
@@ -0,0 +1,65 @@
+package auth
+
+// В этом файле подписанные сессии: токен = base64(данные) + "." + base64(HMAC-SHA256).
+// Подделать токен без секрета нельзя; отзыв проверяется по серверной записи сессии (sid).
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+var errBadToken = errors.New("invalid session token")
+
+type sessionClaims struct {
+	SessionID string `json:"sid"`
+	UserID    string `json:"uid"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+type signer struct {
+	secret []byte
+}
+
+func (s signer) sign(c sessionClaims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawURLEncoding.EncodeToString(payload)
+	return enc + "." + base64.RawURLEncoding.EncodeToString(s.mac(enc)), nil
+}
+
+func (s signer) verify(token string, now time.Time) (sessionClaims, error) {
+	enc, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return sessionClaims{}, errBadToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, s.mac(enc)) {
+		return sessionClaims{}, errBadToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(enc)
+	if err != nil {
+		return sessionClaims{}, errBadToken
+	}
+	var c sessionClaims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return sessionClaims{}, errBadToken
+	}
+	if now.Unix() >= c.ExpiresAt {
+		return sessionClaims{}, errBadToken
+	}
+	return c, nil
+}
+
+func (s signer) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
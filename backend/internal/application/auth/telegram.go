@@ -0,0 +1,101 @@
+package auth
+
+// В этом файле привязка Telegram к профилю. Ник нельзя просто вписать в профиль:
+// сайт выдаёт одноразовый код, и привязка сохраняется, только когда бот получит
+// этот код от того самого пользователя Telegram.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/user"
+)
+
+// TelegramLinkRequest - код, который нужно отправить боту.
+type TelegramLinkRequest struct {
+	Code      string    `json:"code"`
+	URL       string    `json:"url,omitempty"` // t.me/<бот>?start=<код>, если имя бота известно
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// RequestTelegramLink начинает привязку ника к пользователю. До подтверждения в боте
+// профиль не меняется; повторный запрос заменяет прежний код.
+func (s *Service) RequestTelegramLink(ctx context.Context, userID, telegramID string) (TelegramLinkRequest, error) {
+	telegramID = domain.NormalizeTelegram(telegramID)
+	if telegramID == "" {
+		return TelegramLinkRequest{}, domain.ErrTelegramNotLinked
+	}
+
+	u, err := s.users.Get(ctx, userID)
+	if err != nil {
+		return TelegramLinkRequest{}, err
+	}
+	if err := s.telegramFree(ctx, u, telegramID); err != nil {
+		return TelegramLinkRequest{}, err
+	}
+
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return TelegramLinkRequest{}, err
+	}
+	// base64url годится для параметра start: там разрешены только A-Z, a-z, 0-9, _ и -
+	code := base64.RawURLEncoding.EncodeToString(buf)
+	req := TelegramLinkRequest{Code: code, ExpiresAt: s.now().Add(linkTTL)}
+	if s.cfg.TelegramBot != "" {
+		req.URL = "https://t.me/" + url.PathEscape(s.cfg.TelegramBot) + "?start=" + code
+	}
+
+	if err := s.links.Save(ctx, domain.TelegramLink{
+		CodeHash:   hashCode(code),
+		UserID:     u.ID,
+		TelegramID: telegramID,
+		ExpiresAt:  req.ExpiresAt,
+	}); err != nil {
+		return TelegramLinkRequest{}, err
+	}
+	return req, nil
+}
+
+// ConfirmTelegram завершает привязку. Его вызывает бот: username и chatID он берёт из
+// самого сообщения, так что ник подтверждён Telegram. chatID = 0 - код пришёл не из
+// личного чата, тогда чат узнаем из следующего личного сообщения.
+// Код одноразовый и сгорает даже при неудачной попытке.
+func (s *Service) ConfirmTelegram(ctx context.Context, code, username string, chatID int64) (domain.User, error) {
+	l, err := s.links.Take(ctx, hashCode(code))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.User{}, domain.ErrInvalidLinkCode
+		}
+		return domain.User{}, err
+	}
+	if !s.now().Before(l.ExpiresAt) || domain.NormalizeTelegram(username) != l.TelegramID {
+		return domain.User{}, domain.ErrInvalidLinkCode
+	}
+
+	u, err := s.users.Get(ctx, l.UserID)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if err := s.telegramFree(ctx, u, l.TelegramID); err != nil {
+		return domain.User{}, err
+	}
+	u.TelegramID = l.TelegramID
+	u.TelegramChatID = chatID
+	return s.users.Update(ctx, u)
+}
+
+// telegramFree проверяет, что ник не привязан к другому пользователю. Один Telegram - один пользователь.
+func (s *Service) telegramFree(ctx context.Context, u domain.User, telegramID string) error {
+	other, err := s.users.GetByTelegram(ctx, telegramID)
+	switch {
+	case err == nil && other.ID != u.ID:
+		return domain.ErrTelegramTaken
+	case err != nil && !errors.Is(err, domain.ErrNotFound):
+		return err
+	}
+	return nil
+}
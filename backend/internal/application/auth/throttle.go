@@ -0,0 +1,72 @@
+package auth
+
+// В этом файле простой ограничитель попыток входа: не больше limit событий на ключ за окно.
+
+import (
+	"sync"
+	"time"
+)
+
+// Throttle считает попытки по ключу (почта, IP) в скользящем окне. Состояние в памяти:
+// после перезапуска счётчики обнуляются, для защиты от перебора этого достаточно.
+type Throttle struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string][]time.Time
+	now    func() time.Time
+}
+
+func NewThrottle(limit int, window time.Duration) *Throttle {
+	return &Throttle{
+		limit:  limit,
+		window: window,
+		hits:   make(map[string][]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Allow учитывает попытку и говорит, укладывается ли она в лимит.
+func (t *Throttle) Allow(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	recent := t.recentLocked(key, now)
+	if len(recent) >= t.limit {
+		t.hits[key] = recent
+		return false
+	}
+	t.hits[key] = append(recent, now)
+	return true
+}
+
+// Blocked говорит, исчерпан ли лимит, не учитывая новую попытку.
+func (t *Throttle) Blocked(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recent := t.recentLocked(key, t.now())
+	t.hits[key] = recent
+	return len(recent) >= t.limit
+}
+
+// Reset забывает попытки по ключу, например после успешного входа.
+func (t *Throttle) Reset(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.hits, key)
+}
+
+func (t *Throttle) recentLocked(key string, now time.Time) []time.Time {
+	hits := t.hits[key]
+	i := 0
+	for i < len(hits) && now.Sub(hits[i]) >= t.window {
+		i++
+	}
+	if i == len(hits) {
+		delete(t.hits, key)
+		return nil
+	}
+	return hits[i:]
+}
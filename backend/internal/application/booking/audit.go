@@ -0,0 +1,49 @@
+package booking
+
+// В этом файле запись изменений броней в журнал.
+
+import (
+	"context"
+	"log"
+
+	"Dormitory_Booking/internal/domain/audit"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// ownerAction - action, если бронь трогает владелец, и admin_override, если кто-то другой.
+func ownerAction(b domain.Booking, requesterID string, action audit.Action) audit.Action {
+	if b.TelegramID != requesterID {
+		return audit.ActionAdminOverride
+	}
+	return action
+}
+
+// record пишет изменение в журнал и рассылает его подписчикам. Сбой журнала не отменяет
+// уже сделанное изменение, поэтому только логируется.
+func (s *Service) record(ctx context.Context, action audit.Action, actor string, isAdmin bool, before, after *domain.Booking) {
+	s.publish(ctx, before, after)
+	if s.audit == nil {
+		return
+	}
+
+	e := audit.Entry{Actor: actor, Admin: isAdmin, Action: action, Before: before, After: after}
+	if after != nil {
+		e.BookingID = after.ID
+	} else if before != nil {
+		e.BookingID = before.ID
+	}
+	req := audit.RequestFrom(ctx)
+	e.IP, e.UserAgent = req.IP, req.UserAgent
+
+	if _, err := s.audit.Append(ctx, e); err != nil {
+		log.Printf("audit: %s %s: %v", action, e.BookingID, err)
+	}
+}
+
+// AuditLog - выборка из журнала для админки.
+func (s *Service) AuditLog(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	if s.audit == nil {
+		return nil, nil
+	}
+	return s.audit.Find(ctx, f)
+}
@@ -0,0 +1,62 @@
+package booking_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/audit"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_AuditLog(t *testing.T) {
+	ctx := audit.WithRequest(context.Background(), audit.Request{IP: "10.0.0.7", UserAgent: "test"})
+	log := memory.NewInMemoryAuditLog()
+	svc := app.NewService(memory.NewInMemoryBookingRepo(), app.WithAuditLog(log))
+
+	start := time.Date(2099, 1, 5, 10, 0, 0, 0, time.UTC)
+	b, err := svc.CreateBooking(ctx, app.CreateBookingInput{Start: start, End: start.Add(time.Hour), Room: domain.Room132, Title: "Кино", TelegramID: "owner"})
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	title := "Кино и пицца"
+	if _, err := svc.UpdateBooking(ctx, b.ID, "owner", false, app.UpdateBookingInput{Title: &title}); err != nil {
+		t.Fatalf("UpdateBooking: %v", err)
+	}
+	if err := svc.CancelBooking(ctx, b.ID, "admin", true, ""); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+
+	entries, _ := svc.AuditLog(ctx, audit.Filter{BookingID: b.ID})
+	if len(entries) != 3 {
+		t.Fatalf("ожидали 3 записи, получили %+v", entries)
+	}
+	// от новых к старым
+	del, upd, cre := entries[0], entries[1], entries[2]
+	if cre.Action != audit.ActionCreate || cre.Before != nil || cre.After == nil || cre.Actor != "owner" {
+		t.Fatalf("создание: %+v", cre)
+	}
+	if upd.Action != audit.ActionUpdate || upd.Before.Title != "Кино" || upd.After.Title != title {
+		t.Fatalf("изменение: %+v", upd)
+	}
+	if del.Action != audit.ActionAdminOverride || del.Actor != "admin" || !del.Admin || del.After != nil || del.Before.Title != title {
+		t.Fatalf("удаление админом: %+v", del)
+	}
+	if del.IP != "10.0.0.7" || del.UserAgent != "test" {
+		t.Fatalf("не записали данные запроса: %+v", del)
+	}
+
+	if list, _ := svc.AuditLog(ctx, audit.Filter{Action: audit.ActionUpdate}); len(list) != 1 {
+		t.Fatalf("фильтр по действию: %+v", list)
+	}
+
+	if _, err := svc.RestoreBooking(ctx, b.ID, "admin"); err != nil {
+		t.Fatalf("RestoreBooking: %v", err)
+	}
+	entries, _ = svc.AuditLog(ctx, audit.Filter{BookingID: b.ID, Action: audit.ActionRestore})
+	if len(entries) != 1 || !entries[0].Before.Cancelled() || entries[0].After.Cancelled() {
+		t.Fatalf("восстановление: %+v", entries)
+	}
+}
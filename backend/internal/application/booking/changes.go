@@ -0,0 +1,51 @@
+package booking
+
+// В этом файле инкрементальная синхронизация: изменения броней после ревизии
+// и устаревание старых надгробий (отменённых броней) в этой выдаче.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+const (
+	// DefaultChangesLimit - сколько изменений отдавать за раз, если клиент не просил меньше.
+	DefaultChangesLimit = 500
+	// DefaultTombstoneRetention - сколько отменённая бронь приходит в журнале изменений
+	// надгробием. Клиент, не синхронизировавшийся дольше, получит ErrCursorExpired
+	// и начнёт с нуля. Из базы бронь при этом не удаляется.
+	DefaultTombstoneRetention = 30 * 24 * time.Hour
+)
+
+var errNoChangeLog = errors.New("журнал ревизий не подключён")
+
+// WithChangeLog подключает журнал ревизий (GET /bookings/changes). Обычно это
+// то же хранилище, что и брони.
+func WithChangeLog(changes domain.ChangeLog) Option {
+	return func(s *Service) {
+		s.changes = changes
+	}
+}
+
+// Changes - брони, изменённые после ревизии since, включая отменённые. limit вне
+// (0, DefaultChangesLimit] заменяется на DefaultChangesLimit.
+func (s *Service) Changes(ctx context.Context, since int64, limit int) (domain.ChangeSet, error) {
+	if s.changes == nil {
+		return domain.ChangeSet{}, errNoChangeLog
+	}
+	if limit <= 0 || limit > DefaultChangesLimit {
+		limit = DefaultChangesLimit
+	}
+	return s.changes.Changes(ctx, since, limit)
+}
+
+// ExpireTombstones убирает из журнала изменений брони, отменённые больше retention назад.
+func (s *Service) ExpireTombstones(ctx context.Context, retention time.Duration) (int, error) {
+	if s.changes == nil {
+		return 0, nil
+	}
+	return s.changes.ExpireTombstones(ctx, time.Now().Add(-retention))
+}
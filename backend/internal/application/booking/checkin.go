@@ -0,0 +1,202 @@
+package booking
+
+// В этом файле отметка о приходе и освобождение броней, на которые никто не пришёл.
+// Отметиться можно запросом к API или сканом QR-кода на двери комнаты.
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"log"
+	"time"
+
+	"Dormitory_Booking/internal/domain/audit"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// CheckInEarly - за сколько до начала брони уже можно отметиться.
+const CheckInEarly = 10 * time.Minute
+
+// WithNoShowRepo подключает учёт неявок. Без него брони освобождаются, но неявки не копятся.
+func WithNoShowRepo(noShows domain.NoShowRepository) Option {
+	return func(s *Service) {
+		s.noShows = noShows
+	}
+}
+
+// checkInWindow - когда по правилам комнаты можно отметиться на бронь b.
+// required=false - отмечаться не нужно, бронь не освободится (льготное окно 0).
+func checkInWindow(p Policy, b domain.Booking) (opens, closes time.Time, required bool) {
+	grace := p.For(b.Room).CheckInGraceMinutes
+	opens = b.Start.Add(-CheckInEarly)
+	if grace == 0 {
+		return opens, b.End, false
+	}
+	closes = b.Start.Add(time.Duration(grace) * time.Minute)
+	if closes.After(b.End) {
+		closes = b.End
+	}
+	return opens, closes, true
+}
+
+// CheckIn отмечает, что владелец пришёл на бронь. Повторная отметка ничего не меняет.
+func (s *Service) CheckIn(ctx context.Context, id string, requesterID string) (domain.Booking, error) {
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	if b.TelegramID != requesterID {
+		return domain.Booking{}, domain.ErrForbidden
+	}
+	if b.Cancelled() {
+		return domain.Booking{}, domain.ErrCancelled
+	}
+	if b.CheckedInAt != nil {
+		return b, nil
+	}
+
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	now := time.Now()
+	opens, closes, _ := checkInWindow(policy, b)
+	if now.Before(opens) || !now.Before(closes) {
+		return domain.Booking{}, domain.ErrCheckInClosed
+	}
+	return s.repo.CheckIn(ctx, id, now)
+}
+
+// CheckInByRoomToken отмечает приход по QR-коду комнаты: на ближайшую бронь
+// пользователя в этой комнате, на которую сейчас открыта отметка.
+func (s *Service) CheckInByRoomToken(ctx context.Context, token string, requesterID string) (domain.Booking, error) {
+	if s.rooms == nil || token == "" {
+		return domain.Booking{}, domain.ErrNotFound
+	}
+	room, err := s.rooms.GetByCheckInToken(ctx, token)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	now := time.Now()
+	candidates, err := s.repo.Find(ctx, domain.ListFilter{
+		From:  now,
+		To:    now.Add(CheckInEarly),
+		Room:  room.Number,
+		Owner: requesterID,
+	})
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	if len(candidates) == 0 {
+		return domain.Booking{}, domain.ErrNothingToCheckIn
+	}
+
+	var lastErr error
+	for _, b := range candidates {
+		checked, err := s.CheckIn(ctx, b.ID, requesterID)
+		if err == nil {
+			return checked, nil
+		}
+		if !errors.Is(err, domain.ErrCheckInClosed) {
+			return domain.Booking{}, err
+		}
+		lastErr = err
+	}
+	return domain.Booking{}, lastErr
+}
+
+// RoomCheckInToken возвращает токен QR-кода комнаты, при необходимости создаёт его.
+// reset - выпустить новый токен, старый QR-код перестанет работать.
+func (s *Service) RoomCheckInToken(ctx context.Context, number domain.Room, reset bool) (string, error) {
+	if s.rooms == nil {
+		return "", errRoomsNotConfigured
+	}
+	room, err := s.rooms.Get(ctx, number)
+	if err != nil {
+		return "", err
+	}
+	if room.CheckInToken != "" && !reset {
+		return room.CheckInToken, nil
+	}
+
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	room.CheckInToken = base64.RawURLEncoding.EncodeToString(buf)
+	if _, err := s.rooms.Update(ctx, room); err != nil {
+		return "", err
+	}
+	return room.CheckInToken, nil
+}
+
+// ReleaseNoShows отменяет идущие брони, владелец которых не отметился за льготное окно,
+// записывает неявку и отдаёт время очереди ожидания. Уже закончившиеся брони не трогает:
+// освобождать там нечего, а неявку без работающей фоновой задачи засчитывать нечестно.
+func (s *Service) ReleaseNoShows(ctx context.Context) error {
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	// идущие сейчас: Start <= now < End. Окно в секунду, а не [now, now): пустой
+	// tstzrange в Postgres ни с чем не пересекается
+	started, err := s.repo.Find(ctx, domain.ListFilter{From: now, To: now.Add(time.Second)})
+	if err != nil {
+		return err
+	}
+
+	for _, b := range started {
+		if b.Start.After(now) {
+			continue
+		}
+		_, closes, required := checkInWindow(policy, b)
+		if !required || b.CheckedInAt != nil || now.Before(closes) {
+			continue
+		}
+
+		cancelled, err := s.repo.Cancel(ctx, b.ID, domain.Cancellation{At: now, Reason: domain.ReasonNoShow})
+		if err != nil {
+			if errors.Is(err, domain.ErrCancelled) || errors.Is(err, domain.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if s.noShows != nil {
+			n := domain.NoShow{TelegramID: b.TelegramID, BookingID: b.ID, Room: b.Room, Start: b.Start, RecordedAt: now}
+			if err := s.noShows.Record(ctx, n); err != nil {
+				log.Printf("no-show: бронь %s: %v", b.ID, err)
+			}
+		}
+		s.record(ctx, audit.ActionNoShow, "", false, &b, &cancelled)
+		s.offerFreed(ctx, b.Room, now, b.End)
+	}
+	return nil
+}
+
+// NoShows - неявки под фильтр, для админки и будущих правил политики.
+func (s *Service) NoShows(ctx context.Context, f domain.NoShowFilter) ([]domain.NoShow, error) {
+	if s.noShows == nil {
+		return nil, nil
+	}
+	return s.noShows.Find(ctx, f)
+}
+
+// RunNoShows раз в every освобождает брони без отметки, пока не отменят ctx.
+func (s *Service) RunNoShows(ctx context.Context, every time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.ReleaseNoShows(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("no-show: %v", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_CheckInAndNoShows(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	noShows := memory.NewInMemoryNoShowRepo()
+	svc := app.NewService(repo,
+		app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)),
+		app.WithPolicyStore(checkInPolicy(t)),
+		app.WithNoShowRepo(noShows),
+	)
+
+	// создаём в обход сервиса: через него бронь в прошлом не завести
+	now := time.Now()
+	missed, _ := repo.Create(ctx, domain.Booking{Start: now.Add(-30 * time.Minute), End: now.Add(30 * time.Minute), Room: domain.Room132, Title: "Пусто", TelegramID: "lazy"})
+	present, _ := repo.Create(ctx, domain.Booking{Start: now.Add(-30 * time.Minute), End: now.Add(30 * time.Minute), Room: domain.Room21, Title: "Пришли", TelegramID: "eager"})
+	repo.CheckIn(ctx, present.ID, now.Add(-25*time.Minute))
+	fresh, _ := repo.Create(ctx, domain.Booking{Start: now.Add(-5 * time.Minute), End: now.Add(time.Hour), Room: domain.Room256, Title: "Только начали", TelegramID: "eager"})
+	later, _ := repo.Create(ctx, domain.Booking{Start: now.Add(2 * time.Hour), End: now.Add(3 * time.Hour), Room: domain.Room256, Title: "Потом", TelegramID: "eager"})
+
+	if _, err := svc.CheckIn(ctx, later.ID, "eager"); !errors.Is(err, domain.ErrCheckInClosed) {
+		t.Fatalf("отметка за два часа до начала: ожидали ErrCheckInClosed, получили %v", err)
+	}
+	if _, err := svc.CheckIn(ctx, fresh.ID, "lazy"); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("отметка на чужую бронь: ожидали ErrForbidden, получили %v", err)
+	}
+	if _, err := svc.CheckIn(ctx, missed.ID, "lazy"); !errors.Is(err, domain.ErrCheckInClosed) {
+		t.Fatalf("отметка после льготного окна: ожидали ErrCheckInClosed, получили %v", err)
+	}
+
+	if err := svc.ReleaseNoShows(ctx); err != nil {
+		t.Fatalf("ReleaseNoShows: %v", err)
+	}
+
+	if b, _ := repo.Get(ctx, missed.ID); !b.Cancelled() || b.CancelReason != domain.ReasonNoShow {
+		t.Fatalf("бронь без отметки должна освободиться: %+v", b)
+	}
+	for _, id := range []string{present.ID, fresh.ID, later.ID} {
+		if b, _ := repo.Get(ctx, id); b.Cancelled() {
+			t.Fatalf("бронь %q не должна освобождаться", b.Title)
+		}
+	}
+	list, _ := svc.NoShows(ctx, domain.NoShowFilter{Owner: "lazy"})
+	if len(list) != 1 || list[0].BookingID != missed.ID {
+		t.Fatalf("ожидали одну неявку lazy, получили %+v", list)
+	}
+
+	// повторный проход ничего не добавляет
+	svc.ReleaseNoShows(ctx)
+	if list, _ := svc.NoShows(ctx, domain.NoShowFilter{}); len(list) != 1 {
+		t.Fatalf("неявка записалась дважды: %+v", list)
+	}
+}
+
+// checkInPolicy - политика по умолчанию с обязательной отметкой за 15 минут.
+func checkInPolicy(t *testing.T) app.PolicyStore {
+	t.Helper()
+	store := app.NewStaticPolicyStore()
+	p := app.DefaultPolicy()
+	p.Default.CheckInGraceMinutes = 15
+	if _, err := store.Save(context.Background(), p); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	return store
+}
+
+func TestService_ReleaseNoShows_OffByDefault(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)))
+
+	now := time.Now()
+	b, _ := repo.Create(ctx, domain.Booking{Start: now.Add(-30 * time.Minute), End: now.Add(30 * time.Minute), Room: domain.Room132, Title: "Пусто", TelegramID: "lazy"})
+	if err := svc.ReleaseNoShows(ctx); err != nil {
+		t.Fatalf("ReleaseNoShows: %v", err)
+	}
+	if got, _ := repo.Get(ctx, b.ID); got.Cancelled() {
+		t.Fatalf("без льготного окна в политике бронь не должна освобождаться")
+	}
+}
+
+func TestService_CheckInByRoomToken(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)))
+
+	token, err := svc.RoomCheckInToken(ctx, domain.Room132, false)
+	if err != nil || token == "" {
+		t.Fatalf("RoomCheckInToken: %q, %v", token, err)
+	}
+	if again, _ := svc.RoomCheckInToken(ctx, domain.Room132, false); again != token {
+		t.Fatalf("без reset токен не должен меняться")
+	}
+
+	if _, err := svc.CheckInByRoomToken(ctx, token, "owner"); !errors.Is(err, domain.ErrNothingToCheckIn) {
+		t.Fatalf("скан без брони: ожидали ErrNothingToCheckIn, получили %v", err)
+	}
+
+	now := time.Now()
+	b, _ := repo.Create(ctx, domain.Booking{Start: now.Add(5 * time.Minute), End: now.Add(time.Hour), Room: domain.Room132, Title: "Кино", TelegramID: "owner"})
+	checked, err := svc.CheckInByRoomToken(ctx, token, "owner")
+	if err != nil || checked.ID != b.ID || checked.CheckedInAt == nil {
+		t.Fatalf("CheckInByRoomToken: %+v, %v", checked, err)
+	}
+
+	// новый токен - старый QR-код больше не работает, а правка комнаты токен не стирает
+	reset, _ := svc.RoomCheckInToken(ctx, domain.Room132, true)
+	if _, err := svc.CheckInByRoomToken(ctx, token, "owner"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("старый токен: ожидали ErrNotFound, получили %v", err)
+	}
+	room, _ := svc.GetRoom(ctx, domain.Room132)
+	room.CheckInToken = ""
+	if _, err := svc.UpdateRoom(ctx, room); err != nil {
+		t.Fatalf("UpdateRoom: %v", err)
+	}
+	if again, _ := svc.RoomCheckInToken(ctx, domain.Room132, false); again != reset {
+		t.Fatalf("правка комнаты не должна сбрасывать токен QR-кода")
+	}
+}
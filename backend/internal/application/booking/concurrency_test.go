@@ -0,0 +1,162 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+// runParallel запускает n создателей одновременно и возвращает ошибки по каждому.
+func runParallel(n int, create func(i int) error) []error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	startGate := make(chan struct{})
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-startGate
+			errs[i] = create(i)
+		}(i)
+	}
+
+	close(startGate)
+	wg.Wait()
+	return errs
+}
+
+func TestService_CreateBooking_ParallelSameSlot(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(memory.NewInMemoryBookingRepo())
+
+	// понедельник, 12:00
+	start := time.Date(2099, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	errs := runParallel(300, func(i int) error {
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(time.Hour),
+			Room:       domain.Room21,
+			Title:      "Гонка",
+			TelegramID: fmt.Sprintf("user-%d", i),
+		})
+		return err
+	})
+
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, domain.ErrOverlap):
+		default:
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if ok != 1 {
+		t.Fatalf("слот должен достаться ровно одному, успешных созданий: %d", ok)
+	}
+}
+
+func TestService_CreateBooking_ParallelPrivateDailyLimit(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(memory.NewInMemoryBookingRepo())
+
+	day := time.Date(2099, 1, 5, 6, 0, 0, 0, time.UTC)
+
+	// 300 частных броней по 5 минут, все до 18:00, чтобы упираться только в суточный лимит
+	errs := runParallel(300, func(i int) error {
+		start := day.Add(time.Duration(i%144) * 5 * time.Minute)
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(5 * time.Minute),
+			Room:       domain.Room256,
+			Title:      "ЧП",
+			TelegramID: fmt.Sprintf("user-%d", i),
+			IsPrivate:  true,
+		})
+		return err
+	})
+
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, domain.ErrPrivateDailyLimit), errors.Is(err, domain.ErrOverlap):
+		default:
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if ok != 3 {
+		t.Fatalf("ожидали ровно 3 частные брони за день, получили %d", ok)
+	}
+}
+
+// slowRepo растягивает окно между проверкой лимитов и записью, чтобы гонка проявлялась.
+type slowRepo struct {
+	*memory.InMemoryBookingRepo
+}
+
+func (r slowRepo) WithRoomLock(ctx context.Context, rooms []domain.Room, owners []string, fn func(repo domain.Repository) error) error {
+	return r.InMemoryBookingRepo.WithRoomLock(ctx, rooms, owners, func(repo domain.Repository) error {
+		return fn(slowWrites{repo})
+	})
+}
+
+type slowWrites struct {
+	domain.Repository
+}
+
+func (r slowWrites) Create(ctx context.Context, b domain.Booking) (domain.Booking, error) {
+	time.Sleep(time.Millisecond)
+	return r.Repository.Create(ctx, b)
+}
+
+func TestService_CreateBooking_ParallelQuotaAcrossRooms(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(slowRepo{memory.NewInMemoryBookingRepo()})
+	p := app.DefaultPolicy()
+	p.Quota = app.Quota{MaxActiveBookings: 2}
+	if _, err := svc.SavePolicy(ctx, p); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+
+	day := time.Date(2099, 1, 5, 6, 0, 0, 0, time.UTC)
+	rooms := []domain.Room{domain.Room21, domain.Room132, domain.Room256}
+
+	// один владелец сразу во все комнаты: блокировка комнаты его не сдерживает, лимит общий
+	errs := runParallel(300, func(i int) error {
+		start := day.Add(time.Duration(i/3) * 5 * time.Minute)
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(5 * time.Minute),
+			Room:       rooms[i%3],
+			Title:      "Жадность",
+			TelegramID: "greedy",
+		})
+		return err
+	})
+
+	ok := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			ok++
+		case errors.Is(err, domain.ErrQuotaActive):
+		default:
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+	}
+	if ok != 2 {
+		t.Fatalf("ожидали ровно 2 активные брони, получили %d", ok)
+	}
+}
@@ -11,23 +11,74 @@ type BookingDTO struct {
 	Start       time.Time `json:"start"`
 	End         time.Time `json:"end"`
 	Room        int       `json:"room"`
+	Dormitory   string    `json:"dormitory,omitempty"`
 	Title       string    `json:"title"`
 	Description string    `json:"description,omitempty"` // если пусто - фронт не увидит и не рисует кнопку "Подробнее"
 	IsPrivate   bool      `json:"isPrivate"`
 	TelegramID  string    `json:"telegramId"`
 	CanManage   bool      `json:"canManage"`
+	SeriesID    string    `json:"seriesId,omitempty"`
+
+	Visibility domain.Visibility `json:"visibility"` // anonymous-busy - подробности скрыты
+
+	Status       domain.Status `json:"status"` // active, cancelled или completed (уже прошла)
+	CancelledAt  *time.Time    `json:"cancelledAt,omitempty"`
+	CancelReason string        `json:"cancelReason,omitempty"`
+	CheckedInAt  *time.Time    `json:"checkedInAt,omitempty"`
+	Revision     int64         `json:"revision,omitempty"`
+}
+
+// Project - бронь такой, какой её положено видеть зрителю. Всё, что уходит наружу
+// (JSON, бот, календари), должно проходить через неё. У чужой частной брони остаются
+// только ID, комната, время и статус, а название заменяется на «Занято».
+func Project(b domain.Booking, viewerID string, isAdmin bool) domain.Booking {
+	if b.VisibilityFor(viewerID, isAdmin) != domain.VisibilityAnonymousBusy {
+		return b
+	}
+	return domain.Booking{
+		ID:          b.ID,
+		Start:       b.Start,
+		End:         b.End,
+		Room:        b.Room,
+		Dormitory:   b.Dormitory,
+		Title:       domain.BusyTitle,
+		IsPrivate:   true,
+		Status:      b.Status,
+		CancelledAt: b.CancelledAt,
+		Revision:    b.Revision,
+	}
+}
+
+// ProjectSeries - серия для зрителя; nil, если серия частная и зрителю не положена.
+func ProjectSeries(s domain.Series, viewerID string, isAdmin bool) *domain.Series {
+	if s.VisibilityFor(viewerID, isAdmin) == domain.VisibilityAnonymousBusy {
+		return nil
+	}
+	return &s
 }
 
 func ToDTO(b domain.Booking, viewerID string, isAdmin bool) BookingDTO {
+	visibility := b.VisibilityFor(viewerID, isAdmin)
+	b = Project(b, viewerID, isAdmin)
 	return BookingDTO{
 		ID:          b.ID,
 		Start:       b.Start,
 		End:         b.End,
 		Room:        int(b.Room),
+		Dormitory:   b.Dormitory,
 		Title:       b.Title,
 		Description: b.Description,
 		IsPrivate:   b.IsPrivate,
 		TelegramID:  b.TelegramID,
-		CanManage:   isAdmin || viewerID == b.TelegramID,
+		CanManage:   isAdmin || (viewerID != "" && viewerID == b.TelegramID),
+		SeriesID:    b.SeriesID,
+
+		Visibility: visibility,
+
+		Status:       b.StatusAt(time.Now()),
+		CancelledAt:  b.CancelledAt,
+		CancelReason: b.CancelReason,
+		CheckedInAt:  b.CheckedInAt,
+		Revision:     b.Revision,
 	}
 }
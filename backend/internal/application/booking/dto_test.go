@@ -60,3 +60,43 @@ func TestToDTO_AdminCanManage(t *testing.T) {
 		t.Fatalf("админ всегда должен иметь CanManage=true")
 	}
 }
+
+func TestToDTO_PrivateHiddenFromStrangers(t *testing.T) {
+	b := domain.Booking{
+		ID:           "1",
+		Start:        time.Now(),
+		End:          time.Now().Add(time.Hour),
+		Room:         domain.Room21,
+		Title:        "ДР Пети",
+		Description:  "торт в холодильнике",
+		TelegramID:   "owner",
+		IsPrivate:    true,
+		SeriesID:     "s1",
+		CancelReason: "заболел",
+	}
+
+	for _, viewer := range []string{"", "stranger"} {
+		dto := app.ToDTO(b, viewer, false)
+		if dto.Visibility != domain.VisibilityAnonymousBusy || dto.Title != domain.BusyTitle {
+			t.Fatalf("зритель %q: ожидали «Занято», получили %+v", viewer, dto)
+		}
+		if dto.Description != "" || dto.TelegramID != "" || dto.SeriesID != "" || dto.CancelReason != "" || dto.CanManage {
+			t.Fatalf("зритель %q: подробности частной брони раскрыты: %+v", viewer, dto)
+		}
+		if !dto.Start.Equal(b.Start) || !dto.End.Equal(b.End) || dto.Room != int(b.Room) {
+			t.Fatalf("зритель %q: время и комната должны остаться: %+v", viewer, dto)
+		}
+	}
+
+	if dto := app.ToDTO(b, "owner", false); dto.Visibility != domain.VisibilityPrivate || dto.Title != b.Title {
+		t.Fatalf("владелец должен видеть свою бронь целиком: %+v", dto)
+	}
+	if dto := app.ToDTO(b, "", true); dto.Visibility != domain.VisibilityPrivate || dto.TelegramID != "owner" {
+		t.Fatalf("админ должен видеть бронь целиком: %+v", dto)
+	}
+
+	b.IsPrivate = false
+	if dto := app.ToDTO(b, "", false); dto.Visibility != domain.VisibilityPublic || dto.Title != b.Title {
+		t.Fatalf("обычная бронь видна всем: %+v", dto)
+	}
+}
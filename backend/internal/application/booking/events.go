@@ -0,0 +1,49 @@
+package booking
+
+// В этом файле рассылка изменений броней для живого обновления таблицы.
+
+import (
+	"context"
+	"log"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// WithEvents подключает рассылку изменений броней (GET /bookings/stream).
+func WithEvents(bus domain.EventBus) Option {
+	return func(s *Service) {
+		s.events = bus
+	}
+}
+
+// publish рассылает изменение брони. Вызывается из record, так что события идут
+// ровно по тем изменениям, что попадают в журнал. Сбой рассылки только логируется.
+func (s *Service) publish(ctx context.Context, before, after *domain.Booking) {
+	if s.events == nil {
+		return
+	}
+
+	var e domain.Event
+	switch {
+	case after == nil || after.Cancelled():
+		e.Type = domain.EventDeleted
+		if after != nil {
+			e.Booking = *after
+		} else {
+			// журнал пишет отмену без After, подписчикам отдаём бронь уже со статусом
+			e.Booking = *before
+			e.Booking.Status = domain.StatusCancelled
+		}
+	case before == nil || before.Cancelled():
+		e.Type = domain.EventCreated
+		e.Booking = *after
+	default:
+		e.Type = domain.EventUpdated
+		e.Booking = *after
+		e.Previous = before
+	}
+
+	if err := s.events.Publish(ctx, e); err != nil {
+		log.Printf("events: %s %s: %v", e.Type, e.Booking.ID, err)
+	}
+}
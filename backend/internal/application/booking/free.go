@@ -0,0 +1,194 @@
+package booking
+
+// В этом файле поиск свободного времени в комнате на конкретный день
+// с учётом тех же правил, что проверяет CreateBooking.
+
+import (
+	"context"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// Slot - свободный промежуток [Start, End). Бронь можно начать не позже LatestStart:
+// после полуночи и (для ЧП, если вечерний лимит выбран) после начала вечера
+// можно только продолжить бронь, начатую раньше.
+type Slot struct {
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+	LatestStart time.Time `json:"latestStart"`
+	MaxMinutes  int       `json:"maxMinutes,omitempty"` // ограничение длительности ЧП, 0 - нет
+}
+
+// AvailabilityQuery - что ищем.
+type AvailabilityQuery struct {
+	Room        domain.Room
+	Day         time.Time     // день: берётся только дата, часы работы и правила считаются в поясе общежития
+	MinDuration time.Duration // более короткие промежутки не предлагать
+	Private     bool          // учитывать правила частных посиделок
+}
+
+// FreeSlots возвращает свободные промежутки комнаты в часы её работы в день day.
+// От day берётся только дата, часы работы считаются в поясе общежития.
+func (s *Service) FreeSlots(ctx context.Context, number domain.Room, day time.Time) ([]Slot, error) {
+	return s.Availability(ctx, AvailabilityQuery{Room: number, Day: day})
+}
+
+// Availability возвращает промежутки, в которые бронь пройдёт все проверки CreateBooking:
+// часы работы комнаты, прошедшее время, горизонт бронирования (Quota.MaxDaysAhead),
+// пересечения, а для ЧП ещё тихие ночи, дневной и вечерний лимиты и максимальную
+// длительность. Остальные личные лимиты зависят от владельца и здесь не учитываются.
+func (s *Service) Availability(ctx context.Context, q AvailabilityQuery) ([]Slot, error) {
+	room, err := s.bookableRoom(ctx, q.Room)
+	if err != nil {
+		return nil, err
+	}
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := policy.For(q.Room)
+
+	minDur := q.MinDuration
+	if minDur < time.Minute {
+		minDur = time.Minute
+	}
+
+	loc := s.loc
+	dayStart := time.Date(q.Day.Year(), q.Day.Month(), q.Day.Day(), 0, 0, 0, 0, loc)
+	nextDay := dayStart.AddDate(0, 0, 1)
+	openHour, closeHour := scheduleHours(room.Schedule, dayStart.Weekday())
+	open := dayStart.Add(time.Duration(openHour) * time.Hour)
+	closing := dayStart.Add(time.Duration(closeHour) * time.Hour)
+
+	// начинать бронь можно только в этот день, не в прошлом и не дальше горизонта
+	startBefore := nextDay
+	if now := ceilMinute(time.Now().In(loc)); open.Before(now) {
+		open = now
+	}
+	if until, ok := policy.Quota.bookableUntil(time.Now()); ok {
+		if last := until.In(loc).Truncate(time.Minute).Add(time.Minute); last.Before(startBefore) {
+			startBefore = last
+		}
+	}
+	if !open.Before(closing) || !open.Before(startBefore) {
+		return nil, nil
+	}
+
+	busy, err := s.repo.Find(ctx, domain.ListFilter{From: open, To: closing, Room: q.Room})
+	if err != nil {
+		return nil, err
+	}
+	gaps := []Slot{{Start: open, End: closing}}
+	for _, b := range busy {
+		gaps = cutSlots(gaps, b.Start.In(loc), b.End.In(loc))
+	}
+	if s.waitlist != nil {
+		// время, закреплённое за очередью, тоже занято
+		offered, err := s.waitlist.Find(ctx, domain.WaitlistFilter{From: open, To: closing, Room: q.Room,
+			Statuses: []domain.WaitlistStatus{domain.WaitlistOffered}})
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		for _, e := range offered {
+			if e.Holds(now) {
+				gaps = cutSlots(gaps, e.Start.In(loc), e.End.In(loc))
+			}
+		}
+	}
+
+	maxMinutes := 0
+	if q.Private {
+		maxMinutes = rules.MaxPrivateMinutes
+		if maxMinutes > 0 && minDur > time.Duration(maxMinutes)*time.Minute {
+			return nil, nil
+		}
+
+		// тихие ночи, начавшиеся накануне, сегодня или завтра
+		for day := dayStart.AddDate(0, 0, -1); !day.After(nextDay); day = day.AddDate(0, 0, 1) {
+			for _, w := range rules.QuietNights {
+				if day.Weekday() != w.Weekday {
+					continue
+				}
+				nightStart := time.Date(day.Year(), day.Month(), day.Day(), w.StartHour, 0, 0, 0, loc)
+				gaps = cutSlots(gaps, nightStart, nightStart.Add(time.Duration(w.Hours)*time.Hour))
+			}
+		}
+
+		day, evening, err := s.privateCounts(ctx, q.Room, dayStart, nextDay, rules)
+		if err != nil {
+			return nil, err
+		}
+		if rules.PrivateDailyLimit > 0 && day >= rules.PrivateDailyLimit {
+			return nil, nil
+		}
+		if rules.PrivateEveningLimit > 0 && evening >= rules.PrivateEveningLimit {
+			eveningStart := time.Date(dayStart.Year(), dayStart.Month(), dayStart.Day(), rules.EveningStartHour, 0, 0, 0, loc)
+			if eveningStart.Before(startBefore) {
+				startBefore = eveningStart
+			}
+		}
+	}
+
+	var out []Slot
+	for _, g := range gaps {
+		latest := g.End.Add(-minDur)
+		if last := startBefore.Add(-time.Minute); last.Before(latest) {
+			latest = last
+		}
+		if g.End.Sub(g.Start) < minDur || latest.Before(g.Start) {
+			continue
+		}
+		out = append(out, Slot{Start: g.Start, End: g.End, LatestStart: latest, MaxMinutes: maxMinutes})
+	}
+	return out, nil
+}
+
+// privateCounts считает ЧП комнаты, начинающиеся в день [dayStart, nextDay), и из них вечерние.
+func (s *Service) privateCounts(ctx context.Context, room domain.Room, dayStart, nextDay time.Time, rules Rules) (int, int, error) {
+	isPrivate := true
+	existing, err := s.repo.Find(ctx, domain.ListFilter{From: dayStart, To: nextDay, Room: room, IsPrivate: &isPrivate})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	day, evening := 0, 0
+	for _, e := range existing {
+		start := e.Start.In(dayStart.Location())
+		if start.Before(dayStart) || !start.Before(nextDay) {
+			continue
+		}
+		day++
+		if start.Hour() >= rules.EveningStartHour {
+			evening++
+		}
+	}
+	return day, evening, nil
+}
+
+// cutSlots вырезает [from, to) из каждого промежутка.
+func cutSlots(slots []Slot, from, to time.Time) []Slot {
+	out := slots[:0:0]
+	for _, s := range slots {
+		if !timesOverlap(s.Start, s.End, from, to) {
+			out = append(out, s)
+			continue
+		}
+		if s.Start.Before(from) {
+			out = append(out, Slot{Start: s.Start, End: from})
+		}
+		if to.Before(s.End) {
+			out = append(out, Slot{Start: to, End: s.End})
+		}
+	}
+	return out
+}
+
+// ceilMinute округляет время вверх до целой минуты.
+func ceilMinute(t time.Time) time.Time {
+	if r := t.Truncate(time.Minute); r.Before(t) {
+		return r.Add(time.Minute)
+	}
+	return t
+}
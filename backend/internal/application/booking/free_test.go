@@ -0,0 +1,103 @@
+package booking_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_FreeSlots(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo)
+
+	// понедельник, комната 132 работает 06:00-22:00
+	day := time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(10), End: at(12), Room: domain.Room132, TelegramID: "a"})
+	repo.Create(ctx, domain.Booking{Start: at(12), End: at(13), Room: domain.Room132, TelegramID: "b"})
+	repo.Create(ctx, domain.Booking{Start: at(18), End: at(22), Room: domain.Room132, TelegramID: "c"})
+	repo.Create(ctx, domain.Booking{Start: at(14), End: at(15), Room: domain.Room21, TelegramID: "d"})
+
+	slots, err := svc.FreeSlots(ctx, domain.Room132, day.Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	want := []app.Slot{{Start: at(6), End: at(10)}, {Start: at(13), End: at(18)}}
+	if len(slots) != len(want) {
+		t.Fatalf("ожидали %v, получили %v", want, slots)
+	}
+	for i := range want {
+		if !slots[i].Start.Equal(want[i].Start) || !slots[i].End.Equal(want[i].End) {
+			t.Fatalf("ожидали %v, получили %v", want, slots)
+		}
+	}
+}
+
+func TestService_Availability_Private(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo)
+
+	// пятница, комната 21 работает 06:00-01:00, тихая ночь с 23:00
+	day := time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(10), End: at(12), Room: domain.Room21, TelegramID: "a"})
+
+	// для обычной брони свободно до закрытия, начать можно до полуночи
+	slots, err := svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room21, Day: day, MinDuration: time.Hour})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if len(slots) != 2 || !slots[1].End.Equal(at(25)) || !slots[1].LatestStart.Equal(at(24).Add(-time.Minute)) {
+		t.Fatalf("неожиданные промежутки: %+v", slots)
+	}
+
+	// для ЧП вечер обрезан тихой ночью и виден лимит длительности
+	slots, err = svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room21, Day: day, Private: true})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if len(slots) != 2 || !slots[1].Start.Equal(at(12)) || !slots[1].End.Equal(at(23)) || slots[1].MaxMinutes != 180 {
+		t.Fatalf("неожиданные промежутки ЧП: %+v", slots)
+	}
+
+	// вечерний лимит выбран: начать ЧП можно только до 18:00
+	repo.Create(ctx, domain.Booking{Start: at(19), End: at(21), Room: domain.Room21, TelegramID: "b", IsPrivate: true})
+	slots, _ = svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room21, Day: day, Private: true, MinDuration: time.Hour})
+	for _, s := range slots {
+		if !s.LatestStart.Before(at(18)) {
+			t.Fatalf("после 18:00 ЧП начинать нельзя: %+v", slots)
+		}
+	}
+	if len(slots) != 2 || !slots[1].Start.Equal(at(12)) || !slots[1].End.Equal(at(19)) {
+		t.Fatalf("неожиданные промежутки ЧП: %+v", slots)
+	}
+
+	// найденный промежуток действительно бронируется
+	_, err = svc.CreateBooking(ctx, app.CreateBookingInput{
+		Start: slots[1].LatestStart, End: slots[1].LatestStart.Add(time.Hour),
+		Room: domain.Room21, Title: "ЧП", TelegramID: "c", IsPrivate: true,
+	})
+	if err != nil {
+		t.Fatalf("промежуток из Availability не прошёл CreateBooking: %v", err)
+	}
+
+	// длиннее лимита ЧП ничего не найдётся
+	slots, _ = svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room21, Day: day, Private: true, MinDuration: 4 * time.Hour})
+	if len(slots) != 0 {
+		t.Fatalf("ожидали пусто, получили %+v", slots)
+	}
+
+	// дневной лимит (3 ЧП) выбран
+	repo.Create(ctx, domain.Booking{Start: at(7), End: at(8), Room: domain.Room21, TelegramID: "d", IsPrivate: true})
+	slots, _ = svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room21, Day: day, Private: true})
+	if len(slots) != 0 {
+		t.Fatalf("ожидали пусто после дневного лимита, получили %+v", slots)
+	}
+}
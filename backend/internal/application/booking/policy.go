@@ -0,0 +1,176 @@
+package booking
+
+// В этом файле политика бронирования: лимиты и запреты, которые студсовет меняет каждый семестр.
+// Политика версионируется, у каждой комнаты может быть свой набор правил.
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// Имена правил. Их видно в ошибках и в админке.
+const (
+	RuleRoomSchedule       = "room.schedule"
+	RulePrivateMaxDuration = "private.max_duration"
+	RulePrivateQuietNight  = "private.quiet_night"
+	RulePrivateDailyLimit  = "private.daily_limit"
+	RulePrivateEvening     = "private.evening_limit"
+	RuleQuotaActive        = "quota.max_active"
+	RuleQuotaWeeklyHours   = "quota.weekly_hours"
+	RuleQuotaHorizon       = "quota.horizon_days"
+)
+
+// QuietWindow - ночь, в которую нельзя частные посиделки: с StartHour дня Weekday на Hours часов.
+type QuietWindow struct {
+	Weekday   time.Weekday `json:"weekday" yaml:"weekday"`
+	StartHour int          `json:"startHour" yaml:"startHour"`
+	Hours     int          `json:"hours" yaml:"hours"`
+}
+
+// Rules - набор правил для одной комнаты.
+type Rules struct {
+	MaxPrivateMinutes   int           `json:"maxPrivateMinutes" yaml:"maxPrivateMinutes"`     // 0 - без ограничения
+	PrivateDailyLimit   int           `json:"privateDailyLimit" yaml:"privateDailyLimit"`     // 0 - без ограничения
+	PrivateEveningLimit int           `json:"privateEveningLimit" yaml:"privateEveningLimit"` // 0 - без ограничения
+	EveningStartHour    int           `json:"eveningStartHour" yaml:"eveningStartHour"`
+	QuietNights         []QuietWindow `json:"quietNights" yaml:"quietNights"`
+	CheckInGraceMinutes int           `json:"checkInGraceMinutes" yaml:"checkInGraceMinutes"` // 0 - отмечаться не нужно
+}
+
+// Policy - версия политики: правила по умолчанию, переопределения для отдельных комнат
+// и личные лимиты, которые считаются по всем комнатам сразу.
+type Policy struct {
+	Version int                   `json:"version" yaml:"version"`
+	Default Rules                 `json:"default" yaml:"default"`
+	Rooms   map[domain.Room]Rules `json:"rooms,omitempty" yaml:"rooms,omitempty"`
+	Quota   Quota                 `json:"quota" yaml:"quota"`
+}
+
+// PolicyStore - откуда берётся текущая политика (БД, YAML-файл, память).
+// Save сохраняет новую версию и возвращает её с проставленным номером.
+type PolicyStore interface {
+	Current(ctx context.Context) (Policy, error)
+	Save(ctx context.Context, p Policy) (Policy, error)
+}
+
+// DefaultPolicy - правила, которые раньше были константами в коде.
+func DefaultPolicy() Policy {
+	return Policy{
+		Version: 0,
+		Default: Rules{
+			MaxPrivateMinutes:   180,
+			PrivateDailyLimit:   3,
+			PrivateEveningLimit: 1,
+			EveningStartHour:    18,
+			// отметка о приходе выключена, пока её нет в боте и на сайте: иначе все брони
+			// освобождались бы через льготное окно
+			CheckInGraceMinutes: 0,
+			// ночь с пятницы на субботу и с субботы на воскресенье, 23:00–06:00
+			QuietNights: []QuietWindow{
+				{Weekday: time.Friday, StartHour: 23, Hours: 7},
+				{Weekday: time.Saturday, StartHour: 23, Hours: 7},
+			},
+		},
+	}
+}
+
+// For возвращает правила для комнаты.
+func (p Policy) For(room domain.Room) Rules {
+	if r, ok := p.Rooms[room]; ok {
+		return r
+	}
+	return p.Default
+}
+
+var ErrInvalidPolicy = apperror.New(apperror.CodeInvalidPolicy, apperror.KindValidation, "Некорректная политика бронирования.")
+
+// Validate проверяет, что правила вообще можно применить.
+func (p Policy) Validate() error {
+	if err := p.Quota.validate(); err != nil {
+		return fmt.Errorf("quota: %w", err)
+	}
+	if err := p.Default.validate(); err != nil {
+		return fmt.Errorf("default: %w", err)
+	}
+	for room, r := range p.Rooms {
+		if err := r.validate(); err != nil {
+			return fmt.Errorf("room %d: %w", room, err)
+		}
+	}
+	return nil
+}
+
+func (r Rules) validate() error {
+	if r.MaxPrivateMinutes < 0 || r.PrivateDailyLimit < 0 || r.PrivateEveningLimit < 0 || r.CheckInGraceMinutes < 0 {
+		return ErrInvalidPolicy
+	}
+	if r.EveningStartHour < 0 || r.EveningStartHour > 23 {
+		return ErrInvalidPolicy
+	}
+	for _, q := range r.QuietNights {
+		if q.Weekday < time.Sunday || q.Weekday > time.Saturday || q.StartHour < 0 || q.StartHour > 23 || q.Hours <= 0 || q.Hours > 24 {
+			return ErrInvalidPolicy
+		}
+	}
+	return nil
+}
+
+// RuleViolation - нарушение конкретного правила политики. Через errors.Is
+// по-прежнему сравнивается с доменной ошибкой (ErrPrivateDailyLimit и т.п.).
+type RuleViolation struct {
+	Rule    string         // имя правила, например private.daily_limit
+	Version int            // версия политики, по которой проверяли
+	Params  map[string]any // параметры правила, например {"limit": 3}
+	Err     error
+}
+
+func (v *RuleViolation) Error() string { return v.Err.Error() }
+
+func (v *RuleViolation) Unwrap() error { return v.Err }
+
+func violation(p Policy, rule string, err error, params map[string]any) *RuleViolation {
+	return &RuleViolation{Rule: rule, Version: p.Version, Params: params, Err: err}
+}
+
+// staticPolicy - политика, зашитая в код. Используется, пока хранилище не подключено.
+type staticPolicy struct {
+	mu sync.RWMutex
+	p  Policy
+}
+
+// NewStaticPolicyStore - хранилище политики в памяти, стартует с DefaultPolicy.
+func NewStaticPolicyStore() PolicyStore {
+	return &staticPolicy{p: DefaultPolicy()}
+}
+
+func (s *staticPolicy) Current(ctx context.Context) (Policy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.p, nil
+}
+
+func (s *staticPolicy) Save(ctx context.Context, p Policy) (Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p.Version = s.p.Version + 1
+	s.p = p
+	return p, nil
+}
+
+// CurrentPolicy возвращает действующую политику.
+func (s *Service) CurrentPolicy(ctx context.Context) (Policy, error) {
+	return s.policies.Current(ctx)
+}
+
+// SavePolicy проверяет и сохраняет новую версию политики. Уже созданные брони не пересматриваются.
+func (s *Service) SavePolicy(ctx context.Context, p Policy) (Policy, error) {
+	if err := p.Validate(); err != nil {
+		return Policy{}, err
+	}
+	return s.policies.Save(ctx, p)
+}
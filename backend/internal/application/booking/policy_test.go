@@ -0,0 +1,99 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+func TestPolicy_DefaultQuietNightViolationNamesRule(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo())
+
+	// пятница 2099-01-09, 22:00-00:00 - задевает тихую ночь с 23:00
+	start := time.Date(2099, 1, 9, 22, 0, 0, 0, time.UTC)
+	_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+		Start: start, End: start.Add(2 * time.Hour), Room: domain.Room21, Title: "ЧП", TelegramID: "1", IsPrivate: true,
+	})
+
+	var v *app.RuleViolation
+	if !errors.As(err, &v) {
+		t.Fatalf("ожидали RuleViolation, получили %v", err)
+	}
+	if v.Rule != app.RulePrivateQuietNight || !errors.Is(err, domain.ErrInvalidTime) {
+		t.Fatalf("ожидали %s / ErrInvalidTime, получили %s / %v", app.RulePrivateQuietNight, v.Rule, v.Err)
+	}
+}
+
+func TestPolicy_PerRoomOverride(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo())
+
+	p := app.DefaultPolicy()
+	p.Rooms = map[domain.Room]app.Rules{
+		domain.Room256: {MaxPrivateMinutes: 60, PrivateDailyLimit: 1, EveningStartHour: 18},
+	}
+	saved, err := svc.SavePolicy(ctx, p)
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if saved.Version != 1 {
+		t.Fatalf("ожидали версию 1, получили %d", saved.Version)
+	}
+
+	// понедельник 12:00-14:00: в 21 можно (лимит 3 часа), в 256 уже нельзя (лимит час)
+	start := time.Date(2099, 1, 5, 12, 0, 0, 0, time.UTC)
+	in := app.CreateBookingInput{Start: start, End: start.Add(2 * time.Hour), Room: domain.Room21, Title: "ЧП", TelegramID: "1", IsPrivate: true}
+	if _, err := svc.CreateBooking(ctx, in); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	in.Room = domain.Room256
+	_, err = svc.CreateBooking(ctx, in)
+	var v *app.RuleViolation
+	if !errors.As(err, &v) || v.Rule != app.RulePrivateMaxDuration || v.Version != 1 {
+		t.Fatalf("ожидали нарушение %s версии 1, получили %v", app.RulePrivateMaxDuration, err)
+	}
+	if v.Params["maxMinutes"] != 60 {
+		t.Fatalf("в параметрах должен быть лимит, получили %v", v.Params)
+	}
+	if !errors.Is(err, domain.ErrTooLongDuration) {
+		t.Fatalf("нарушение должно сравниваться с ErrTooLongDuration")
+	}
+}
+
+func TestPolicy_DefaultDailyLimit(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo())
+
+	day := time.Date(2099, 1, 5, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		start := day.Add(time.Duration(i) * 2 * time.Hour)
+		if _, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start: start, End: start.Add(time.Hour), Room: domain.Room21, Title: "ЧП", TelegramID: "1", IsPrivate: true,
+		}); err != nil {
+			t.Fatalf("бронь %d: ожидали nil, получили %v", i, err)
+		}
+	}
+
+	start := day.Add(8 * time.Hour)
+	_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+		Start: start, End: start.Add(time.Hour), Room: domain.Room21, Title: "ЧП", TelegramID: "1", IsPrivate: true,
+	})
+	if !errors.Is(err, domain.ErrPrivateDailyLimit) {
+		t.Fatalf("ожидали ErrPrivateDailyLimit, получили %v", err)
+	}
+}
+
+func TestPolicy_ValidateRejectsNonsense(t *testing.T) {
+	p := app.DefaultPolicy()
+	p.Default.QuietNights = append(p.Default.QuietNights, app.QuietWindow{Weekday: time.Monday, StartHour: 25, Hours: 1})
+
+	if err := p.Validate(); !errors.Is(err, app.ErrInvalidPolicy) {
+		t.Fatalf("ожидали ErrInvalidPolicy, получили %v", err)
+	}
+}
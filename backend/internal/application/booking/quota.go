@@ -0,0 +1,164 @@
+package booking
+
+// В этом файле личные лимиты: сколько броней, часов в неделю и насколько вперёд
+// может забронировать один пользователь во всех комнатах вместе.
+
+import (
+	"context"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// Quota - личные лимиты пользователя. Ноль - без ограничения.
+type Quota struct {
+	MaxActiveBookings int `json:"maxActiveBookings" yaml:"maxActiveBookings"` // будущих и идущих броней
+	MaxWeeklyHours    int `json:"maxWeeklyHours" yaml:"maxWeeklyHours"`       // часов за неделю с понедельника, по началу брони
+	MaxDaysAhead      int `json:"maxDaysAhead" yaml:"maxDaysAhead"`           // на сколько дней вперёд можно бронировать
+}
+
+func (q Quota) validate() error {
+	if q.MaxActiveBookings < 0 || q.MaxWeeklyHours < 0 || q.MaxDaysAhead < 0 {
+		return ErrInvalidPolicy
+	}
+	return nil
+}
+
+// bookableUntil - позже этого момента начать бронь нельзя (MaxDaysAhead от now).
+// false - горизонт не ограничен.
+func (q Quota) bookableUntil(now time.Time) (time.Time, bool) {
+	if q.MaxDaysAhead <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, q.MaxDaysAhead), true
+}
+
+// weekStart - понедельник 00:00 недели, в которую попадает t, в поясе t.
+func weekStart(t time.Time) time.Time {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+	return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+}
+
+// weeklyMinutes - сколько минут владелец уже забронировал на неделе, начинающейся с week.
+// Бронь b (если она уже сохранена) не считается.
+func weeklyMinutes(ctx context.Context, repo domain.Repository, owner string, week time.Time, b domain.Booking) (int, error) {
+	end := week.AddDate(0, 0, 7)
+	existing, err := repo.Find(ctx, domain.ListFilter{From: week, To: end, Owner: owner})
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, e := range existing {
+		if isSameBooking(e, b) || e.Start.Before(week) || !e.Start.Before(end) {
+			continue
+		}
+		total += int(e.End.Sub(e.Start) / time.Minute)
+	}
+	return total, nil
+}
+
+// activeBookings - сколько у владельца броней, которые ещё не закончились. Бронь b не считается.
+func activeBookings(ctx context.Context, repo domain.Repository, owner string, now time.Time, b domain.Booking) (int, error) {
+	existing, err := repo.Find(ctx, domain.ListFilter{From: now, Owner: owner})
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, e := range existing {
+		if !isSameBooking(e, b) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// validateQuota проверяет личные лимиты владельца брони. Лимиты считаются по всем комнатам,
+// поэтому вызывать под блокировкой не только комнаты, но и владельца (WithRoomLock).
+func validateQuota(ctx context.Context, repo domain.Repository, policy Policy, loc *time.Location, b domain.Booking) ([]error, error) {
+	q := policy.Quota
+	if b.TelegramID == "" || q == (Quota{}) {
+		return nil, nil
+	}
+	now := time.Now()
+
+	var out []error
+	if until, ok := q.bookableUntil(now); ok && b.Start.After(until) {
+		out = append(out, violation(policy, RuleQuotaHorizon, domain.ErrQuotaHorizon, map[string]any{
+			"days": q.MaxDaysAhead,
+		}))
+	}
+
+	if q.MaxActiveBookings > 0 {
+		n, err := activeBookings(ctx, repo, b.TelegramID, now, b)
+		if err != nil {
+			return nil, err
+		}
+		if n >= q.MaxActiveBookings {
+			out = append(out, violation(policy, RuleQuotaActive, domain.ErrQuotaActive, map[string]any{
+				"limit": q.MaxActiveBookings,
+			}))
+		}
+	}
+
+	if q.MaxWeeklyHours > 0 {
+		week := weekStart(b.Start.In(loc))
+		used, err := weeklyMinutes(ctx, repo, b.TelegramID, week, b)
+		if err != nil {
+			return nil, err
+		}
+		if used+int(b.End.Sub(b.Start)/time.Minute) > q.MaxWeeklyHours*60 {
+			out = append(out, violation(policy, RuleQuotaWeeklyHours, domain.ErrQuotaWeeklyHours, map[string]any{
+				"limit":       q.MaxWeeklyHours,
+				"usedMinutes": used,
+				"weekStart":   week.Format(time.DateOnly),
+			}))
+		}
+	}
+	return out, nil
+}
+
+// QuotaUsage - сколько лимитов пользователь уже выбрал. Остаток nil - без ограничения.
+type QuotaUsage struct {
+	Quota Quota `json:"quota"`
+
+	ActiveBookings    int  `json:"activeBookings"`
+	RemainingBookings *int `json:"remainingBookings"`
+
+	WeekStart              time.Time `json:"weekStart"`
+	WeeklyMinutes          int       `json:"weeklyMinutes"`
+	RemainingWeeklyMinutes *int      `json:"remainingWeeklyMinutes"`
+
+	BookableUntil *time.Time `json:"bookableUntil"` // позже этого момента начать бронь нельзя
+}
+
+// MyQuota считает остаток личных лимитов владельца на момент at; неделя - та, в которую
+// попадает at по времени общежития.
+func (s *Service) MyQuota(ctx context.Context, owner string, at time.Time) (QuotaUsage, error) {
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return QuotaUsage{}, err
+	}
+	q := policy.Quota
+	u := QuotaUsage{Quota: q, WeekStart: weekStart(at.In(s.loc))}
+
+	if u.ActiveBookings, err = activeBookings(ctx, s.repo, owner, at, domain.Booking{}); err != nil {
+		return QuotaUsage{}, err
+	}
+	if u.WeeklyMinutes, err = weeklyMinutes(ctx, s.repo, owner, u.WeekStart, domain.Booking{}); err != nil {
+		return QuotaUsage{}, err
+	}
+
+	if q.MaxActiveBookings > 0 {
+		left := max(q.MaxActiveBookings-u.ActiveBookings, 0)
+		u.RemainingBookings = &left
+	}
+	if q.MaxWeeklyHours > 0 {
+		left := max(q.MaxWeeklyHours*60-u.WeeklyMinutes, 0)
+		u.RemainingWeeklyMinutes = &left
+	}
+	if until, ok := q.bookableUntil(at); ok {
+		u.BookableUntil = &until
+	}
+	return u, nil
+}
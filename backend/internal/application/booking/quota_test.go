@@ -0,0 +1,106 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_Quota(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(memory.NewInMemoryBookingRepo())
+
+	p := app.DefaultPolicy()
+	p.Quota = app.Quota{MaxActiveBookings: 2, MaxWeeklyHours: 3, MaxDaysAhead: 14}
+	if _, err := svc.SavePolicy(ctx, p); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+
+	// послезавтра: в пределах горизонта, все брони в одной неделе
+	day := time.Now().UTC().Add(48 * time.Hour).Truncate(24 * time.Hour)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	book := func(owner string, room domain.Room, from, to time.Time) error {
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{Start: from, End: to, Room: room, Title: "Игры", TelegramID: owner})
+		return err
+	}
+
+	if err := book("greedy", domain.Room132, at(10), at(12)); err != nil {
+		t.Fatalf("первая бронь: %v", err)
+	}
+	if err := book("greedy", domain.Room21, at(13), at(15)); !errors.Is(err, domain.ErrQuotaWeeklyHours) {
+		t.Fatalf("4 часа при лимите 3: ожидали ErrQuotaWeeklyHours, получили %v", err)
+	}
+	if err := book("greedy", domain.Room21, at(13), at(14)); err != nil {
+		t.Fatalf("ровно в лимит часов: %v", err)
+	}
+	if err := book("greedy", domain.Room256, at(16), at(17)); !errors.Is(err, domain.ErrQuotaActive) {
+		t.Fatalf("третья бронь при лимите 2: ожидали ErrQuotaActive, получили %v", err)
+	}
+	if err := book("other", domain.Room256, at(16).AddDate(0, 0, 20), at(17).AddDate(0, 0, 20)); !errors.Is(err, domain.ErrQuotaHorizon) {
+		t.Fatalf("на 3 недели вперёд: ожидали ErrQuotaHorizon, получили %v", err)
+	}
+	// чужие лимиты не задевают
+	if err := book("other", domain.Room256, at(16), at(17)); err != nil {
+		t.Fatalf("у другого пользователя свои лимиты: %v", err)
+	}
+
+	u, err := svc.MyQuota(ctx, "greedy", day)
+	if err != nil {
+		t.Fatalf("MyQuota: %v", err)
+	}
+	if u.ActiveBookings != 2 || u.RemainingBookings == nil || *u.RemainingBookings != 0 {
+		t.Fatalf("остаток броней: %+v", u)
+	}
+	if u.WeeklyMinutes != 180 || u.RemainingWeeklyMinutes == nil || *u.RemainingWeeklyMinutes != 0 {
+		t.Fatalf("остаток часов: %+v", u)
+	}
+	if u.BookableUntil == nil || !u.BookableUntil.Equal(day.AddDate(0, 0, 14)) {
+		t.Fatalf("горизонт: %+v", u.BookableUntil)
+	}
+
+	p.Quota.MaxWeeklyHours = -1
+	if _, err := svc.SavePolicy(ctx, p); !errors.Is(err, app.ErrInvalidPolicy) {
+		t.Fatalf("отрицательный лимит: ожидали ErrInvalidPolicy, получили %v", err)
+	}
+}
+
+// Подсказка к брони дальше горизонта сама должна проходить проверку,
+// а свободное время за горизонтом не предлагается.
+func TestService_CheckBooking_QuotaHorizonSuggestion(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(memory.NewInMemoryBookingRepo())
+
+	p := app.DefaultPolicy()
+	p.Quota = app.Quota{MaxDaysAhead: 3}
+	if _, err := svc.SavePolicy(ctx, p); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+
+	far := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 10).Add(14 * time.Hour)
+	in := app.CreateBookingInput{Start: far, End: far.Add(time.Hour), Room: domain.Room132, Title: "Игры", TelegramID: "planner"}
+	vs, err := svc.CheckBooking(ctx, in)
+	if err != nil {
+		t.Fatalf("CheckBooking: %v", err)
+	}
+	if len(vs) != 1 || vs[0].Code != "QUOTA_HORIZON" || vs[0].Suggestion == nil {
+		t.Fatalf("ожидали QUOTA_HORIZON с подсказкой, получили %+v", vs)
+	}
+
+	s := vs[0].Suggestion
+	if s.Start.After(time.Now().AddDate(0, 0, 3)) {
+		t.Fatalf("подсказка %s за горизонтом", s.Start)
+	}
+	in.Start, in.End = s.Start, s.End
+	if vs, err := svc.CheckBooking(ctx, in); err != nil || len(vs) != 0 {
+		t.Fatalf("подсказка должна проходить проверку, получили %+v (%v)", vs, err)
+	}
+
+	if slots, err := svc.Availability(ctx, app.AvailabilityQuery{Room: domain.Room132, Day: far}); err != nil || len(slots) != 0 {
+		t.Fatalf("за горизонтом свободного времени быть не должно, получили %+v (%v)", slots, err)
+	}
+}
@@ -0,0 +1,103 @@
+package booking
+
+// В этом файле каталог комнат: поиск комнаты для брони и админские операции над каталогом.
+
+import (
+	"context"
+	"errors"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+var errRoomsNotConfigured = errors.New("каталог комнат не подключён")
+
+// bookableRoom возвращает комнату из каталога, если в ней сейчас можно бронировать.
+func (s *Service) bookableRoom(ctx context.Context, number domain.Room) (domain.RoomInfo, error) {
+	room, err := s.GetRoom(ctx, number)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return domain.RoomInfo{}, domain.ErrInvalidRoom
+		}
+		return domain.RoomInfo{}, err
+	}
+	if !room.Active {
+		return domain.RoomInfo{}, domain.ErrInvalidRoom
+	}
+	return room, nil
+}
+
+// ListRooms возвращает каталог. Неактивные комнаты - только если includeInactive (для админки).
+func (s *Service) ListRooms(ctx context.Context, includeInactive bool) ([]domain.RoomInfo, error) {
+	var all []domain.RoomInfo
+	if s.rooms == nil {
+		all = domain.DefaultRooms()
+	} else {
+		var err error
+		if all, err = s.rooms.List(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]domain.RoomInfo, 0, len(all))
+	for _, r := range all {
+		if r.Active || includeInactive {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+// GetRoom возвращает комнату по номеру.
+func (s *Service) GetRoom(ctx context.Context, number domain.Room) (domain.RoomInfo, error) {
+	if s.rooms == nil {
+		for _, r := range domain.DefaultRooms() {
+			if r.Number == number {
+				return r, nil
+			}
+		}
+		return domain.RoomInfo{}, domain.ErrNotFound
+	}
+	return s.rooms.Get(ctx, number)
+}
+
+// CreateRoom добавляет комнату в каталог.
+func (s *Service) CreateRoom(ctx context.Context, r domain.RoomInfo) (domain.RoomInfo, error) {
+	if s.rooms == nil {
+		return domain.RoomInfo{}, errRoomsNotConfigured
+	}
+	if err := r.Validate(); err != nil {
+		return domain.RoomInfo{}, err
+	}
+	return s.rooms.Create(ctx, r)
+}
+
+// UpdateRoom меняет описание, график или активность комнаты. Уже созданные брони не трогаем.
+func (s *Service) UpdateRoom(ctx context.Context, r domain.RoomInfo) (domain.RoomInfo, error) {
+	if s.rooms == nil {
+		return domain.RoomInfo{}, errRoomsNotConfigured
+	}
+	if err := r.Validate(); err != nil {
+		return domain.RoomInfo{}, err
+	}
+	// токен QR-кода в JSON админки не ходит, меняется только через RoomCheckInToken
+	if existing, err := s.rooms.Get(ctx, r.Number); err == nil {
+		r.CheckInToken = existing.CheckInToken
+	}
+	return s.rooms.Update(ctx, r)
+}
+
+// DeleteRoom удаляет комнату, если по ней нет ни одной брони, в том числе отменённой.
+// Комнату с историей лучше выключить через Active=false.
+func (s *Service) DeleteRoom(ctx context.Context, number domain.Room) error {
+	if s.rooms == nil {
+		return errRoomsNotConfigured
+	}
+	existing, err := s.repo.Find(ctx, domain.ListFilter{Room: number, IncludeCancelled: true})
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return domain.ErrRoomInUse
+	}
+	return s.rooms.Delete(ctx, number)
+}
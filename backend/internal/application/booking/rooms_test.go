@@ -0,0 +1,97 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func newLounge() domain.RoomInfo {
+	return domain.RoomInfo{
+		Number:   310,
+		Name:     "Новая лаунж-зона",
+		Building: "Корпус 2",
+		Floor:    3,
+		Capacity: 12,
+		Active:   true,
+		Schedule: domain.Schedule{
+			WeekdayOpen: 10, WeekdayClose: 20,
+			FriSatOpen: 10, FriSatClose: 20,
+			SunOpen: 10, SunClose: 20,
+		},
+	}
+}
+
+func TestService_NewRoomIsBookableWithItsSchedule(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo(), app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)))
+
+	if _, err := svc.CreateRoom(ctx, newLounge()); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	// понедельник: 12:00 можно, 08:00 - комната ещё закрыта
+	start := time.Date(2099, 1, 5, 12, 0, 0, 0, time.UTC)
+	if _, err := svc.CreateBooking(ctx, app.CreateBookingInput{Start: start, End: start.Add(time.Hour), Room: 310, Title: "A", TelegramID: "1"}); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	early := time.Date(2099, 1, 5, 8, 0, 0, 0, time.UTC)
+	_, err := svc.CreateBooking(ctx, app.CreateBookingInput{Start: early, End: early.Add(time.Hour), Room: 310, Title: "B", TelegramID: "1"})
+	if !errors.Is(err, domain.ErrInvalidTime) {
+		t.Fatalf("ожидали ErrInvalidTime, получили %v", err)
+	}
+}
+
+func TestService_InactiveRoomIsNotBookable(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo(), app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)))
+
+	room, _ := svc.GetRoom(ctx, domain.Room21)
+	room.Active = false
+	if _, err := svc.UpdateRoom(ctx, room); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	start, end := futureInterval()
+	_, err := svc.CreateBooking(ctx, app.CreateBookingInput{Start: start, End: end, Room: domain.Room21, Title: "A", TelegramID: "1"})
+	if !errors.Is(err, domain.ErrInvalidRoom) {
+		t.Fatalf("ожидали ErrInvalidRoom, получили %v", err)
+	}
+
+	active, _ := svc.ListRooms(ctx, false)
+	if len(active) != 2 {
+		t.Fatalf("в публичном списке должно быть 2 комнаты, получили %d", len(active))
+	}
+}
+
+func TestService_DeleteRoomInUse(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	svc := app.NewService(repo, app.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)))
+
+	start, end := futureInterval()
+	repo.data["1"] = domain.Booking{ID: "1", Start: start, End: end, Room: domain.Room132, TelegramID: "1"}
+
+	if err := svc.DeleteRoom(ctx, domain.Room132); !errors.Is(err, domain.ErrRoomInUse) {
+		t.Fatalf("ожидали ErrRoomInUse, получили %v", err)
+	}
+	if err := svc.DeleteRoom(ctx, domain.Room256); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+}
+
+func TestService_CreateRoom_InvalidSchedule(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(newFakeRepo(), app.WithRoomRepo(memory.NewInMemoryRoomRepo()))
+
+	room := newLounge()
+	room.Schedule.SunClose = room.Schedule.SunOpen
+	if _, err := svc.CreateRoom(ctx, room); !errors.Is(err, domain.ErrInvalidSchedule) {
+		t.Fatalf("ожидали ErrInvalidSchedule, получили %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package booking
+
+// В этом файле повторяющиеся брони: создание серии и отмена занятий.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"Dormitory_Booking/internal/domain/audit"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+var errSeriesNotConfigured = errors.New("хранилище серий не подключено")
+
+// CreateSeriesInput - первое занятие серии и правило повторения.
+type CreateSeriesInput struct {
+	CreateBookingInput
+	Rule domain.Recurrence
+}
+
+// OccurrenceConflict - занятие серии, которое не удалось создать, и почему.
+type OccurrenceConflict struct {
+	Start time.Time
+	End   time.Time
+	Err   error
+}
+
+// SeriesResult - что получилось при создании серии.
+type SeriesResult struct {
+	Series    domain.Series
+	Created   []domain.Booking
+	Conflicts []OccurrenceConflict
+}
+
+// CreateSeries создаёт серию и все её занятия. Каждое занятие проходит те же правила,
+// что и одиночная бронь; не прошедшие попадают в Conflicts, остальные создаются.
+// Если не создалось ни одного занятия, серия не сохраняется.
+func (s *Service) CreateSeries(ctx context.Context, in CreateSeriesInput) (SeriesResult, error) {
+	if s.series == nil {
+		return SeriesResult{}, errSeriesNotConfigured
+	}
+	if err := in.Rule.Validate(); err != nil {
+		return SeriesResult{}, err
+	}
+	if !in.End.After(in.Start) {
+		return SeriesResult{}, domain.ErrInvalidPeriod
+	}
+
+	// «каждый вторник в 19:00» - по часам общежития, в том числе через переход на летнее время
+	series, err := s.series.Create(ctx, domain.Series{
+		Start:       in.Start.In(s.loc),
+		End:         in.End.In(s.loc),
+		Room:        in.Room,
+		Title:       in.Title,
+		Description: in.Description,
+		TelegramID:  in.TelegramID,
+		IsPrivate:   in.IsPrivate,
+		Rule:        in.Rule,
+	})
+	if err != nil {
+		return SeriesResult{}, err
+	}
+
+	res := SeriesResult{Series: series}
+	for _, occ := range series.Occurrences() {
+		b, err := s.createBooking(ctx, domain.Booking{
+			Start:       occ[0],
+			End:         occ[1],
+			Room:        series.Room,
+			Title:       series.Title,
+			Description: series.Description,
+			TelegramID:  series.TelegramID,
+			IsPrivate:   series.IsPrivate,
+			SeriesID:    series.ID,
+		})
+		if err != nil {
+			res.Conflicts = append(res.Conflicts, OccurrenceConflict{Start: occ[0], End: occ[1], Err: err})
+			continue
+		}
+		res.Created = append(res.Created, b)
+	}
+
+	if len(res.Created) == 0 {
+		if err := s.series.Delete(ctx, series.ID); err != nil {
+			return SeriesResult{}, err
+		}
+		res.Series = domain.Series{}
+	}
+
+	return res, nil
+}
+
+// GetSeries возвращает серию и её текущие занятия.
+func (s *Service) GetSeries(ctx context.Context, id string) (domain.Series, []domain.Booking, error) {
+	if s.series == nil {
+		return domain.Series{}, nil, errSeriesNotConfigured
+	}
+	series, err := s.series.Get(ctx, id)
+	if err != nil {
+		return domain.Series{}, nil, err
+	}
+	occurrences, err := s.repo.Find(ctx, domain.ListFilter{SeriesID: id})
+	if err != nil {
+		return domain.Series{}, nil, err
+	}
+	return series, occurrences, nil
+}
+
+// CancelSeries отменяет занятия серии, начинающиеся не раньше from и не раньше текущего
+// момента: прошедшие и уже начавшиеся занятия остаются в истории. Нулевой from - вся
+// серия, тогда она ещё и помечается завершённой (запись не удаляется, чтобы занятия
+// в истории не теряли ссылку). Права как у CancelBooking: владелец или админ.
+// Возвращает число отменённых занятий.
+func (s *Service) CancelSeries(ctx context.Context, id string, from time.Time, requesterID string, isAdmin bool) (int, error) {
+	series, occurrences, err := s.GetSeries(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	if !isAdmin && series.TelegramID != requesterID {
+		return 0, domain.ErrForbidden
+	}
+
+	now := time.Now()
+	since := from
+	if since.Before(now) {
+		since = now
+	}
+	cancelled := 0
+	for _, b := range occurrences {
+		if b.Start.Before(since) {
+			continue
+		}
+		_, err := s.repo.Cancel(ctx, b.ID, domain.Cancellation{At: now, By: requesterID})
+		if err != nil && !errors.Is(err, domain.ErrNotFound) && !errors.Is(err, domain.ErrCancelled) {
+			return cancelled, err
+		}
+		if err == nil {
+			s.record(ctx, ownerAction(b, requesterID, audit.ActionDelete), requesterID, isAdmin, &b, nil)
+			s.notifyCancelled(ctx, b, requesterID)
+			s.offerFreed(ctx, b.Room, b.Start, b.End)
+			cancelled++
+		}
+	}
+
+	if from.IsZero() {
+		if err := s.series.End(ctx, id, now); err != nil {
+			return cancelled, err
+		}
+	}
+
+	return cancelled, nil
+}
@@ -0,0 +1,139 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_CreateSeries_ReportsConflicts(t *testing.T) {
+	ctx := context.Background()
+	// у fakeRepo ID зависит только от времени суток, а у занятий серии оно одинаковое
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithSeriesRepo(memory.NewInMemorySeriesRepo()))
+
+	// вторник, 19:00-21:00, 4 недели; на третью неделю слот уже занят
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	busy := start.AddDate(0, 0, 14)
+	repo.Create(ctx, domain.Booking{Start: busy, End: busy.Add(time.Hour), Room: domain.Room256, TelegramID: "other"})
+
+	res, err := svc.CreateSeries(ctx, app.CreateSeriesInput{
+		CreateBookingInput: app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(2 * time.Hour),
+			Room:       domain.Room256,
+			Title:      "Настолки",
+			TelegramID: "club",
+		},
+		Rule: domain.Recurrence{Freq: domain.FreqWeekly, Count: 4},
+	})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if len(res.Created) != 3 || len(res.Conflicts) != 1 {
+		t.Fatalf("ожидали 3 созданных и 1 конфликт, получили %d и %d", len(res.Created), len(res.Conflicts))
+	}
+	if !errors.Is(res.Conflicts[0].Err, domain.ErrOverlap) || !res.Conflicts[0].Start.Equal(busy) {
+		t.Fatalf("конфликт должен быть ErrOverlap на %v, получили %+v", busy, res.Conflicts[0])
+	}
+	for _, b := range res.Created {
+		if b.SeriesID != res.Series.ID {
+			t.Fatalf("занятие должно ссылаться на серию")
+		}
+	}
+}
+
+func TestService_CancelSeries_RestOfSeries(t *testing.T) {
+	ctx := context.Background()
+	svc := app.NewService(memory.NewInMemoryBookingRepo(), app.WithSeriesRepo(memory.NewInMemorySeriesRepo()))
+
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	res, err := svc.CreateSeries(ctx, app.CreateSeriesInput{
+		CreateBookingInput: app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(2 * time.Hour),
+			Room:       domain.Room256,
+			Title:      "Настолки",
+			TelegramID: "club",
+		},
+		Rule: domain.Recurrence{Freq: domain.FreqWeekly, Count: 4},
+	})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	if _, err := svc.CancelSeries(ctx, res.Series.ID, time.Time{}, "stranger", false); !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("ожидали ErrForbidden, получили %v", err)
+	}
+
+	n, err := svc.CancelSeries(ctx, res.Series.ID, start.AddDate(0, 0, 14), "club", false)
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("ожидали отмену 2 занятий, получили %d", n)
+	}
+
+	_, left, err := svc.GetSeries(ctx, res.Series.ID)
+	if err != nil {
+		t.Fatalf("серия должна остаться, err=%v", err)
+	}
+	if len(left) != 2 {
+		t.Fatalf("должно остаться 2 занятия, осталось %d", len(left))
+	}
+}
+
+func TestService_CancelSeries_KeepsHistory(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithSeriesRepo(memory.NewInMemorySeriesRepo()))
+
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	res, err := svc.CreateSeries(ctx, app.CreateSeriesInput{
+		CreateBookingInput: app.CreateBookingInput{
+			Start:      start,
+			End:        start.Add(2 * time.Hour),
+			Room:       domain.Room256,
+			Title:      "Настолки",
+			TelegramID: "club",
+		},
+		Rule: domain.Recurrence{Freq: domain.FreqWeekly, Count: 3},
+	})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	// прошедшее занятие той же серии - через сервис его не создать
+	past := time.Now().Add(-7 * 24 * time.Hour).Truncate(time.Hour)
+	held, err := repo.Create(ctx, domain.Booking{Start: past, End: past.Add(2 * time.Hour), Room: domain.Room256, Title: "Настолки", TelegramID: "club", SeriesID: res.Series.ID})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	// одно будущее занятие уже отменено отдельно - в счёт не идёт
+	if err := svc.CancelBooking(ctx, res.Created[0].ID, "club", false, ""); err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	n, err := svc.CancelSeries(ctx, res.Series.ID, time.Time{}, "club", false)
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("ожидали отмену 2 занятий, получили %d", n)
+	}
+
+	series, left, err := svc.GetSeries(ctx, res.Series.ID)
+	if err != nil {
+		t.Fatalf("серия должна остаться в истории, err=%v", err)
+	}
+	if series.EndedAt == nil {
+		t.Fatalf("серия должна быть помечена завершённой")
+	}
+	if len(left) != 1 || left[0].ID != held.ID || left[0].SeriesID != res.Series.ID {
+		t.Fatalf("прошедшее занятие должно остаться в серии, осталось: %+v", left)
+	}
+}
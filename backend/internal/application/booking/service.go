@@ -6,17 +6,91 @@ package booking
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"Dormitory_Booking/internal/domain/audit"
 	domain "Dormitory_Booking/internal/domain/booking"
 )
 
 type Service struct {
-	repo domain.Repository
+	repo     domain.Repository
+	series   domain.SeriesRepository
+	rooms    domain.RoomRepository
+	policies PolicyStore
+	notifier Notifier
+	audit    audit.Repository
+	waitlist domain.WaitlistRepository
+	hold     time.Duration // сколько освободившийся слот ждёт подтверждения
+	noShows  domain.NoShowRepository
+	loc      *time.Location // пояс общежития: в нём считаются часы работы, ночи и лимиты на день
+	events   domain.EventBus
+	changes  domain.ChangeLog
 }
 
-func NewService(repo domain.Repository) *Service {
-	return &Service{repo: repo}
+// Notifier узнаёт о событиях, о которых надо сообщить владельцу брони.
+// Ошибки доставки - забота самого Notifier, на операцию с бронью они не влияют.
+type Notifier interface {
+	// BookingCancelled - бронь удалил не владелец, а администратор.
+	BookingCancelled(ctx context.Context, b domain.Booking)
+	// WaitlistOffer - время из заявки освободилось и держится за заявителем до e.HoldUntil.
+	WaitlistOffer(ctx context.Context, e domain.WaitlistEntry)
+}
+
+// Option - необязательная зависимость сервиса.
+type Option func(*Service)
+
+// WithSeriesRepo подключает хранилище серий (повторяющихся броней).
+func WithSeriesRepo(series domain.SeriesRepository) Option {
+	return func(s *Service) {
+		s.series = series
+	}
+}
+
+// WithRoomRepo подключает каталог комнат. Без него работают только комнаты из domain.DefaultRooms.
+func WithRoomRepo(rooms domain.RoomRepository) Option {
+	return func(s *Service) {
+		s.rooms = rooms
+	}
+}
+
+// WithPolicyStore подключает хранилище политики. Без него действует DefaultPolicy.
+func WithPolicyStore(policies PolicyStore) Option {
+	return func(s *Service) {
+		s.policies = policies
+	}
+}
+
+// WithNotifier подключает уведомления владельцам броней.
+func WithNotifier(n Notifier) Option {
+	return func(s *Service) {
+		s.notifier = n
+	}
+}
+
+// WithAuditLog подключает журнал изменений броней.
+func WithAuditLog(log audit.Repository) Option {
+	return func(s *Service) {
+		s.audit = log
+	}
+}
+
+// WithLocation задаёт часовой пояс общежития. Все правила считаются в нём, какой бы
+// сдвиг ни прислал клиент. По умолчанию UTC.
+func WithLocation(loc *time.Location) Option {
+	return func(s *Service) {
+		if loc != nil {
+			s.loc = loc
+		}
+	}
+}
+
+func NewService(repo domain.Repository, opts ...Option) *Service {
+	s := &Service{repo: repo, policies: NewStaticPolicyStore(), loc: time.UTC}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
 // CreateBookingInput - данные от HTTP/бота/парсера для создания брони.
@@ -30,18 +104,39 @@ type CreateBookingInput struct {
 	IsPrivate   bool        // частная посиделка или нет
 }
 
+// UpdateBookingInput - частичные изменения брони. nil означает "не трогать поле".
+type UpdateBookingInput struct {
+	Start       *time.Time
+	End         *time.Time
+	Room        *domain.Room
+	Title       *string
+	Description *string
+	IsPrivate   *bool
+}
+
+// Location - часовой пояс общежития, в котором считаются правила.
+func (s *Service) Location() *time.Location {
+	return s.loc
+}
+
 // ListBookings возвращает все брони.
 func (s *Service) ListBookings(ctx context.Context) ([]domain.Booking, error) {
 	return s.repo.List(ctx)
 }
 
+// FindBookings возвращает брони под фильтр (диапазон, комната, владелец, приватность).
+func (s *Service) FindBookings(ctx context.Context, f domain.ListFilter) ([]domain.Booking, error) {
+	return s.repo.Find(ctx, f)
+}
+
 // GetBooking возвращает бронь по ID.
 func (s *Service) GetBooking(ctx context.Context, id string) (domain.Booking, error) {
 	return s.repo.Get(ctx, id)
 }
 
-// DeleteBooking - удалить бронь может только владелец или админ.
-func (s *Service) DeleteBooking(ctx context.Context, id string, requesterID string, isAdmin bool) error {
+// CancelBooking отменяет бронь: она остаётся в истории со статусом cancelled и причиной.
+// Отменить может только владелец или админ.
+func (s *Service) CancelBooking(ctx context.Context, id string, requesterID string, isAdmin bool, reason string) error {
 	b, err := s.repo.Get(ctx, id)
 	if err != nil {
 		return err
@@ -51,7 +146,41 @@ func (s *Service) DeleteBooking(ctx context.Context, id string, requesterID stri
 		return domain.ErrForbidden
 	}
 
-	return s.repo.Delete(ctx, id)
+	if _, err := s.repo.Cancel(ctx, id, domain.Cancellation{At: time.Now(), By: requesterID, Reason: reason}); err != nil {
+		return err
+	}
+	s.record(ctx, ownerAction(b, requesterID, audit.ActionDelete), requesterID, isAdmin, &b, nil)
+	s.notifyCancelled(ctx, b, requesterID)
+	s.offerFreed(ctx, b.Room, b.Start, b.End)
+	return nil
+}
+
+// RestoreBooking возвращает отменённую бронь, если её время всё ещё свободно. Только для админа.
+func (s *Service) RestoreBooking(ctx context.Context, id string, adminID string) (domain.Booking, error) {
+	b, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	var restored domain.Booking
+	err = s.repo.WithRoomLock(ctx, []domain.Room{b.Room}, nil, func(repo domain.Repository) error {
+		var err error
+		restored, err = repo.Restore(ctx, id)
+		return err
+	})
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	s.record(ctx, audit.ActionRestore, adminID, true, &b, &restored)
+	return restored, nil
+}
+
+// notifyCancelled сообщает владельцу, что его бронь удалил кто-то другой (администратор).
+func (s *Service) notifyCancelled(ctx context.Context, b domain.Booking, requesterID string) {
+	if s.notifier != nil && b.TelegramID != requesterID {
+		s.notifier.BookingCancelled(ctx, b)
+	}
 }
 
 // CreateBooking создаёт новую бронь с учётом всех правил.
@@ -66,42 +195,215 @@ func (s *Service) CreateBooking(ctx context.Context, in CreateBookingInput) (dom
 		IsPrivate:   in.IsPrivate,
 	}
 
-	if err := b.ValidateBasic(); err != nil {
+	return s.createBooking(ctx, b)
+}
+
+// createBooking - общая часть для одиночной брони и занятий серии.
+func (s *Service) createBooking(ctx context.Context, b domain.Booking) (domain.Booking, error) {
+	room, err := s.bookableRoom(ctx, b.Room)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
 		return domain.Booking{}, err
 	}
 
-	// общие ограничения по длительности
-	if err := validateDuration(b); err != nil {
+	// проверки и запись под одной блокировкой комнаты и владельца, иначе два параллельных
+	// запроса (даже в разные комнаты) оба пройдут лимиты и оба запишутся
+	var created domain.Booking
+	err = s.repo.WithRoomLock(ctx, []domain.Room{b.Room}, []string{b.TelegramID}, func(repo domain.Repository) error {
+		if err := validateBooking(ctx, repo, room, policy, s.loc, b, nil); err != nil {
+			return err
+		}
+		if err := s.checkHold(ctx, b); err != nil {
+			return err
+		}
+		var err error
+		created, err = repo.Create(ctx, b)
+		return err
+	})
+	if err != nil {
 		return domain.Booking{}, err
 	}
 
-	// ограничения по графику работы комнаты
-	if err := validateRoomSchedule(b); err != nil {
+	s.record(ctx, audit.ActionCreate, created.TelegramID, false, nil, &created)
+	return created, nil
+}
+
+// UpdateBooking меняет существующую бронь. Права те же, что и на удаление:
+// владелец или админ. Все правила проверяются заново, саму бронь при этом не считаем конфликтом.
+func (s *Service) UpdateBooking(ctx context.Context, id string, requesterID string, isAdmin bool, in UpdateBookingInput) (domain.Booking, error) {
+	current, err := s.repo.Get(ctx, id)
+	if err != nil {
 		return domain.Booking{}, err
 	}
 
+	rooms := []domain.Room{current.Room}
+	target := current.Room
+	if in.Room != nil {
+		rooms = append(rooms, *in.Room)
+		target = *in.Room
+	}
+	room, err := s.bookableRoom(ctx, target)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	var before, updated domain.Booking
+	err = s.repo.WithRoomLock(ctx, rooms, []string{current.TelegramID}, func(repo domain.Repository) error {
+		// перечитываем под блокировкой: бронь могли поменять между Get и захватом
+		b, err := repo.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if !isAdmin && b.TelegramID != requesterID {
+			return domain.ErrForbidden
+		}
+		if b.Cancelled() {
+			return domain.ErrCancelled
+		}
+		before = b
+
+		if in.Start != nil {
+			b.Start = *in.Start
+		}
+		if in.End != nil {
+			b.End = *in.End
+		}
+		if in.Room != nil {
+			b.Room = *in.Room
+		}
+		if in.Title != nil {
+			b.Title = *in.Title
+		}
+		if in.Description != nil {
+			b.Description = *in.Description
+		}
+		if in.IsPrivate != nil {
+			b.IsPrivate = *in.IsPrivate
+		}
+
+		if err := validateBooking(ctx, repo, room, policy, s.loc, b, &before); err != nil {
+			return err
+		}
+		if err := s.checkHold(ctx, b); err != nil {
+			return err
+		}
+
+		updated, err = repo.Update(ctx, b)
+		return err
+	})
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	s.record(ctx, ownerAction(before, requesterID, audit.ActionUpdate), requesterID, isAdmin, &before, &updated)
+	// перенос или сокращение могли освободить время для очереди
+	s.offerFreed(ctx, before.Room, before.Start, before.End)
+	return updated, nil
+}
+
+// validateBooking прогоняет бронь через все правила и возвращает первое нарушение.
+// Если у брони уже есть ID (редактирование), она сама не учитывается в лимитах и пересечениях.
+// repo - репозиторий внутри WithRoomLock, room - запись каталога для b.Room,
+// prev - бронь до редактирования (nil - новая бронь).
+func validateBooking(ctx context.Context, repo domain.Repository, room domain.RoomInfo, policy Policy, loc *time.Location, b domain.Booking, prev *domain.Booking) error {
+	violations, err := bookingViolations(ctx, repo, room, policy, loc, b, prev)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// bookingViolations прогоняет бронь через все правила и собирает все нарушения в порядке проверки.
+// Часы, дни и ночи считаются в поясе общежития loc, а не в сдвиге, с которым пришло время брони.
+// Начало в прошлом - нарушение, только если его и меняют: у уже идущей брони (prev с тем же
+// началом) можно поправить название или продлить конец. Ошибка вторым значением - только сбой хранилища.
+func bookingViolations(ctx context.Context, repo domain.Repository, room domain.RoomInfo, policy Policy, loc *time.Location, b domain.Booking, prev *domain.Booking) ([]error, error) {
+	var out []error
+	add := func(err error) {
+		for _, e := range out {
+			if e == err {
+				return
+			}
+		}
+		out = append(out, err)
+	}
+
+	startKept := prev != nil && prev.Start.Equal(b.Start)
+	if err := b.ValidateBasic(); err != nil && !(startKept && errors.Is(err, domain.ErrInPast)) {
+		add(err)
+		// без комнаты и с перевёрнутым интервалом остальное проверять бессмысленно
+		if !errors.Is(err, domain.ErrInPast) {
+			return out, nil
+		}
+	}
+
+	rules := policy.For(b.Room)
+
+	// общие ограничения по длительности
+	if err := validateDuration(b, policy, rules); err != nil {
+		add(err)
+		if errors.Is(err, domain.ErrInvalidPeriod) {
+			return out, nil
+		}
+	}
+
+	// ограничения по графику работы комнаты
+	if err := validateRoomSchedule(b, room.Schedule, loc); err != nil {
+		add(err)
+	}
+
 	// частные посиделки: ночь, лимиты на день/вечер
 	if b.IsPrivate {
-		if err := s.validatePrivateRules(ctx, b); err != nil {
-			return domain.Booking{}, err
+		private, err := validatePrivateRules(ctx, repo, policy, rules, loc, b)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range private {
+			add(v)
 		}
 	}
 
+	// личные лимиты владельца по всем комнатам
+	quota, err := validateQuota(ctx, repo, policy, loc, b)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range quota {
+		add(v)
+	}
+
 	// проверка пересечений по времени в той же комнате
-	existing, err := s.repo.List(ctx)
+	existing, err := repo.Find(ctx, domain.ListFilter{From: b.Start, To: b.End, Room: b.Room})
 	if err != nil {
-		return domain.Booking{}, err
+		return nil, err
 	}
 	for _, e := range existing {
-		if e.Room != b.Room {
+		if isSameBooking(e, b) {
 			continue
 		}
 		if timesOverlap(b.Start, b.End, e.Start, e.End) {
-			return domain.Booking{}, domain.ErrOverlap
+			add(domain.ErrOverlap)
+			break
 		}
 	}
 
-	return s.repo.Create(ctx, b)
+	return out, nil
+}
+
+// isSameBooking - true, если e и b это одна и та же уже сохранённая бронь.
+func isSameBooking(e, b domain.Booking) bool {
+	return b.ID != "" && e.ID == b.ID
 }
 
 // timesOverlap проверяет пересечение двух временных интервалов.
@@ -111,120 +413,97 @@ func timesOverlap(s1, e1, s2, e2 time.Time) bool {
 
 // Ограничения по длительности брони.
 
-const maxBookingDuration = 3 * time.Hour
-
-func validateDuration(b domain.Booking) error {
-
-	// Лимит 3 часа только на приватные
-
+func validateDuration(b domain.Booking, policy Policy, rules Rules) error {
 	dur := b.End.Sub(b.Start)
 	if dur <= 0 {
 		return domain.ErrInvalidPeriod
 	}
 
-	if b.IsPrivate && dur > maxBookingDuration {
-		return domain.ErrTooLongDuration
+	// лимит длительности только на приватные
+	maxDur := time.Duration(rules.MaxPrivateMinutes) * time.Minute
+	if b.IsPrivate && maxDur > 0 && dur > maxDur {
+		return violation(policy, RulePrivateMaxDuration, domain.ErrTooLongDuration, map[string]any{
+			"maxMinutes": rules.MaxPrivateMinutes,
+		})
 	}
 	return nil
 }
 
 // График работы комнат.
 
-// Для удобства храним часы работы в виде смещений от полуночи.
-// Закрытие может быть позже 23:59 (например, 25:00 = 01:00 следующего дня).
-type roomSchedule struct {
-	WeekdayOpen  int // часы с 0 до 24
-	WeekdayClose int
-	FriSatOpen   int
-	FriSatClose  int
-	SunOpen      int
-	SunClose     int
-}
-
-var roomSchedules = map[domain.Room]roomSchedule{
-	domain.Room21: {
-		WeekdayOpen: 6, WeekdayClose: 23,
-		FriSatOpen: 6, FriSatClose: 25, // до 01:00
-		SunOpen: 6, SunClose: 23,
-	},
-	domain.Room256: {
-		WeekdayOpen: 6, WeekdayClose: 23,
-		FriSatOpen: 6, FriSatClose: 25,
-		SunOpen: 6, SunClose: 23,
-	},
-	domain.Room132: {
-		WeekdayOpen: 6, WeekdayClose: 22,
-		FriSatOpen: 6, FriSatClose: 23,
-		SunOpen: 6, SunClose: 22,
-	},
-}
-
 // validateRoomSchedule проверяет, что бронь целиком укладывается в разрешённые часы работы комнаты.
-func validateRoomSchedule(b domain.Booking) error {
-	sched, ok := roomSchedules[b.Room]
-	if !ok {
-		return domain.ErrInvalidRoom
-	}
-
-	loc := b.Start.Location()
+func validateRoomSchedule(b domain.Booking, sched domain.Schedule, loc *time.Location) error {
 	startLocal := b.Start.In(loc)
 	endLocal := b.End.In(loc)
 
 	dayStart := time.Date(startLocal.Year(), startLocal.Month(), startLocal.Day(), 0, 0, 0, 0, loc)
-	var openHour, closeHour int
-
-	switch startLocal.Weekday() {
-	case time.Friday, time.Saturday:
-		openHour = sched.FriSatOpen
-		closeHour = sched.FriSatClose
-	case time.Sunday:
-		openHour = sched.SunOpen
-		closeHour = sched.SunClose
-	default:
-		openHour = sched.WeekdayOpen
-		closeHour = sched.WeekdayClose
-	}
+	openHour, closeHour := scheduleHours(sched, startLocal.Weekday())
 
 	openTime := dayStart.Add(time.Duration(openHour) * time.Hour)
 	closeTime := dayStart.Add(time.Duration(closeHour) * time.Hour) // может быть > 24ч (до 01:00)
 
 	if startLocal.Before(openTime) || endLocal.After(closeTime) {
-		return domain.ErrInvalidTime
+		return &RuleViolation{Rule: RuleRoomSchedule, Err: domain.ErrInvalidTime, Params: map[string]any{
+			"openHour":  openHour,
+			"closeHour": closeHour,
+		}}
 	}
+	return nil
+}
 
-	now := time.Now().In(loc)
-	if startLocal.Before(now) {
-		return domain.ErrInPast
+// scheduleHours - часы открытия и закрытия комнаты в этот день недели.
+func scheduleHours(sched domain.Schedule, wd time.Weekday) (int, int) {
+	switch wd {
+	case time.Friday, time.Saturday:
+		return sched.FriSatOpen, sched.FriSatClose
+	case time.Sunday:
+		return sched.SunOpen, sched.SunClose
+	default:
+		return sched.WeekdayOpen, sched.WeekdayClose
 	}
-
-	return nil
 }
 
 // "Частные посиделки" (ЧП)
 
-// validatePrivateRules проверяет ночь, лимит ЧП в день и лимит вечерних ЧП.
-func (s *Service) validatePrivateRules(ctx context.Context, b domain.Booking) error {
-	loc := b.Start.Location()
+// validatePrivateRules проверяет тихие ночи, лимит ЧП в день и лимит вечерних ЧП
+// и возвращает все нарушения.
+func validatePrivateRules(ctx context.Context, repo domain.Repository, policy Policy, rules Rules, loc *time.Location, b domain.Booking) ([]error, error) {
 	startLocal := b.Start.In(loc)
 	endLocal := b.End.In(loc)
 
-	// Нет ЧП в ночь с пятницы на субботу и с субботы на воскресенье в 23:00–06:00.
-	if overlapsForbiddenPrivateNight(startLocal, endLocal) {
-		return domain.ErrInvalidTime
+	// Нет ЧП в тихие ночи (по умолчанию с пятницы на субботу и с субботы на воскресенье, 23:00–06:00).
+	var out []error
+	if w, ok := overlapsForbiddenPrivateNight(startLocal, endLocal, rules.QuietNights); ok {
+		out = append(out, violation(policy, RulePrivateQuietNight, domain.ErrInvalidTime, map[string]any{
+			"weekday":   w.Weekday.String(),
+			"startHour": w.StartHour,
+			"hours":     w.Hours,
+		}))
 	}
 
-	// Не более 3 ЧП в день, не более одной ЧП после 18:00 по комнате.
-	existing, err := s.repo.List(ctx)
-	if err != nil {
-		return err
+	if rules.PrivateDailyLimit == 0 && rules.PrivateEveningLimit == 0 {
+		return out, nil
 	}
 
+	// Лимит ЧП в день и лимит вечерних ЧП по комнате.
 	dayY, dayM, dayD := startLocal.Date()
+	dayStart := time.Date(dayY, dayM, dayD, 0, 0, 0, 0, loc)
+	isPrivate := true
+	existing, err := repo.Find(ctx, domain.ListFilter{
+		From:      dayStart,
+		To:        dayStart.AddDate(0, 0, 1),
+		Room:      b.Room,
+		IsPrivate: &isPrivate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	privateCountDay := 0
 	privateEveningCount := 0
 
 	for _, e := range existing {
-		if !e.IsPrivate || e.Room != b.Room {
+		if isSameBooking(e, b) {
 			continue
 		}
 
@@ -232,42 +511,49 @@ func (s *Service) validatePrivateRules(ctx context.Context, b domain.Booking) er
 		y, m, d := eLocal.Date()
 		if y == dayY && m == dayM && d == dayD {
 			privateCountDay++
-			if eLocal.Hour() >= 18 {
+			if eLocal.Hour() >= rules.EveningStartHour {
 				privateEveningCount++
 			}
 		}
 	}
 
-	if privateCountDay >= 3 {
-		return domain.ErrPrivateDailyLimit
+	if rules.PrivateDailyLimit > 0 && privateCountDay >= rules.PrivateDailyLimit {
+		out = append(out, violation(policy, RulePrivateDailyLimit, domain.ErrPrivateDailyLimit, map[string]any{
+			"limit": rules.PrivateDailyLimit,
+		}))
 	}
 
-	if startLocal.Hour() >= 18 && privateEveningCount >= 1 {
-		return domain.ErrPrivateEveningLimit
+	if rules.PrivateEveningLimit > 0 && startLocal.Hour() >= rules.EveningStartHour && privateEveningCount >= rules.PrivateEveningLimit {
+		out = append(out, violation(policy, RulePrivateEvening, domain.ErrPrivateEveningLimit, map[string]any{
+			"limit":            rules.PrivateEveningLimit,
+			"eveningStartHour": rules.EveningStartHour,
+		}))
 	}
 
-	return nil
+	return out, nil
 }
 
-// overlapsForbiddenPrivateNight проверяет, пересекает ли бронь ночные интервалы.
-func overlapsForbiddenPrivateNight(start, end time.Time) bool {
+// overlapsForbiddenPrivateNight проверяет, пересекает ли бронь какую-нибудь тихую ночь,
+// и если да - возвращает её. start и end должны быть уже в поясе общежития.
+func overlapsForbiddenPrivateNight(start, end time.Time, nights []QuietWindow) (QuietWindow, bool) {
 	loc := start.Location()
 	dayStart := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
 	dayEnd := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, loc)
 
-	for day := dayStart.Add(-24 * time.Hour); !day.After(dayEnd.Add(24 * time.Hour)); day = day.Add(24 * time.Hour) {
-		wd := day.Weekday()
-		if wd != time.Friday && wd != time.Saturday {
-			continue
-		}
+	for day := dayStart.AddDate(0, 0, -1); !day.After(dayEnd.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		for _, w := range nights {
+			if day.Weekday() != w.Weekday {
+				continue
+			}
 
-		nightStart := time.Date(day.Year(), day.Month(), day.Day(), 23, 0, 0, 0, loc)
-		nightEnd := nightStart.Add(7 * time.Hour) // до 06:00 следующего дня
+			nightStart := time.Date(day.Year(), day.Month(), day.Day(), w.StartHour, 0, 0, 0, loc)
+			nightEnd := nightStart.Add(time.Duration(w.Hours) * time.Hour)
 
-		if timesOverlap(start, end, nightStart, nightEnd) {
-			return true
+			if timesOverlap(start, end, nightStart, nightEnd) {
+				return w, true
+			}
 		}
 	}
 
-	return false
+	return QuietWindow{}, false
 }
@@ -8,6 +8,7 @@ import (
 
 	app "Dormitory_Booking/internal/application/booking"
 	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
 )
 
 type fakeRepo struct {
@@ -28,6 +29,16 @@ func (r *fakeRepo) List(ctx context.Context) ([]domain.Booking, error) {
 	return res, nil
 }
 
+func (r *fakeRepo) Find(ctx context.Context, f domain.ListFilter) ([]domain.Booking, error) {
+	res := make([]domain.Booking, 0)
+	for _, b := range r.data {
+		if f.Matches(b) {
+			res = append(res, b)
+		}
+	}
+	return res, nil
+}
+
 func (r *fakeRepo) Get(ctx context.Context, id string) (domain.Booking, error) {
 	b, ok := r.data[id]
 	if !ok {
@@ -44,12 +55,46 @@ func (r *fakeRepo) Create(ctx context.Context, b domain.Booking) (domain.Booking
 	return b, nil
 }
 
-func (r *fakeRepo) Delete(ctx context.Context, id string) error {
-	if _, ok := r.data[id]; !ok {
-		return domain.ErrNotFound
+func (r *fakeRepo) Update(ctx context.Context, b domain.Booking) (domain.Booking, error) {
+	if _, ok := r.data[b.ID]; !ok {
+		return domain.Booking{}, domain.ErrNotFound
+	}
+	r.data[b.ID] = b
+	return b, nil
+}
+
+func (r *fakeRepo) Cancel(ctx context.Context, id string, c domain.Cancellation) (domain.Booking, error) {
+	b, ok := r.data[id]
+	if !ok {
+		return domain.Booking{}, domain.ErrNotFound
+	}
+	b.Status, b.CancelledAt, b.CancelledBy, b.CancelReason = domain.StatusCancelled, &c.At, c.By, c.Reason
+	r.data[id] = b
+	return b, nil
+}
+
+func (r *fakeRepo) Restore(ctx context.Context, id string) (domain.Booking, error) {
+	b, ok := r.data[id]
+	if !ok {
+		return domain.Booking{}, domain.ErrNotFound
+	}
+	b.Status, b.CancelledAt, b.CancelledBy, b.CancelReason = domain.StatusActive, nil, "", ""
+	r.data[id] = b
+	return b, nil
+}
+
+func (r *fakeRepo) CheckIn(ctx context.Context, id string, at time.Time) (domain.Booking, error) {
+	b, ok := r.data[id]
+	if !ok {
+		return domain.Booking{}, domain.ErrNotFound
 	}
-	delete(r.data, id)
-	return nil
+	b.CheckedInAt = &at
+	r.data[id] = b
+	return b, nil
+}
+
+func (r *fakeRepo) WithRoomLock(ctx context.Context, rooms []domain.Room, owners []string, fn func(repo domain.Repository) error) error {
+	return fn(r)
 }
 
 func futureInterval() (time.Time, time.Time) {
@@ -158,7 +203,7 @@ func TestService_GetBooking_NotFound(t *testing.T) {
 	}
 }
 
-func TestService_DeleteBooking_Forbidden(t *testing.T) {
+func TestService_CancelBooking_Forbidden(t *testing.T) {
 	ctx := context.Background()
 	repo := newFakeRepo()
 	svc := app.NewService(repo)
@@ -172,13 +217,13 @@ func TestService_DeleteBooking_Forbidden(t *testing.T) {
 		TelegramID: "owner",
 	}
 
-	err := svc.DeleteBooking(ctx, "1", "not-owner", false)
+	err := svc.CancelBooking(ctx, "1", "not-owner", false, "")
 	if !errors.Is(err, domain.ErrForbidden) {
 		t.Fatalf("ожидали ErrForbidden, получили %v", err)
 	}
 }
 
-func TestService_DeleteBooking_AdminCanDelete(t *testing.T) {
+func TestService_CancelBooking_AdminCanCancel(t *testing.T) {
 	ctx := context.Background()
 	repo := newFakeRepo()
 	svc := app.NewService(repo)
@@ -192,11 +237,109 @@ func TestService_DeleteBooking_AdminCanDelete(t *testing.T) {
 		TelegramID: "owner",
 	}
 
-	err := svc.DeleteBooking(ctx, "1", "some-admin", true)
+	err := svc.CancelBooking(ctx, "1", "some-admin", true, "ремонт")
+	if err != nil {
+		t.Fatalf("админ должен уметь отменять, err=%v", err)
+	}
+	b, ok := repo.data["1"]
+	if !ok || !b.Cancelled() || b.CancelledBy != "some-admin" || b.CancelReason != "ремонт" || b.CancelledAt == nil {
+		t.Fatalf("бронь должна остаться в истории отменённой, получили %+v", b)
+	}
+}
+
+func TestService_UpdateBooking_MoveWithinOwnSlot(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	svc := app.NewService(repo)
+
+	start, end := futureInterval()
+	repo.data["1"] = domain.Booking{
+		ID:         "1",
+		Start:      start,
+		End:        end,
+		Room:       domain.Room21,
+		Title:      "Настолки",
+		TelegramID: "owner",
+	}
+
+	// сдвигаем на 30 минут: пересекается сама с собой, но это не конфликт
+	newStart := start.Add(30 * time.Minute)
+	newEnd := end.Add(30 * time.Minute)
+	updated, err := svc.UpdateBooking(ctx, "1", "owner", false, app.UpdateBookingInput{
+		Start: &newStart,
+		End:   &newEnd,
+	})
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+	if !updated.Start.Equal(newStart) || !updated.End.Equal(newEnd) {
+		t.Fatalf("время не обновилось: %v - %v", updated.Start, updated.End)
+	}
+	if repo.data["1"].Title != "Настолки" {
+		t.Fatalf("поля, которых нет во входе, трогать нельзя")
+	}
+}
+
+func TestService_UpdateBooking_Overlap(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	svc := app.NewService(repo)
+
+	start, end := futureInterval()
+	repo.data["1"] = domain.Booking{ID: "1", Start: start, End: end, Room: domain.Room21, Title: "A", TelegramID: "owner"}
+	repo.data["2"] = domain.Booking{ID: "2", Start: end, End: end.Add(time.Hour), Room: domain.Room21, Title: "B", TelegramID: "other"}
+
+	newEnd := end.Add(30 * time.Minute)
+	_, err := svc.UpdateBooking(ctx, "1", "owner", false, app.UpdateBookingInput{End: &newEnd})
+	if !errors.Is(err, domain.ErrOverlap) {
+		t.Fatalf("ожидали ErrOverlap, получили %v", err)
+	}
+}
+
+func TestService_UpdateBooking_InProgress(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	// комната открыта круглые сутки, чтобы тест не зависел от того, когда его запускают
+	allDay := domain.Schedule{WeekdayClose: 25, FriSatClose: 25, SunClose: 25}
+	svc := app.NewService(repo, app.WithRoomRepo(memory.NewInMemoryRoomRepo(
+		domain.RoomInfo{Number: domain.Room21, Name: "Досуговая 21", Active: true, Schedule: allDay})))
+
+	now := time.Now()
+	repo.data["1"] = domain.Booking{ID: "1", Start: now.Add(-10 * time.Minute), End: now.Add(20 * time.Minute), Room: domain.Room21, Title: "Настолки", TelegramID: "owner"}
+
+	// уже идущую бронь можно продлить и переименовать
+	newEnd := now.Add(40 * time.Minute)
+	title := "Настолки до победного"
+	updated, err := svc.UpdateBooking(ctx, "1", "owner", false, app.UpdateBookingInput{End: &newEnd, Title: &title})
 	if err != nil {
-		t.Fatalf("админ должен уметь удалять, err=%v", err)
+		t.Fatalf("продление идущей брони: ожидали nil, получили %v", err)
 	}
-	if _, ok := repo.data["1"]; ok {
-		t.Fatalf("бронь должна быть удалена из репозитория")
+	if !updated.End.Equal(newEnd) || updated.Title != title {
+		t.Fatalf("бронь не обновилась: %+v", updated)
+	}
+
+	// а перенести начало в прошлое по-прежнему нельзя
+	earlier := now.Add(-20 * time.Minute)
+	if _, err := svc.UpdateBooking(ctx, "1", "owner", false, app.UpdateBookingInput{Start: &earlier}); !errors.Is(err, domain.ErrInPast) {
+		t.Fatalf("перенос начала в прошлое: ожидали ErrInPast, получили %v", err)
+	}
+}
+
+func TestService_UpdateBooking_Forbidden(t *testing.T) {
+	ctx := context.Background()
+	repo := newFakeRepo()
+	svc := app.NewService(repo)
+
+	start, end := futureInterval()
+	repo.data["1"] = domain.Booking{ID: "1", Start: start, End: end, Room: domain.Room21, Title: "A", TelegramID: "owner"}
+
+	title := "Чужое"
+	_, err := svc.UpdateBooking(ctx, "1", "not-owner", false, app.UpdateBookingInput{Title: &title})
+	if !errors.Is(err, domain.ErrForbidden) {
+		t.Fatalf("ожидали ErrForbidden, получили %v", err)
+	}
+
+	if _, err := svc.UpdateBooking(ctx, "1", "admin", true, app.UpdateBookingInput{Title: &title}); err != nil {
+		t.Fatalf("админ должен уметь редактировать, err=%v", err)
 	}
 }
@@ -0,0 +1,136 @@
+package booking_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+var msk = time.FixedZone("MSK", 3*60*60)
+
+// offsets - один и тот же момент, записанный с разными сдвигами
+var offsets = []*time.Location{msk, time.UTC, time.FixedZone("EST", -5*60*60), time.FixedZone("JST", 9*60*60)}
+
+func violationCodes(vs []app.Violation) []apperror.Code {
+	out := make([]apperror.Code, 0, len(vs))
+	for _, v := range vs {
+		out = append(out, v.Code)
+	}
+	return out
+}
+
+func TestService_RulesUseDormLocation(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithLocation(msk))
+
+	// пятница по Москве; вечерняя ЧП уже есть
+	day := time.Date(2099, 1, 2, 0, 0, 0, 0, msk)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(18), End: at(20), Room: domain.Room21, TelegramID: "a", IsPrivate: true})
+
+	cases := []struct {
+		name       string
+		room       domain.Room
+		start, end time.Time
+		private    bool
+		want       []apperror.Code
+	}{
+		// 08:00-09:00 по Москве - это 05:00Z, до открытия по UTC, но комната уже работает
+		{"утро", domain.Room132, at(8), at(9), false, nil},
+		// 05:00-06:00 по Москве - комната ещё закрыта, хотя по UTC это 02:00 прошлого дня
+		{"до открытия", domain.Room132, at(5), at(6), false, []apperror.Code{apperror.CodeInvalidTime}},
+		// 20:00-02:00 по Москве: длительность, часы работы, тихая ночь и вечерний лимит
+		{"ночная ЧП", domain.Room21, at(20), at(26), true, []apperror.Code{
+			apperror.CodeTooLongDuration, apperror.CodeInvalidTime, apperror.CodeInvalidTime, apperror.CodePrivateEveningLimit,
+		}},
+		// 13:00-15:00 по Москве - днём ЧП можно, хотя по JST это уже 19:00
+		{"дневная ЧП", domain.Room21, at(13), at(15), true, nil},
+	}
+
+	for _, c := range cases {
+		for _, loc := range offsets {
+			in := app.CreateBookingInput{
+				Start: c.start.In(loc), End: c.end.In(loc),
+				Room: c.room, Title: c.name, TelegramID: "b", IsPrivate: c.private,
+			}
+			violations, err := svc.CheckBooking(ctx, in)
+			if err != nil {
+				t.Fatalf("%s (%s): ожидали nil, получили %v", c.name, loc, err)
+			}
+			got := violationCodes(violations)
+			if len(got) != len(c.want) {
+				t.Fatalf("%s (%s): ожидали %v, получили %v", c.name, loc, c.want, got)
+			}
+			for i := range c.want {
+				if got[i] != c.want[i] {
+					t.Fatalf("%s (%s): ожидали %v, получили %v", c.name, loc, c.want, got)
+				}
+			}
+		}
+	}
+}
+
+func TestService_CreateBooking_SameResultForAnyOffset(t *testing.T) {
+	ctx := context.Background()
+
+	// понедельник 22:30-23:30 по Москве: комната 21 в будни закрывается в 23:00,
+	// хотя по UTC это ещё 19:30 - нельзя с любым сдвигом
+	start := time.Date(2099, 1, 5, 22, 30, 0, 0, msk)
+	for _, loc := range offsets {
+		svc := app.NewService(memory.NewInMemoryBookingRepo(), app.WithLocation(msk))
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start: start.In(loc), End: start.Add(time.Hour).In(loc),
+			Room: domain.Room21, Title: "Поздно", TelegramID: "a",
+		})
+		if err == nil {
+			t.Fatalf("%s: бронь после закрытия комнаты должна отклоняться", loc)
+		}
+	}
+
+	// та же бронь на час раньше умещается в часы работы
+	for _, loc := range offsets {
+		svc := app.NewService(memory.NewInMemoryBookingRepo(), app.WithLocation(msk))
+		_, err := svc.CreateBooking(ctx, app.CreateBookingInput{
+			Start: start.Add(-time.Hour).In(loc), End: start.Add(-30 * time.Minute).In(loc),
+			Room: domain.Room21, Title: "Успели", TelegramID: "a",
+		})
+		if err != nil {
+			t.Fatalf("%s: ожидали nil, получили %v", loc, err)
+		}
+	}
+}
+
+func TestService_Availability_DayInDormLocation(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo, app.WithLocation(msk))
+
+	// понедельник по Москве, комната 132 работает 06:00-22:00
+	day := time.Date(2099, 1, 5, 0, 0, 0, 0, msk)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(10), End: at(12), Room: domain.Room132, TelegramID: "a"})
+
+	want := []app.Slot{{Start: at(6), End: at(10)}, {Start: at(12), End: at(22)}}
+	for _, loc := range offsets {
+		// от дня берётся только дата, в каком бы поясе он ни пришёл
+		d := time.Date(2099, 1, 5, 12, 0, 0, 0, loc)
+		slots, err := svc.FreeSlots(ctx, domain.Room132, d)
+		if err != nil {
+			t.Fatalf("%s: ожидали nil, получили %v", loc, err)
+		}
+		if len(slots) != len(want) {
+			t.Fatalf("%s: ожидали %v, получили %v", loc, want, slots)
+		}
+		for i := range want {
+			if !slots[i].Start.Equal(want[i].Start) || !slots[i].End.Equal(want[i].End) {
+				t.Fatalf("%s: ожидали %v, получили %v", loc, want, slots)
+			}
+		}
+	}
+}
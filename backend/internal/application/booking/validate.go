@@ -0,0 +1,147 @@
+package booking
+
+// В этом файле пробная проверка брони: все нарушенные правила сразу и ближайшее подходящее время.
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// Violation - одно нарушенное правило.
+type Violation struct {
+	Code       apperror.Code  `json:"code"`           // стабильный код ошибки, например PRIVATE_DAILY_LIMIT
+	Rule       string         `json:"rule,omitempty"` // имя правила политики, если нарушена она
+	Message    string         `json:"message"`
+	Params     map[string]any `json:"params,omitempty"`
+	Suggestion *Slot          `json:"suggestion,omitempty"` // ближайшее время той же длительности, где правил не нарушить
+}
+
+// сколько дней вперёд искать подсказку
+const suggestionDays = 7
+
+// CheckBooking проверяет бронь по всем правилам, ничего не сохраняя.
+// Пустой список - бронь можно создавать (если за это время её никто не опередит).
+func (s *Service) CheckBooking(ctx context.Context, in CreateBookingInput) ([]Violation, error) {
+	b := domain.Booking{
+		Start:       in.Start,
+		End:         in.End,
+		Room:        in.Room,
+		Title:       in.Title,
+		Description: in.Description,
+		TelegramID:  in.TelegramID,
+		IsPrivate:   in.IsPrivate,
+	}
+
+	room, err := s.bookableRoom(ctx, b.Room)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidRoom) {
+			return []Violation{toViolation(err)}, nil
+		}
+		return nil, err
+	}
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	errs, err := bookingViolations(ctx, s.repo, room, policy, s.loc, b, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkHold(ctx, b); err != nil {
+		if !errors.Is(err, domain.ErrSlotHeld) {
+			return nil, err
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) == 0 {
+		return nil, nil
+	}
+
+	suggestion, err := s.suggestSlot(ctx, b, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Violation, 0, len(errs))
+	for _, e := range errs {
+		v := toViolation(e)
+		v.Suggestion = suggestion
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func toViolation(err error) Violation {
+	v := Violation{Code: apperror.From(err).Code, Message: err.Error()}
+	var rv *RuleViolation
+	if errors.As(err, &rv) {
+		v.Rule = rv.Rule
+		v.Params = rv.Params
+	}
+	return v
+}
+
+// suggestSlot ищет ближайшее к b.Start время той же длительности (для ЧП - не длиннее лимита)
+// в той же комнате: сначала в день брони, потом в следующие дни. Бронь дальше горизонта
+// (Quota.MaxDaysAhead) подсказываем с последнего доступного дня назад. nil - ничего не нашлось.
+func (s *Service) suggestSlot(ctx context.Context, b domain.Booking, policy Policy) (*Slot, error) {
+	rules := policy.For(b.Room)
+	dur := b.End.Sub(b.Start)
+	if dur <= 0 {
+		return nil, nil
+	}
+	if maxDur := time.Duration(rules.MaxPrivateMinutes) * time.Minute; b.IsPrivate && maxDur > 0 && dur > maxDur {
+		dur = maxDur
+	}
+
+	// бронь в прошлом подсказываем с сегодняшнего дня
+	now := time.Now().In(s.loc)
+	from, step := b.Start.In(s.loc), 1
+	if from.Before(now) {
+		from = now
+	}
+	if until, ok := policy.Quota.bookableUntil(now); ok && from.After(until) {
+		from, step = until.In(s.loc), -1
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.loc)
+
+	for i := 0; i < suggestionDays; i++ {
+		day := from.AddDate(0, 0, i*step)
+		if day.Before(today) {
+			break
+		}
+		slots, err := s.Availability(ctx, AvailabilityQuery{Room: b.Room, Day: day, MinDuration: dur, Private: b.IsPrivate})
+		if err != nil {
+			return nil, err
+		}
+
+		var best *Slot
+		var bestDist time.Duration
+		for _, slot := range slots {
+			start := b.Start
+			if start.Before(slot.Start) {
+				start = slot.Start
+			}
+			if start.After(slot.LatestStart) {
+				start = slot.LatestStart
+			}
+			dist := start.Sub(b.Start)
+			if dist < 0 {
+				dist = -dist
+			}
+			if best == nil || dist < bestDist {
+				best = &Slot{Start: start, End: start.Add(dur), LatestStart: start, MaxMinutes: slot.MaxMinutes}
+				bestDist = dist
+			}
+		}
+		if best != nil {
+			return best, nil
+		}
+	}
+	return nil, nil
+}
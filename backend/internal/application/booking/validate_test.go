@@ -0,0 +1,93 @@
+package booking_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func TestService_CheckBooking_AllViolations(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo)
+
+	// пятница, комната 21; вечерняя ЧП уже есть
+	day := time.Date(2099, 1, 2, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(18), End: at(20), Room: domain.Room21, TelegramID: "a", IsPrivate: true})
+
+	in := app.CreateBookingInput{Start: at(20), End: at(26), Room: domain.Room21, Title: "ЧП", TelegramID: "b", IsPrivate: true}
+	violations, err := svc.CheckBooking(ctx, in)
+	if err != nil {
+		t.Fatalf("ожидали nil, получили %v", err)
+	}
+
+	want := []struct {
+		code apperror.Code
+		rule string
+	}{
+		{apperror.CodeTooLongDuration, app.RulePrivateMaxDuration},
+		{apperror.CodeInvalidTime, app.RuleRoomSchedule},
+		{apperror.CodeInvalidTime, app.RulePrivateQuietNight},
+		{apperror.CodePrivateEveningLimit, app.RulePrivateEvening},
+	}
+	if len(violations) != len(want) {
+		t.Fatalf("ожидали %v, получили %+v", want, violations)
+	}
+	for i, w := range want {
+		if violations[i].Code != w.code || violations[i].Rule != w.rule || violations[i].Message == "" {
+			t.Fatalf("нарушение %d: ожидали %s/%s, получили %+v", i, w.code, w.rule, violations[i])
+		}
+	}
+	if violations[0].Params["maxMinutes"] != 180 {
+		t.Fatalf("ожидали параметры правила, получили %+v", violations[0].Params)
+	}
+
+	// подсказка проходит настоящее создание
+	s := violations[0].Suggestion
+	if s == nil || s.End.Sub(s.Start) != 3*time.Hour {
+		t.Fatalf("ожидали подсказку на 3 часа, получили %+v", s)
+	}
+	in.Start, in.End = s.Start, s.End
+	if _, err := svc.CreateBooking(ctx, in); err != nil {
+		t.Fatalf("подсказка не прошла CreateBooking: %v", err)
+	}
+
+	// ничего не сохраняется, и корректная бронь проходит без нарушений
+	violations, err = svc.CheckBooking(ctx, app.CreateBookingInput{Start: at(8), End: at(9), Room: domain.Room132, TelegramID: "c"})
+	if err != nil || len(violations) != 0 {
+		t.Fatalf("ожидали пустой список, получили %+v, %v", violations, err)
+	}
+	if list, _ := repo.Find(ctx, domain.ListFilter{Room: domain.Room132}); len(list) != 0 {
+		t.Fatalf("CheckBooking не должен ничего сохранять")
+	}
+}
+
+func TestService_CheckBooking_Overlap(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewInMemoryBookingRepo()
+	svc := app.NewService(repo)
+
+	day := time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC)
+	at := func(h int) time.Time { return day.Add(time.Duration(h) * time.Hour) }
+	repo.Create(ctx, domain.Booking{Start: at(10), End: at(12), Room: domain.Room132, TelegramID: "a"})
+
+	violations, err := svc.CheckBooking(ctx, app.CreateBookingInput{Start: at(11), End: at(13), Room: domain.Room132, TelegramID: "b"})
+	if err != nil || len(violations) != 1 || violations[0].Code != apperror.CodeOverlap {
+		t.Fatalf("ожидали пересечение, получили %+v, %v", violations, err)
+	}
+	// ближайшее свободное время той же длительности - сразу после чужой брони
+	if s := violations[0].Suggestion; s == nil || !s.Start.Equal(at(12)) || !s.End.Equal(at(14)) {
+		t.Fatalf("ожидали подсказку 12:00-14:00, получили %+v", s)
+	}
+
+	violations, _ = svc.CheckBooking(ctx, app.CreateBookingInput{Start: at(11), End: at(13), Room: 999})
+	if len(violations) != 1 || violations[0].Code != apperror.CodeInvalidRoom {
+		t.Fatalf("ожидали неизвестную комнату, получили %+v", violations)
+	}
+}
@@ -0,0 +1,292 @@
+package booking
+
+// В этом файле очередь ожидания: заявки на занятое время и закрепление освободившегося
+// слота за первым в очереди, чья бронь теперь проходит все правила.
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// DefaultWaitlistHold - сколько освободившийся слот ждёт подтверждения.
+const DefaultWaitlistHold = 30 * time.Minute
+
+var errWaitlistNotConfigured = errors.New("очередь ожидания не настроена")
+
+// WithWaitlist подключает очередь ожидания. hold - сколько держать слот за заявителем;
+// ноль - DefaultWaitlistHold.
+func WithWaitlist(waitlist domain.WaitlistRepository, hold time.Duration) Option {
+	return func(s *Service) {
+		if hold <= 0 {
+			hold = DefaultWaitlistHold
+		}
+		s.waitlist = waitlist
+		s.hold = hold
+	}
+}
+
+// JoinWaitlist ставит в очередь заявку на время, которое сейчас занято.
+// Если время свободно - ErrSlotFree; если мешает не занятость, а другое правило - это правило.
+func (s *Service) JoinWaitlist(ctx context.Context, in CreateBookingInput) (domain.WaitlistEntry, error) {
+	if s.waitlist == nil {
+		return domain.WaitlistEntry{}, errWaitlistNotConfigured
+	}
+	e := domain.WaitlistEntry{
+		Start:       in.Start,
+		End:         in.End,
+		Room:        in.Room,
+		Title:       in.Title,
+		Description: in.Description,
+		TelegramID:  in.TelegramID,
+		IsPrivate:   in.IsPrivate,
+	}
+
+	room, err := s.bookableRoom(ctx, e.Room)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	policy, err := s.policies.Current(ctx)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+
+	b := e.Booking()
+	violations, err := bookingViolations(ctx, s.repo, room, policy, s.loc, b, nil)
+	if err != nil {
+		return domain.WaitlistEntry{}, err
+	}
+	busy := false
+	for _, v := range violations {
+		if !errors.Is(v, domain.ErrOverlap) {
+			return domain.WaitlistEntry{}, v
+		}
+		busy = true
+	}
+	if !busy {
+		// время может быть не занято, а закреплено за кем-то из очереди
+		err := s.checkHold(ctx, b)
+		if err == nil {
+			return domain.WaitlistEntry{}, domain.ErrSlotFree
+		}
+		if !errors.Is(err, domain.ErrSlotHeld) {
+			return domain.WaitlistEntry{}, err
+		}
+	}
+
+	return s.waitlist.Add(ctx, e)
+}
+
+// MyWaitlist - живые (ждущие и предложенные) заявки пользователя.
+func (s *Service) MyWaitlist(ctx context.Context, owner string) ([]domain.WaitlistEntry, error) {
+	if s.waitlist == nil {
+		return nil, nil
+	}
+	return s.waitlist.Find(ctx, domain.WaitlistFilter{
+		Owner:    owner,
+		Statuses: []domain.WaitlistStatus{domain.WaitlistWaiting, domain.WaitlistOffered},
+	})
+}
+
+// LeaveWaitlist снимает заявку. Если за ней держался слот, он уходит следующему.
+func (s *Service) LeaveWaitlist(ctx context.Context, id string, requesterID string, isAdmin bool) error {
+	if s.waitlist == nil {
+		return errWaitlistNotConfigured
+	}
+	e, err := s.waitlist.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !isAdmin && e.TelegramID != requesterID {
+		return domain.ErrForbidden
+	}
+	if e.Status != domain.WaitlistWaiting && e.Status != domain.WaitlistOffered {
+		return nil
+	}
+
+	held := e.Holds(time.Now())
+	e.Status = domain.WaitlistWithdrawn
+	e.HoldUntil = nil
+	if _, err := s.waitlist.Update(ctx, e); err != nil {
+		return err
+	}
+	if held {
+		s.offerFreed(ctx, e.Room, e.Start, e.End)
+	}
+	return nil
+}
+
+// ConfirmHold превращает закреплённый за пользователем слот в бронь.
+func (s *Service) ConfirmHold(ctx context.Context, id string, requesterID string) (domain.Booking, error) {
+	if s.waitlist == nil {
+		return domain.Booking{}, errWaitlistNotConfigured
+	}
+	e, err := s.waitlist.Get(ctx, id)
+	if err != nil {
+		return domain.Booking{}, err
+	}
+	if e.TelegramID != requesterID {
+		return domain.Booking{}, domain.ErrForbidden
+	}
+	if !e.Holds(time.Now()) {
+		return domain.Booking{}, domain.ErrNoHold
+	}
+
+	created, err := s.createBooking(ctx, e.Booking())
+	if err != nil {
+		return domain.Booking{}, err
+	}
+
+	e.Status = domain.WaitlistConfirmed
+	e.BookingID = created.ID
+	if _, err := s.waitlist.Update(ctx, e); err != nil {
+		log.Printf("waitlist: подтверждение %s: %v", e.ID, err)
+	}
+	return created, nil
+}
+
+// ExpireHolds закрывает просроченные предложения и передаёт слот дальше по очереди,
+// а заявки на уже наступившее время снимает.
+func (s *Service) ExpireHolds(ctx context.Context) error {
+	if s.waitlist == nil {
+		return nil
+	}
+	now := time.Now()
+	live, err := s.waitlist.Find(ctx, domain.WaitlistFilter{
+		Statuses: []domain.WaitlistStatus{domain.WaitlistWaiting, domain.WaitlistOffered},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range live {
+		lapsed := e.Status == domain.WaitlistOffered && !e.Holds(now)
+		if !lapsed && e.Start.After(now) {
+			continue
+		}
+		e.Status = domain.WaitlistExpired
+		if _, err := s.waitlist.Update(ctx, e); err != nil {
+			return err
+		}
+		if lapsed && e.Start.After(now) {
+			s.offerFreed(ctx, e.Room, e.Start, e.End)
+		}
+	}
+	return nil
+}
+
+// RunWaitlist раз в every закрывает просроченные предложения, пока не отменят ctx.
+func (s *Service) RunWaitlist(ctx context.Context, every time.Duration) {
+	if s.waitlist == nil {
+		return
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.ExpireHolds(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("waitlist: %v", err)
+		}
+	}
+}
+
+// offerFreed вызывается, когда в комнате освободилось [from, to): каждая ждущая заявка
+// на это время, чья бронь теперь проходит все правила, получает слот на s.hold.
+// Заявки смотрятся по порядку очереди, уже закреплённые слоты мешают следующим.
+// Сбои только логируются: освобождение брони уже состоялось.
+func (s *Service) offerFreed(ctx context.Context, number domain.Room, from, to time.Time) {
+	if s.waitlist == nil {
+		return
+	}
+
+	var offered []domain.WaitlistEntry
+	err := s.repo.WithRoomLock(ctx, []domain.Room{number}, nil, func(repo domain.Repository) error {
+		waiting, err := s.waitlist.Find(ctx, domain.WaitlistFilter{
+			Room:     number,
+			From:     from,
+			To:       to,
+			Statuses: []domain.WaitlistStatus{domain.WaitlistWaiting},
+		})
+		if err != nil || len(waiting) == 0 {
+			return err
+		}
+		room, err := s.bookableRoom(ctx, number)
+		if err != nil {
+			return err
+		}
+		policy, err := s.policies.Current(ctx)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, e := range waiting {
+			b := e.Booking()
+			violations, err := bookingViolations(ctx, repo, room, policy, s.loc, b, nil)
+			if err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				continue
+			}
+			if err := s.checkHold(ctx, b); err != nil {
+				if errors.Is(err, domain.ErrSlotHeld) {
+					continue
+				}
+				return err
+			}
+
+			until := now.Add(s.hold)
+			if e.Start.Before(until) {
+				until = e.Start
+			}
+			e.Status = domain.WaitlistOffered
+			e.HoldUntil = &until
+			if _, err := s.waitlist.Update(ctx, e); err != nil {
+				return err
+			}
+			offered = append(offered, e)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("waitlist: комната %d: %v", number, err)
+	}
+
+	if s.notifier != nil {
+		for _, e := range offered {
+			s.notifier.WaitlistOffer(ctx, e)
+		}
+	}
+}
+
+// checkHold - ErrSlotHeld, если время b держит за собой чужая заявка из очереди.
+func (s *Service) checkHold(ctx context.Context, b domain.Booking) error {
+	if s.waitlist == nil {
+		return nil
+	}
+	holds, err := s.waitlist.Find(ctx, domain.WaitlistFilter{
+		Room:     b.Room,
+		From:     b.Start,
+		To:       b.End,
+		Statuses: []domain.WaitlistStatus{domain.WaitlistOffered},
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, h := range holds {
+		if h.Holds(now) && h.TelegramID != b.TelegramID {
+			return domain.ErrSlotHeld
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package booking_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	app "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+type offerRecorder struct {
+	offers []domain.WaitlistEntry
+}
+
+func (n *offerRecorder) BookingCancelled(ctx context.Context, b domain.Booking) {}
+
+func (n *offerRecorder) WaitlistOffer(ctx context.Context, e domain.WaitlistEntry) {
+	n.offers = append(n.offers, e)
+}
+
+func waitlistInput(owner string) app.CreateBookingInput {
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	return app.CreateBookingInput{Start: start, End: start.Add(time.Hour), Room: domain.Room132, Title: "Кино", TelegramID: owner}
+}
+
+func TestService_Waitlist(t *testing.T) {
+	ctx := context.Background()
+	notifier := &offerRecorder{}
+	svc := app.NewService(memory.NewInMemoryBookingRepo(),
+		app.WithWaitlist(memory.NewInMemoryWaitlistRepo(), time.Hour),
+		app.WithNotifier(notifier),
+	)
+
+	if _, err := svc.JoinWaitlist(ctx, waitlistInput("first")); !errors.Is(err, domain.ErrSlotFree) {
+		t.Fatalf("в очередь на свободное время: ожидали ErrSlotFree, получили %v", err)
+	}
+
+	blocking, err := svc.CreateBooking(ctx, waitlistInput("owner"))
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	first, err := svc.JoinWaitlist(ctx, waitlistInput("first"))
+	if err != nil || first.Status != domain.WaitlistWaiting {
+		t.Fatalf("JoinWaitlist: %+v, %v", first, err)
+	}
+	second, _ := svc.JoinWaitlist(ctx, waitlistInput("second"))
+
+	if err := svc.CancelBooking(ctx, blocking.ID, "owner", false, ""); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+	if len(notifier.offers) != 1 || notifier.offers[0].ID != first.ID || notifier.offers[0].HoldUntil == nil {
+		t.Fatalf("слот должен достаться первому в очереди, предложения: %+v", notifier.offers)
+	}
+
+	// пока слот держится, его не может занять никто другой
+	if _, err := svc.CreateBooking(ctx, waitlistInput("stranger")); !errors.Is(err, domain.ErrSlotHeld) {
+		t.Fatalf("ожидали ErrSlotHeld, получили %v", err)
+	}
+	if _, err := svc.ConfirmHold(ctx, second.ID, "second"); !errors.Is(err, domain.ErrNoHold) {
+		t.Fatalf("второму слот не предлагали: ожидали ErrNoHold, получили %v", err)
+	}
+
+	// первый отказался - слот уходит второму
+	if err := svc.LeaveWaitlist(ctx, first.ID, "first", false); err != nil {
+		t.Fatalf("LeaveWaitlist: %v", err)
+	}
+	if len(notifier.offers) != 2 || notifier.offers[1].ID != second.ID {
+		t.Fatalf("слот должен перейти второму, предложения: %+v", notifier.offers)
+	}
+
+	b, err := svc.ConfirmHold(ctx, second.ID, "second")
+	if err != nil || b.TelegramID != "second" {
+		t.Fatalf("ConfirmHold: %+v, %v", b, err)
+	}
+	if list, _ := svc.MyWaitlist(ctx, "second"); len(list) != 0 {
+		t.Fatalf("подтверждённая заявка не должна висеть в очереди: %+v", list)
+	}
+}
+
+func TestService_Waitlist_ExpiredHoldMovesOn(t *testing.T) {
+	ctx := context.Background()
+	notifier := &offerRecorder{}
+	svc := app.NewService(memory.NewInMemoryBookingRepo(),
+		app.WithWaitlist(memory.NewInMemoryWaitlistRepo(), time.Millisecond),
+		app.WithNotifier(notifier),
+	)
+
+	blocking, _ := svc.CreateBooking(ctx, waitlistInput("owner"))
+	first, _ := svc.JoinWaitlist(ctx, waitlistInput("first"))
+	second, _ := svc.JoinWaitlist(ctx, waitlistInput("second"))
+	svc.CancelBooking(ctx, blocking.ID, "owner", false, "")
+
+	time.Sleep(5 * time.Millisecond)
+	if err := svc.ExpireHolds(ctx); err != nil {
+		t.Fatalf("ExpireHolds: %v", err)
+	}
+
+	if len(notifier.offers) != 2 || notifier.offers[0].ID != first.ID || notifier.offers[1].ID != second.ID {
+		t.Fatalf("после просрочки слот должен перейти второму, предложения: %+v", notifier.offers)
+	}
+	if _, err := svc.ConfirmHold(ctx, first.ID, "first"); !errors.Is(err, domain.ErrNoHold) {
+		t.Fatalf("просроченное предложение: ожидали ErrNoHold, получили %v", err)
+	}
+}
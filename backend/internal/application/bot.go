@@ -0,0 +1,35 @@
+package app
+
+// В этом файле точка запуска телеграм-бота. Бот работает отдельным процессом
+// с той же БД, что и HTTP-сервер (в in-memory режиме данные у них, конечно, разные).
+
+import (
+	"Dormitory_Booking/internal/infrastructure/telegram"
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+)
+
+// RunBot поднимает сервисы и опрашивает Bot API, пока не отменят ctx.
+func RunBot(ctx context.Context) error {
+	_ = godotenv.Load()
+
+	token := os.Getenv("TELEGRAM_BOT_TOKEN")
+	if token == "" {
+		return errors.New("TELEGRAM_BOT_TOKEN не задан")
+	}
+	c, err := build(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	client := telegram.NewClient(os.Getenv("TELEGRAM_API_URL"), token)
+	bot := telegram.NewBot(client, c.bookings, c.auth, c.users, c.botLoc)
+
+	log.Println("telegram bot started")
+	return bot.Run(ctx)
+}
@@ -0,0 +1,327 @@
+package app
+
+// В этом файле сборка зависимостей: репозитории (Postgres или память) и сервисы.
+// Общая для HTTP-сервера и телеграм-бота.
+
+import (
+	appauth "Dormitory_Booking/internal/application/auth"
+	appbooking "Dormitory_Booking/internal/application/booking"
+	appdorm "Dormitory_Booking/internal/application/dormitory"
+	appnotify "Dormitory_Booking/internal/application/notify"
+	domainaudit "Dormitory_Booking/internal/domain/audit"
+	domainbooking "Dormitory_Booking/internal/domain/booking"
+	domaindorm "Dormitory_Booking/internal/domain/dormitory"
+	domainnotify "Dormitory_Booking/internal/domain/notification"
+	domainuser "Dormitory_Booking/internal/domain/user"
+	"Dormitory_Booking/internal/infrastructure/mail"
+	"Dormitory_Booking/internal/infrastructure/memory"
+	infranotify "Dormitory_Booking/internal/infrastructure/notify"
+	"Dormitory_Booking/internal/infrastructure/policyfile"
+	pgrepo "Dormitory_Booking/internal/infrastructure/postgres"
+	"Dormitory_Booking/internal/infrastructure/telegram"
+	"context"
+	"crypto/rand"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type components struct {
+	bookings *appbooking.Service // общежитие бота (BOT_DORMITORY, по умолчанию main)
+	dorms    *appdorm.Service
+	auth     *appauth.Service
+	notify   *appnotify.Service
+	users    domainuser.Repository
+	events   domainbooking.EventBus
+	listen   func(ctx context.Context) // приём событий из Postgres; nil в in-memory режиме
+	loc      *time.Location            // пояс по умолчанию: уведомления и общежития без своего пояса
+	botLoc   *time.Location            // пояс общежития бота: в нём бот разбирает и пишет время
+	pool     *pgxpool.Pool             // nil в in-memory режиме
+}
+
+func (c *components) close() {
+	if c.pool != nil {
+		c.pool.Close()
+	}
+}
+
+// tenantRepos - хранилища одного общежития.
+type tenantRepos struct {
+	bookings domainbooking.Repository
+	series   domainbooking.SeriesRepository
+	rooms    domainbooking.RoomRepository
+	policies appbooking.PolicyStore
+	audit    domainaudit.Repository
+	waitlist domainbooking.WaitlistRepository
+	noShows  domainbooking.NoShowRepository
+	changes  domainbooking.ChangeLog
+}
+
+// build собирает репозитории и сервисы по переменным окружения.
+func build(ctx context.Context) (*components, error) {
+	dbURL := os.Getenv("DB_URL") // если пусто — работаем в in-memory режиме
+
+	var allBookings domainbooking.Repository // брони всех общежитий сразу - для напоминаний
+	var reposFor func(dorm string) tenantRepos
+	var dormRepo domaindorm.Repository
+	var users domainuser.Repository
+	var loginCodes domainuser.LoginCodeRepository
+	var sessions domainuser.SessionRepository
+	var links domainuser.TelegramLinkRepository
+	var outbox domainnotify.Outbox
+	var prefs domainnotify.PreferencesRepository
+	var events domainbooking.EventBus
+	var listen func(ctx context.Context)
+	var pool *pgxpool.Pool
+	var err error
+
+	if dbURL != "" {
+		log.Printf("using Postgres repo: %s\n", dbURL)
+		pool, err = pgxpool.New(ctx, dbURL)
+		if err != nil {
+			return nil, err
+		}
+		if err := migrateOnStart(ctx, pool); err != nil {
+			pool.Close()
+			return nil, err
+		}
+		bookings := pgrepo.NewBookingPostgresRepo(pool)
+		allBookings = bookings.ForDormitory("")
+		reposFor = func(dorm string) tenantRepos {
+			scoped := bookings.ForDormitory(dorm)
+			return tenantRepos{
+				bookings: scoped,
+				changes:  scoped,
+				series:   pgrepo.NewSeriesPostgresRepo(pool).ForDormitory(dorm),
+				rooms:    pgrepo.NewRoomPostgresRepo(pool).ForDormitory(dorm),
+				policies: pgrepo.NewPolicyPostgresRepo(pool).ForDormitory(dorm),
+				audit:    pgrepo.NewAuditPostgresRepo(pool).ForDormitory(dorm),
+				waitlist: pgrepo.NewWaitlistPostgresRepo(pool).ForDormitory(dorm),
+				noShows:  pgrepo.NewNoShowPostgresRepo(pool).ForDormitory(dorm),
+			}
+		}
+		dormRepo = pgrepo.NewDormitoryPostgresRepo(pool)
+		users = pgrepo.NewUserPostgresRepo(pool)
+		loginCodes = pgrepo.NewLoginCodePostgresRepo(pool)
+		sessions = pgrepo.NewSessionPostgresRepo(pool)
+		links = pgrepo.NewTelegramLinkPostgresRepo(pool)
+		outbox = pgrepo.NewOutboxPostgresRepo(pool)
+		prefs = pgrepo.NewPreferencesPostgresRepo(pool)
+		bus := pgrepo.NewEventPostgresBus(pool, memory.NewEventBroker())
+		events, listen = bus, bus.Listen
+	} else {
+		log.Println("DB_URL не задан, используем in-memory репозиторий (dev mode)")
+		tenants := newMemoryTenants()
+		allBookings = tenants.bookings.ForDormitory("")
+		reposFor = tenants.get
+		dormRepo = memory.NewInMemoryDormitoryRepo(domaindorm.Dormitory{
+			ID:   domaindorm.DefaultID,
+			Name: getEnv("DORM_NAME", "Общежитие"),
+		})
+		users = memory.NewInMemoryUserRepo()
+		loginCodes = memory.NewInMemoryLoginCodeRepo()
+		sessions = memory.NewInMemorySessionRepo()
+		links = memory.NewInMemoryTelegramLinkRepo()
+		outbox = memory.NewInMemoryOutbox()
+		prefs = memory.NewInMemoryPreferencesRepo()
+		events = memory.NewEventBroker()
+	}
+
+	// файл с политикой важнее БД: его удобно держать в репозитории студсовета.
+	// Он один на все общежития.
+	var filePolicies appbooking.PolicyStore
+	if path := os.Getenv("POLICY_FILE"); path != "" {
+		log.Printf("политика бронирования из файла %s\n", path)
+		filePolicies = policyfile.NewStore(path)
+	}
+
+	loc := dormLocation()
+	mailer := newMailer()
+	notifier := appnotify.NewService(outbox, prefs, users, allBookings, newSenders(mailer), appnotify.Config{
+		Interval: 30 * time.Second,
+		Location: loc,
+		Zone: func(ctx context.Context, id string) *time.Location {
+			d, err := dormRepo.Get(ctx, id)
+			if err != nil {
+				return nil
+			}
+			return d.Location(loc)
+		},
+	})
+
+	hold := waitlistHold()
+	dorms := appdorm.NewService(dormRepo, func(d domaindorm.Dormitory) *appbooking.Service {
+		t := reposFor(d.ID)
+		if filePolicies != nil {
+			t.policies = filePolicies
+		}
+		return appbooking.NewService(t.bookings,
+			appbooking.WithSeriesRepo(t.series),
+			appbooking.WithRoomRepo(t.rooms),
+			appbooking.WithPolicyStore(t.policies),
+			appbooking.WithNotifier(notifier.In(d.Location(loc))),
+			appbooking.WithAuditLog(t.audit),
+			appbooking.WithWaitlist(t.waitlist, hold),
+			appbooking.WithNoShowRepo(t.noShows),
+			appbooking.WithLocation(d.Location(loc)),
+			appbooking.WithEvents(events),
+			appbooking.WithChangeLog(t.changes),
+		)
+	})
+
+	svc, botDorm, err := dorms.Bookings(ctx, getEnv("BOT_DORMITORY", domaindorm.DefaultID))
+	if err != nil {
+		if pool != nil {
+			pool.Close()
+		}
+		return nil, err
+	}
+
+	secret, err := sessionSecret()
+	if err != nil {
+		if pool != nil {
+			pool.Close()
+		}
+		return nil, err
+	}
+	auth := appauth.NewService(users, loginCodes, sessions, links, mailer, appauth.Config{
+		EmailDomain:   getEnv("AUTH_EMAIL_DOMAIN", "edu.hse.ru"),
+		SessionSecret: secret,
+		VerifyURL:     os.Getenv("AUTH_VERIFY_URL"),
+		Admins:        splitList(os.Getenv("ADMINS")),
+		TelegramBot:   strings.TrimPrefix(os.Getenv("TELEGRAM_BOT_NAME"), "@"),
+	})
+
+	return &components{bookings: svc, dorms: dorms, auth: auth, notify: notifier, users: users, events: events, listen: listen, loc: loc, botLoc: botDorm.Location(loc), pool: pool}, nil
+}
+
+// memoryTenants - in-memory хранилища общежитий. Брони лежат в одном хранилище
+// (напоминаниям нужны все сразу), остальное у каждого общежития своё. Хранилища
+// заводятся при первом обращении и живут до конца процесса.
+type memoryTenants struct {
+	bookings *memory.InMemoryBookingRepo
+
+	mu    sync.Mutex
+	repos map[string]tenantRepos
+}
+
+func newMemoryTenants() *memoryTenants {
+	return &memoryTenants{
+		bookings: memory.NewInMemoryBookingRepo(),
+		repos:    make(map[string]tenantRepos),
+	}
+}
+
+func (m *memoryTenants) get(dorm string) tenantRepos {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.repos[dorm]
+	if !ok {
+		// комнаты по умолчанию есть только у первого общежития, как и после миграции в Postgres
+		var seed []domainbooking.RoomInfo
+		if dorm == domaindorm.DefaultID {
+			seed = domainbooking.DefaultRooms()
+		}
+		scoped := m.bookings.ForDormitory(dorm)
+		t = tenantRepos{
+			bookings: scoped,
+			changes:  scoped,
+			series:   memory.NewInMemorySeriesRepo(),
+			rooms:    memory.NewInMemoryRoomRepo(seed...),
+			policies: appbooking.NewStaticPolicyStore(),
+			audit:    memory.NewInMemoryAuditLog(),
+			waitlist: memory.NewInMemoryWaitlistRepo(),
+			noShows:  memory.NewInMemoryNoShowRepo(),
+		}
+		m.repos[dorm] = t
+	}
+	return t
+}
+
+// newSenders - каналы уведомлений. Telegram - только если задан токен бота.
+func newSenders(mailer appauth.Mailer) map[domainnotify.Channel]appnotify.Sender {
+	senders := map[domainnotify.Channel]appnotify.Sender{
+		domainnotify.ChannelEmail:   infranotify.NewEmailSender(mailer),
+		domainnotify.ChannelWebhook: infranotify.NewWebhookSender(),
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		client := telegram.NewClient(os.Getenv("TELEGRAM_API_URL"), token)
+		senders[domainnotify.ChannelTelegram] = infranotify.NewTelegramSender(client)
+	}
+	return senders
+}
+
+// waitlistHold - сколько освободившийся слот ждёт подтверждения (WAITLIST_HOLD, например 30m).
+func waitlistHold() time.Duration {
+	v := os.Getenv("WAITLIST_HOLD")
+	if v == "" {
+		return appbooking.DefaultWaitlistHold
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("WAITLIST_HOLD: некорректное значение %q, используем %s", v, appbooking.DefaultWaitlistHold)
+		return appbooking.DefaultWaitlistHold
+	}
+	return d
+}
+
+// tombstoneRetention - сколько отдавать отменённые брони в журнале изменений (TOMBSTONE_RETENTION, например 720h).
+func tombstoneRetention() time.Duration {
+	v := os.Getenv("TOMBSTONE_RETENTION")
+	if v == "" {
+		return appbooking.DefaultTombstoneRetention
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("TOMBSTONE_RETENTION: некорректное значение %q, используем %s", v, appbooking.DefaultTombstoneRetention)
+		return appbooking.DefaultTombstoneRetention
+	}
+	return d
+}
+
+// dormLocation - часовой пояс по умолчанию (DORM_TIMEZONE). В нём считаются часы работы комнат,
+// тихие ночи и лимиты общежитий без своего пояса, и в нём же бот и уведомления показывают время.
+// Старое имя BOT_TIMEZONE по-прежнему понимается.
+func dormLocation() *time.Location {
+	name := getEnv("DORM_TIMEZONE", getEnv("BOT_TIMEZONE", "Europe/Moscow"))
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		log.Printf("DORM_TIMEZONE: %v, используем UTC", err)
+		return time.UTC
+	}
+	return loc
+}
+
+// newMailer выбирает SMTP, если задан SMTP_HOST, иначе письма пишутся в лог (или в MAIL_FILE).
+func newMailer() appauth.Mailer {
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     host,
+			Port:     os.Getenv("SMTP_PORT"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", os.Getenv("SMTP_USERNAME")),
+		})
+	}
+	log.Println("SMTP_HOST не задан, письма со входом пишутся в лог (dev mode)")
+	return mail.NewLogMailer(os.Getenv("MAIL_FILE"))
+}
+
+// sessionSecret - ключ подписи сессий. Без SESSION_SECRET генерируем случайный,
+// тогда после перезапуска всем придётся войти заново.
+func sessionSecret() ([]byte, error) {
+	if s := os.Getenv("SESSION_SECRET"); s != "" {
+		return []byte(s), nil
+	}
+	log.Println("SESSION_SECRET не задан, сессии не переживут перезапуск")
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, err
+	}
+	return secret, nil
+}
@@ -0,0 +1,168 @@
+package dormitory
+
+// В этом файле сервис общежитий: каталог и по сервису бронирования на каждое общежитие.
+// Сервис бронирования общежития работает только со своими комнатами, бронями, политикой
+// и журналом, так что правила одного общежития другое не видит.
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/dormitory"
+)
+
+// Factory собирает сервис бронирования поверх хранилищ общежития d.
+type Factory func(d domain.Dormitory) *appbooking.Service
+
+type Service struct {
+	repo    domain.Repository
+	factory Factory
+
+	mu       sync.Mutex
+	bookings map[string]*appbooking.Service
+}
+
+func NewService(repo domain.Repository, factory Factory) *Service {
+	return &Service{
+		repo:     repo,
+		factory:  factory,
+		bookings: make(map[string]*appbooking.Service),
+	}
+}
+
+func (s *Service) List(ctx context.Context) ([]domain.Dormitory, error) {
+	return s.repo.List(ctx)
+}
+
+func (s *Service) Get(ctx context.Context, id string) (domain.Dormitory, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// ByHost ищет общежитие по домену запроса; ok=false - домен ни за кем не закреплён.
+func (s *Service) ByHost(ctx context.Context, host string) (d domain.Dormitory, ok bool, err error) {
+	if domain.NormalizeHost(host) == "" {
+		return domain.Dormitory{}, false, nil
+	}
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return domain.Dormitory{}, false, err
+	}
+	for _, d := range list {
+		if d.ServesHost(host) {
+			return d, true, nil
+		}
+	}
+	return domain.Dormitory{}, false, nil
+}
+
+// Bookings возвращает общежитие и его сервис бронирования.
+func (s *Service) Bookings(ctx context.Context, id string) (*appbooking.Service, domain.Dormitory, error) {
+	d, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return nil, domain.Dormitory{}, err
+	}
+	return s.bookingsFor(d), d, nil
+}
+
+func (s *Service) bookingsFor(d domain.Dormitory) *appbooking.Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	svc, ok := s.bookings[d.ID]
+	if !ok {
+		svc = s.factory(d)
+		s.bookings[d.ID] = svc
+	}
+	return svc
+}
+
+// Create заводит общежитие. Комнат у него пока нет, их добавляет администратор.
+func (s *Service) Create(ctx context.Context, d domain.Dormitory) (domain.Dormitory, error) {
+	d = d.Normalize()
+	if err := d.Validate(); err != nil {
+		return domain.Dormitory{}, err
+	}
+	return s.repo.Create(ctx, d)
+}
+
+// Update меняет название, домены, пояс и администраторов общежития.
+func (s *Service) Update(ctx context.Context, d domain.Dormitory) (domain.Dormitory, error) {
+	d = d.Normalize()
+	if err := d.Validate(); err != nil {
+		return domain.Dormitory{}, err
+	}
+	d, err := s.repo.Update(ctx, d)
+	if err != nil {
+		return domain.Dormitory{}, err
+	}
+
+	// пояс мог поменяться: следующий запрос соберёт сервис заново
+	s.mu.Lock()
+	delete(s.bookings, d.ID)
+	s.mu.Unlock()
+	return d, nil
+}
+
+// ForEach вызывает fn для сервиса бронирования каждого общежития.
+// Ошибка одного общежития не мешает остальным, вернётся первая.
+func (s *Service) ForEach(ctx context.Context, fn func(d domain.Dormitory, svc *appbooking.Service) error) error {
+	list, err := s.repo.List(ctx)
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, d := range list {
+		if err := fn(d, s.bookingsFor(d)); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// RunWaitlist раз в every снимает просроченные удержания очереди во всех общежитиях.
+func (s *Service) RunWaitlist(ctx context.Context, every time.Duration) {
+	s.run(ctx, every, "waitlist", func(ctx context.Context, svc *appbooking.Service) error {
+		return svc.ExpireHolds(ctx)
+	})
+}
+
+// RunNoShows раз в every освобождает брони без отметки во всех общежитиях.
+func (s *Service) RunNoShows(ctx context.Context, every time.Duration) {
+	s.run(ctx, every, "no-show", func(ctx context.Context, svc *appbooking.Service) error {
+		return svc.ReleaseNoShows(ctx)
+	})
+}
+
+// RunTombstones раз в every убирает во всех общежитиях из журнала изменений брони,
+// отменённые больше retention назад. Сами брони остаются.
+func (s *Service) RunTombstones(ctx context.Context, every, retention time.Duration) {
+	s.run(ctx, every, "tombstones", func(ctx context.Context, svc *appbooking.Service) error {
+		_, err := svc.ExpireTombstones(ctx, retention)
+		return err
+	})
+}
+
+func (s *Service) run(ctx context.Context, every time.Duration, name string, fn func(ctx context.Context, svc *appbooking.Service) error) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := s.ForEach(ctx, func(d domain.Dormitory, svc *appbooking.Service) error {
+			if err := fn(ctx, svc); err != nil && ctx.Err() == nil {
+				log.Printf("%s: общежитие %s: %v", name, d.ID, err)
+			}
+			return nil
+		})
+		if err != nil && ctx.Err() == nil {
+			log.Printf("%s: %v", name, err)
+		}
+	}
+}
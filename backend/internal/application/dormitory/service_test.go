@@ -0,0 +1,79 @@
+package dormitory_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	appdorm "Dormitory_Booking/internal/application/dormitory"
+	domain "Dormitory_Booking/internal/domain/dormitory"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+func newService(built *int) *appdorm.Service {
+	bookings := memory.NewInMemoryBookingRepo()
+	return appdorm.NewService(
+		memory.NewInMemoryDormitoryRepo(domain.Dormitory{ID: domain.DefaultID, Name: "Первое"}),
+		func(d domain.Dormitory) *appbooking.Service {
+			*built++
+			return appbooking.NewService(bookings.ForDormitory(d.ID))
+		},
+	)
+}
+
+func TestService_Create_Validates(t *testing.T) {
+	var built int
+	s := newService(&built)
+	ctx := context.Background()
+
+	for _, d := range []domain.Dormitory{
+		{ID: "West Wing", Name: "Западное"},
+		{ID: "west", Name: " "},
+		{ID: "west", Name: "Западное", Timezone: "Mars/Olympus"},
+	} {
+		if _, err := s.Create(ctx, d); !errors.Is(err, domain.ErrInvalid) {
+			t.Fatalf("%+v: ожидали ErrInvalid, получили %v", d, err)
+		}
+	}
+
+	d, err := s.Create(ctx, domain.Dormitory{ID: "west", Name: "Западное", Hosts: []string{"West.Example.com:443"}})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := s.Create(ctx, d); !errors.Is(err, domain.ErrExists) {
+		t.Fatalf("повторное создание: ожидали ErrExists, получили %v", err)
+	}
+
+	got, ok, err := s.ByHost(ctx, "west.example.com")
+	if err != nil || !ok || got.ID != "west" {
+		t.Fatalf("поиск по домену: %+v %v %v", got, ok, err)
+	}
+}
+
+func TestService_Bookings_CachedUntilUpdate(t *testing.T) {
+	var built int
+	s := newService(&built)
+	ctx := context.Background()
+
+	first, _, err := s.Bookings(ctx, domain.DefaultID)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	again, _, _ := s.Bookings(ctx, domain.DefaultID)
+	if first != again || built != 1 {
+		t.Fatalf("сервис общежития должен собираться один раз, собран %d раз", built)
+	}
+
+	if _, err := s.Update(ctx, domain.Dormitory{ID: domain.DefaultID, Name: "Первое", Timezone: "Asia/Yekaterinburg"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	svc, _, _ := s.Bookings(ctx, domain.DefaultID)
+	if svc == first || built != 2 {
+		t.Fatalf("после смены пояса сервис должен собраться заново")
+	}
+
+	if _, _, err := s.Bookings(ctx, "nowhere"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("неизвестное общежитие: ожидали ErrNotFound, получили %v", err)
+	}
+}
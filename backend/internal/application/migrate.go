@@ -0,0 +1,108 @@
+package app
+
+// В этом файле миграции схемы: автоматически при старте и подкоманда cmd/migrate.
+
+import (
+	"Dormitory_Booking/deploy/migrations"
+	pgrepo "Dormitory_Booking/internal/infrastructure/postgres"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+// migrateOnStart накатывает новые миграции, если не выключено через MIGRATE_ON_START=false.
+func migrateOnStart(ctx context.Context, pool *pgxpool.Pool) error {
+	if os.Getenv("MIGRATE_ON_START") == "false" {
+		return nil
+	}
+	m, err := pgrepo.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+	n, err := m.Up(ctx)
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("применено миграций: %d\n", n)
+	}
+	return nil
+}
+
+// RunMigrate - подкоманда migrate: up, down [шагов], status, baseline <версия>.
+func RunMigrate(ctx context.Context, args []string) error {
+	_ = godotenv.Load()
+
+	dbURL := os.Getenv("DB_URL")
+	if dbURL == "" {
+		return errors.New("DB_URL не задан")
+	}
+	if len(args) == 0 {
+		args = []string{"up"}
+	}
+
+	pool, err := pgxpool.New(ctx, dbURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	m, err := pgrepo.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "up":
+		n, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("применено миграций: %d\n", n)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("down: неверное число шагов %q", args[1])
+			}
+		}
+		n, err := m.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("откачено миграций: %d\n", n)
+	case "status":
+		list, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range list {
+			applied := "не применена"
+			if s.AppliedAt != nil {
+				applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%s\t%s\n", s.Migration, applied)
+		}
+	case "baseline":
+		if len(args) < 2 {
+			return errors.New("baseline: укажите последнюю применённую версию")
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("baseline: неверная версия %q", args[1])
+		}
+		if err := m.Baseline(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("миграции 1..%d отмечены применёнными\n", version)
+	default:
+		return fmt.Errorf("неизвестная команда %q, есть: up, down [шагов], status, baseline <версия>", args[0])
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package notify
+
+// В этом файле сервис уведомлений: ставит напоминания и извещения об отмене в outbox
+// и в фоне разносит их по каналам (Telegram, почта, вебхук).
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	domainbooking "Dormitory_Booking/internal/domain/booking"
+	domain "Dormitory_Booking/internal/domain/notification"
+	domainuser "Dormitory_Booking/internal/domain/user"
+)
+
+const (
+	maxAttempts = 5
+	claimLease  = time.Minute
+	claimBatch  = 50
+)
+
+// Sender доставляет сообщение по одному каналу.
+type Sender interface {
+	Send(ctx context.Context, m domain.Message) error
+}
+
+// Config - настройки планировщика.
+type Config struct {
+	Interval  time.Duration  // как часто просыпаться
+	Lookahead time.Duration  // на сколько вперёд ставить напоминания
+	Location  *time.Location // в каком поясе писать время в тексте, если у общежития нет своего
+	// Zone - пояс общежития брони для напоминаний; nil или nil в ответе - Location
+	Zone func(ctx context.Context, dormitory string) *time.Location
+}
+
+type Service struct {
+	outbox   domain.Outbox
+	prefs    domain.PreferencesRepository
+	users    domainuser.Repository
+	bookings domainbooking.Repository
+	senders  map[domain.Channel]Sender
+	cfg      Config
+	now      func() time.Time
+}
+
+func NewService(outbox domain.Outbox, prefs domain.PreferencesRepository, users domainuser.Repository,
+	bookings domainbooking.Repository, senders map[domain.Channel]Sender, cfg Config) *Service {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 30 * time.Second
+	}
+	if cfg.Lookahead <= 0 {
+		cfg.Lookahead = 24 * time.Hour
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	return &Service{
+		outbox:   outbox,
+		prefs:    prefs,
+		users:    users,
+		bookings: bookings,
+		senders:  senders,
+		cfg:      cfg,
+		now:      time.Now,
+	}
+}
+
+// In - тот же сервис, но время пишется в поясе loc. Его отдают сервису броней общежития:
+// в заявках очереди общежитие не указано, а извещения идут от имени конкретного общежития.
+func (s *Service) In(loc *time.Location) *Service {
+	c := *s
+	c.cfg.Location = loc
+	c.cfg.Zone = nil
+	return &c
+}
+
+// location - пояс, в котором писать время брони общежития dorm.
+func (s *Service) location(ctx context.Context, dorm string) *time.Location {
+	if s.cfg.Zone != nil && dorm != "" {
+		if loc := s.cfg.Zone(ctx, dorm); loc != nil {
+			return loc
+		}
+	}
+	return s.cfg.Location
+}
+
+// Run ставит напоминания и разносит сообщения раз в Interval, пока не отменят ctx.
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ScheduleReminders(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notify: напоминания: %v", err)
+		}
+		if err := s.Deliver(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("notify: доставка: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// BookingCancelled - админ удалил чужую бронь, сообщаем владельцу.
+func (s *Service) BookingCancelled(ctx context.Context, b domainbooking.Booking) {
+	u, prefs, err := s.owner(ctx, b.TelegramID)
+	if err != nil {
+		if !errors.Is(err, domainuser.ErrNotFound) {
+			log.Printf("notify: отмена %s: %v", b.ID, err)
+		}
+		return
+	}
+
+	msgs := s.messages(u, prefs, domain.Message{
+		DedupKey:  "cancelled:" + b.ID,
+		Kind:      domain.KindCancelled,
+		BookingID: b.ID,
+		Subject:   "Бронь отменена",
+		Body: fmt.Sprintf("Администратор отменил вашу бронь «%s» в комнате %d на %s.",
+			b.Title, b.Room, b.Start.In(s.location(ctx, b.Dormitory)).Format("02.01 15:04")),
+		SendAfter: s.now(),
+	})
+	if err := s.outbox.Enqueue(ctx, msgs...); err != nil {
+		log.Printf("notify: отмена %s: %v", b.ID, err)
+	}
+}
+
+// WaitlistOffer - время из заявки в очереди освободилось и ждёт подтверждения до e.HoldUntil.
+func (s *Service) WaitlistOffer(ctx context.Context, e domainbooking.WaitlistEntry) {
+	if e.HoldUntil == nil {
+		return
+	}
+	u, prefs, err := s.owner(ctx, e.TelegramID)
+	if err != nil {
+		if !errors.Is(err, domainuser.ErrNotFound) {
+			log.Printf("notify: очередь %s: %v", e.ID, err)
+		}
+		return
+	}
+
+	msgs := s.messages(u, prefs, domain.Message{
+		DedupKey: "waitlist:" + e.ID,
+		Kind:     domain.KindWaitlist,
+		Subject:  "Освободилось время",
+		Body: fmt.Sprintf("Освободилось время, которого вы ждали: «%s» в комнате %d на %s. Подтвердите бронь до %s, иначе время уйдёт следующему в очереди.",
+			e.Title, e.Room, e.Start.In(s.cfg.Location).Format("02.01 15:04"), e.HoldUntil.In(s.cfg.Location).Format("15:04")),
+		SendAfter: s.now(),
+	})
+	if err := s.outbox.Enqueue(ctx, msgs...); err != nil {
+		log.Printf("notify: очередь %s: %v", e.ID, err)
+	}
+}
+
+// ScheduleReminders ставит в outbox напоминания о бронях в ближайшие Lookahead.
+// Повторный вызов ничего не дублирует: ключ напоминания включает ID и время начала брони.
+func (s *Service) ScheduleReminders(ctx context.Context) error {
+	now := s.now()
+	upcoming, err := s.bookings.Find(ctx, domainbooking.ListFilter{From: now, To: now.Add(s.cfg.Lookahead)})
+	if err != nil {
+		return err
+	}
+
+	var msgs []domain.Message
+	for _, b := range upcoming {
+		if !b.Start.After(now) {
+			continue // уже идёт
+		}
+		u, prefs, err := s.owner(ctx, b.TelegramID)
+		if err != nil {
+			if errors.Is(err, domainuser.ErrNotFound) {
+				continue
+			}
+			return err
+		}
+		if !prefs.Reminders {
+			continue
+		}
+
+		sendAfter := b.Start.Add(-time.Duration(prefs.ReminderMinutes) * time.Minute)
+		if sendAfter.After(now.Add(s.cfg.Interval)) {
+			continue // рано, поставим на одном из следующих проходов
+		}
+		msgs = append(msgs, s.messages(u, prefs, domain.Message{
+			DedupKey:  "reminder:" + b.ID + ":" + strconv.FormatInt(b.Start.Unix(), 10),
+			Kind:      domain.KindReminder,
+			BookingID: b.ID,
+			Subject:   "Напоминание о брони",
+			Body: fmt.Sprintf("Напоминание: в %s бронь «%s» в комнате %d.",
+				b.Start.In(s.location(ctx, b.Dormitory)).Format("15:04"), b.Title, b.Room),
+			SendAfter: sendAfter,
+		})...)
+	}
+
+	if len(msgs) == 0 {
+		return nil
+	}
+	return s.outbox.Enqueue(ctx, msgs...)
+}
+
+// Deliver отправляет сообщения, которым пора. Неудачные откладываются с растущей паузой,
+// после maxAttempts попыток сообщение остаётся в outbox с ошибкой.
+func (s *Service) Deliver(ctx context.Context) error {
+	now := s.now()
+	due, err := s.outbox.Claim(ctx, now, claimLease, claimBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range due {
+		if err := s.deliver(ctx, m); err != nil {
+			retryAt := time.Time{}
+			if m.Attempts+1 < maxAttempts {
+				retryAt = now.Add(time.Minute << m.Attempts)
+			}
+			if err := s.outbox.MarkFailed(ctx, m.ID, err.Error(), retryAt); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := s.outbox.MarkSent(ctx, m.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var errStale = errors.New("бронь отменена или перенесена")
+
+func (s *Service) deliver(ctx context.Context, m domain.Message) error {
+	if m.Kind == domain.KindReminder {
+		if err := s.checkStillValid(ctx, m); err != nil {
+			if errors.Is(err, errStale) {
+				return nil // напоминать не о чем, просто закрываем сообщение
+			}
+			return err
+		}
+	}
+
+	sender, ok := s.senders[m.Channel]
+	if !ok {
+		return fmt.Errorf("канал %s не настроен", m.Channel)
+	}
+	return sender.Send(ctx, m)
+}
+
+// checkStillValid - бронь могли отменить или перенести после постановки напоминания.
+func (s *Service) checkStillValid(ctx context.Context, m domain.Message) error {
+	b, err := s.bookings.Get(ctx, m.BookingID)
+	if errors.Is(err, domainbooking.ErrNotFound) || err == nil && b.Cancelled() {
+		return errStale
+	}
+	if err != nil {
+		return err
+	}
+	if m.DedupKey != "reminder:"+b.ID+":"+strconv.FormatInt(b.Start.Unix(), 10)+":"+string(m.Channel) {
+		return errStale
+	}
+	return nil
+}
+
+// GetPreferences возвращает настройки пользователя или настройки по умолчанию.
+func (s *Service) GetPreferences(ctx context.Context, userID string) (domain.Preferences, error) {
+	p, err := s.prefs.Get(ctx, userID)
+	if errors.Is(err, domain.ErrNotFound) {
+		return domain.DefaultPreferences(userID), nil
+	}
+	return p, err
+}
+
+// SavePreferences проверяет и сохраняет настройки пользователя.
+func (s *Service) SavePreferences(ctx context.Context, p domain.Preferences) (domain.Preferences, error) {
+	if err := p.Validate(); err != nil {
+		return domain.Preferences{}, err
+	}
+	if err := s.prefs.Save(ctx, p); err != nil {
+		return domain.Preferences{}, err
+	}
+	return p, nil
+}
+
+func (s *Service) owner(ctx context.Context, telegramID string) (domainuser.User, domain.Preferences, error) {
+	u, err := s.users.GetByTelegram(ctx, telegramID)
+	if err != nil {
+		return domainuser.User{}, domain.Preferences{}, err
+	}
+	prefs, err := s.GetPreferences(ctx, u.ID)
+	if err != nil {
+		return domainuser.User{}, domain.Preferences{}, err
+	}
+	return u, prefs, nil
+}
+
+// messages размножает сообщение по каналам, которые пользователь включил.
+func (s *Service) messages(u domainuser.User, prefs domain.Preferences, tmpl domain.Message) []domain.Message {
+	var out []domain.Message
+	add := func(ch domain.Channel, recipient string) {
+		if _, ok := s.senders[ch]; !ok || recipient == "" {
+			return
+		}
+		m := tmpl
+		m.Channel = ch
+		m.Recipient = recipient
+		m.DedupKey = tmpl.DedupKey + ":" + string(ch)
+		out = append(out, m)
+	}
+
+	if prefs.Telegram && u.TelegramChatID != 0 {
+		add(domain.ChannelTelegram, strconv.FormatInt(u.TelegramChatID, 10))
+	}
+	if prefs.Email {
+		add(domain.ChannelEmail, u.Email)
+	}
+	add(domain.ChannelWebhook, prefs.WebhookURL)
+	return out
+}
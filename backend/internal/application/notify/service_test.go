@@ -0,0 +1,228 @@
+package notify_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	appnotify "Dormitory_Booking/internal/application/notify"
+	domainbooking "Dormitory_Booking/internal/domain/booking"
+	domain "Dormitory_Booking/internal/domain/notification"
+	domainuser "Dormitory_Booking/internal/domain/user"
+	"Dormitory_Booking/internal/infrastructure/memory"
+)
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []domain.Message
+	fail bool
+}
+
+func (s *fakeSender) Send(ctx context.Context, m domain.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("канал недоступен")
+	}
+	s.sent = append(s.sent, m)
+	return nil
+}
+
+type fixture struct {
+	svc      *appnotify.Service
+	bookings *memory.InMemoryBookingRepo
+	prefs    *memory.InMemoryPreferencesRepo
+	telegram *fakeSender
+	email    *fakeSender
+	user     domainuser.User
+}
+
+func newFixture(t *testing.T) *fixture {
+	return newFixtureWith(t, appnotify.Config{Location: time.UTC})
+}
+
+func newFixtureWith(t *testing.T, cfg appnotify.Config) *fixture {
+	ctx := context.Background()
+	users := memory.NewInMemoryUserRepo()
+	u, _ := users.Create(ctx, domainuser.User{Email: "student@edu.hse.ru", TelegramID: "student", TelegramChatID: 42})
+
+	f := &fixture{
+		bookings: memory.NewInMemoryBookingRepo(),
+		prefs:    memory.NewInMemoryPreferencesRepo(),
+		telegram: &fakeSender{},
+		email:    &fakeSender{},
+		user:     u,
+	}
+	// напоминания ищут брони всех общежитий сразу, как в components.go
+	f.svc = appnotify.NewService(memory.NewInMemoryOutbox(), f.prefs, users, f.bookings.ForDormitory(""),
+		map[domain.Channel]appnotify.Sender{
+			domain.ChannelTelegram: f.telegram,
+			domain.ChannelEmail:    f.email,
+		},
+		cfg,
+	)
+	return f
+}
+
+func (f *fixture) tick(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	if err := f.svc.ScheduleReminders(ctx); err != nil {
+		t.Fatalf("ScheduleReminders вернул ошибку: %v", err)
+	}
+	if err := f.svc.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver вернул ошибку: %v", err)
+	}
+}
+
+func TestReminders_SentOncePerChannel(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	f.prefs.Save(ctx, domain.Preferences{UserID: f.user.ID, Telegram: true, Email: true, Reminders: true, ReminderMinutes: 30})
+
+	start := time.Now().Add(10 * time.Minute)
+	f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, Title: "Настолки", TelegramID: "student"})
+	// через 3 часа - напоминать ещё рано
+	later := time.Now().Add(3 * time.Hour)
+	f.bookings.Create(ctx, domainbooking.Booking{Start: later, End: later.Add(time.Hour), Room: domainbooking.Room21, TelegramID: "student"})
+
+	f.tick(t)
+	f.tick(t)
+
+	if len(f.telegram.sent) != 1 || f.telegram.sent[0].Recipient != "42" {
+		t.Fatalf("ожидали одно напоминание в чат 42, получили %+v", f.telegram.sent)
+	}
+	if len(f.email.sent) != 1 || f.email.sent[0].Recipient != "student@edu.hse.ru" {
+		t.Fatalf("ожидали одно письмо, получили %+v", f.email.sent)
+	}
+}
+
+func TestReminders_RespectPreferences(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	f.prefs.Save(ctx, domain.Preferences{UserID: f.user.ID, Telegram: true, Reminders: false})
+
+	start := time.Now().Add(10 * time.Minute)
+	f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, TelegramID: "student"})
+
+	f.tick(t)
+	if len(f.telegram.sent) != 0 {
+		t.Fatalf("напоминания выключены, а пришло %d", len(f.telegram.sent))
+	}
+}
+
+func TestReminders_SkipCancelledBooking(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	start := time.Now().Add(10 * time.Minute)
+	b, _ := f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, TelegramID: "student"})
+
+	// напоминание уже в outbox, но бронь отменили до отправки
+	if err := f.svc.ScheduleReminders(ctx); err != nil {
+		t.Fatalf("ScheduleReminders вернул ошибку: %v", err)
+	}
+	f.bookings.Cancel(ctx, b.ID, domainbooking.Cancellation{At: time.Now(), By: "student"})
+	f.tick(t)
+
+	if len(f.telegram.sent) != 0 {
+		t.Fatalf("об отменённой брони напоминать не нужно, получили %+v", f.telegram.sent)
+	}
+}
+
+func TestDeliver_RetriesLater(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	f.telegram.fail = true
+
+	start := time.Now().Add(10 * time.Minute)
+	f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, TelegramID: "student"})
+
+	f.tick(t)
+	f.telegram.fail = false
+	f.tick(t)
+
+	// следующая попытка - через минуту, а не в тот же проход
+	if len(f.telegram.sent) != 0 {
+		t.Fatalf("повтор раньше паузы: %+v", f.telegram.sent)
+	}
+}
+
+func TestBookingCancelledByAdmin(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	bookings := appbooking.NewService(f.bookings, appbooking.WithNotifier(f.svc))
+	start := time.Now().Add(48 * time.Hour)
+	own, _ := f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, Title: "Кино", TelegramID: "student"})
+	other, _ := f.bookings.Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room132, TelegramID: "student"})
+
+	if err := bookings.CancelBooking(ctx, own.ID, "student", false, ""); err != nil {
+		t.Fatalf("CancelBooking вернул ошибку: %v", err)
+	}
+	if err := bookings.CancelBooking(ctx, other.ID, "", true, ""); err != nil {
+		t.Fatalf("CancelBooking вернул ошибку: %v", err)
+	}
+	f.tick(t)
+
+	if len(f.telegram.sent) != 1 || f.telegram.sent[0].Kind != domain.KindCancelled || f.telegram.sent[0].BookingID != other.ID {
+		t.Fatalf("ожидали одно извещение об отмене админом, получили %+v", f.telegram.sent)
+	}
+}
+
+func TestWaitlistOffer(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	until := time.Date(2099, 1, 5, 12, 30, 0, 0, time.UTC)
+	e := domainbooking.WaitlistEntry{ID: "w1", Start: start, End: start.Add(time.Hour), Room: domainbooking.Room132,
+		Title: "Кино", TelegramID: "student", Status: domainbooking.WaitlistOffered, HoldUntil: &until}
+	f.svc.WaitlistOffer(ctx, e)
+	f.svc.WaitlistOffer(ctx, e)
+	f.tick(t)
+
+	if len(f.telegram.sent) != 1 || f.telegram.sent[0].Kind != domain.KindWaitlist {
+		t.Fatalf("ожидали одно предложение из очереди, получили %+v", f.telegram.sent)
+	}
+	if body := f.telegram.sent[0].Body; !strings.Contains(body, "05.01 18:00") || !strings.Contains(body, "до 12:30") {
+		t.Fatalf("в тексте нет времени брони или срока подтверждения: %s", body)
+	}
+}
+
+func TestReminders_DormitoryZone(t *testing.T) {
+	east := time.FixedZone("UTC+5", 5*3600)
+	f := newFixtureWith(t, appnotify.Config{
+		Location: time.UTC,
+		Zone: func(ctx context.Context, dorm string) *time.Location {
+			if dorm == "east" {
+				return east
+			}
+			return nil
+		},
+	})
+	ctx := context.Background()
+	f.prefs.Save(ctx, domain.Preferences{UserID: f.user.ID, Telegram: true, Reminders: true, ReminderMinutes: 30})
+
+	start := time.Now().Add(10 * time.Minute).Truncate(time.Minute)
+	f.bookings.ForDormitory("east").Create(ctx, domainbooking.Booking{Start: start, End: start.Add(time.Hour), Room: domainbooking.Room21, Title: "Настолки", TelegramID: "student"})
+	f.tick(t)
+
+	if len(f.telegram.sent) != 1 || !strings.Contains(f.telegram.sent[0].Body, start.In(east).Format("15:04")) {
+		t.Fatalf("ожидали время по поясу общежития %s, получили %+v", start.In(east).Format("15:04"), f.telegram.sent)
+	}
+
+	// вид для сервиса броней общежития пишет время в его поясе
+	b := domainbooking.Booking{ID: "b1", Start: start, Room: domainbooking.Room21, Title: "Кино", TelegramID: "student"}
+	f.svc.In(east).BookingCancelled(ctx, b)
+	if err := f.svc.Deliver(ctx); err != nil {
+		t.Fatalf("Deliver вернул ошибку: %v", err)
+	}
+	if len(f.telegram.sent) != 2 || !strings.Contains(f.telegram.sent[1].Body, start.In(east).Format("02.01 15:04")) {
+		t.Fatalf("извещение об отмене не в поясе общежития: %+v", f.telegram.sent)
+	}
+}
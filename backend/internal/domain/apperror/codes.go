@@ -0,0 +1,118 @@
+package apperror
+
+// В этом файле стабильные коды ошибок и их английские тексты.
+// Коды видит фронтенд и интеграции, переименовывать их нельзя.
+
+// Code - машиночитаемый код ошибки.
+type Code string
+
+const (
+	CodeInternal   Code = "INTERNAL"
+	CodeBadRequest Code = "BAD_REQUEST"
+	CodeForbidden  Code = "FORBIDDEN"
+	CodeNotFound   Code = "NOT_FOUND"
+
+	// брони и комнаты
+	CodeOverlap             Code = "OVERLAP"
+	CodeInvalidPeriod       Code = "INVALID_PERIOD"
+	CodeInvalidRoom         Code = "INVALID_ROOM"
+	CodeInPast              Code = "IN_PAST"
+	CodeInvalidTime         Code = "INVALID_TIME"
+	CodePrivateDailyLimit   Code = "PRIVATE_DAILY_LIMIT"
+	CodePrivateEveningLimit Code = "PRIVATE_EVENING_LIMIT"
+	CodeTooLongDuration     Code = "TOO_LONG_DURATION"
+	CodeInvalidRecurrence   Code = "INVALID_RECURRENCE"
+	CodeInvalidSchedule     Code = "INVALID_SCHEDULE"
+	CodeRoomExists          Code = "ROOM_EXISTS"
+	CodeRoomInUse           Code = "ROOM_IN_USE"
+	CodeInvalidPolicy       Code = "INVALID_POLICY"
+	CodeBookingCancelled    Code = "BOOKING_CANCELLED"
+	CodeNotCancelled        Code = "NOT_CANCELLED"
+	CodeWaitlistNotFound    Code = "WAITLIST_NOT_FOUND"
+	CodeSlotFree            Code = "SLOT_FREE"
+	CodeSlotHeld            Code = "SLOT_HELD"
+	CodeNoHold              Code = "NO_HOLD"
+	CodeCheckInClosed       Code = "CHECK_IN_CLOSED"
+	CodeNothingToCheckIn    Code = "NOTHING_TO_CHECK_IN"
+	CodeQuotaActive         Code = "QUOTA_ACTIVE_BOOKINGS"
+	CodeQuotaWeeklyHours    Code = "QUOTA_WEEKLY_HOURS"
+	CodeQuotaHorizon        Code = "QUOTA_HORIZON"
+
+	// пользователи и вход
+	CodeUserNotFound      Code = "USER_NOT_FOUND"
+	CodeInvalidEmail      Code = "INVALID_EMAIL"
+	CodeInvalidLoginCode  Code = "INVALID_LOGIN_CODE"
+	CodeTelegramTaken     Code = "TELEGRAM_TAKEN"
+	CodeTelegramNotLinked Code = "TELEGRAM_NOT_LINKED"
+	CodeInvalidLinkCode   Code = "INVALID_TELEGRAM_LINK_CODE"
+	CodeUnauthorized      Code = "UNAUTHORIZED"
+	CodeTooManyAttempts   Code = "TOO_MANY_ATTEMPTS"
+
+	// уведомления
+	CodePreferencesNotFound Code = "PREFERENCES_NOT_FOUND"
+	CodeInvalidPreferences  Code = "INVALID_PREFERENCES"
+
+	// общежития
+	CodeDormitoryNotFound Code = "DORMITORY_NOT_FOUND"
+	CodeDormitoryExists   Code = "DORMITORY_EXISTS"
+	CodeInvalidDormitory  Code = "INVALID_DORMITORY"
+
+	// синхронизация изменений
+	CodeCursorExpired Code = "CURSOR_EXPIRED"
+)
+
+// Языки сообщений.
+const (
+	LangRU = "ru"
+	LangEN = "en"
+)
+
+var english = map[Code]string{
+	CodeInternal:   "Internal server error.",
+	CodeBadRequest: "Malformed request.",
+	CodeForbidden:  "This operation is not allowed for this user.",
+	CodeNotFound:   "Not found.",
+
+	CodeOverlap:             "The booking overlaps an existing one.",
+	CodeInvalidPeriod:       "The end time must be after the start time.",
+	CodeInvalidRoom:         "Unknown room number.",
+	CodeInPast:              "A booking cannot start in the past.",
+	CodeInvalidTime:         "Booking is not allowed at this time.",
+	CodePrivateDailyLimit:   "The daily limit of private bookings has been reached.",
+	CodePrivateEveningLimit: "The evening limit of private bookings has been reached.",
+	CodeTooLongDuration:     "The booking is longer than allowed.",
+	CodeInvalidRecurrence:   "Invalid recurrence rule.",
+	CodeInvalidSchedule:     "Invalid room opening hours.",
+	CodeRoomExists:          "A room with this number already exists.",
+	CodeRoomInUse:           "The room has bookings and cannot be deleted.",
+	CodeInvalidPolicy:       "Invalid booking policy.",
+	CodeBookingCancelled:    "The booking is already cancelled.",
+	CodeNotCancelled:        "The booking is not cancelled, nothing to restore.",
+	CodeWaitlistNotFound:    "Waitlist entry not found.",
+	CodeSlotFree:            "This time is free, book it directly.",
+	CodeSlotHeld:            "This time is held for the next person on the waitlist.",
+	CodeNoHold:              "The slot is not held for you or the confirmation time has expired.",
+	CodeCheckInClosed:       "Check-in is open only shortly before the start and during the grace window after it.",
+	CodeNothingToCheckIn:    "You have no booking in this room right now.",
+	CodeQuotaActive:         "You already have the maximum number of upcoming bookings.",
+	CodeQuotaWeeklyHours:    "Weekly booking hours limit exceeded.",
+	CodeQuotaHorizon:        "Bookings this far ahead are not allowed.",
+
+	CodeUserNotFound:      "User not found.",
+	CodeInvalidEmail:      "Only university email addresses can sign in.",
+	CodeInvalidLoginCode:  "The login code is wrong or has expired.",
+	CodeTelegramTaken:     "This Telegram account is already linked to another user.",
+	CodeTelegramNotLinked: "Link your Telegram account in the profile first.",
+	CodeInvalidLinkCode:   "The Telegram link code is wrong, has expired or was issued for another account.",
+	CodeUnauthorized:      "You need to sign in.",
+	CodeTooManyAttempts:   "Too many login attempts, try again later.",
+
+	CodePreferencesNotFound: "Notification settings not found.",
+	CodeInvalidPreferences:  "Invalid notification settings.",
+
+	CodeDormitoryNotFound: "Dormitory not found.",
+	CodeDormitoryExists:   "A dormitory with this id already exists.",
+	CodeInvalidDormitory:  "Invalid dormitory: check the id and the time zone.",
+
+	CodeCursorExpired: "The sync cursor has expired, start over from revision 0.",
+}
@@ -0,0 +1,67 @@
+package apperror
+
+// В этом файле общая модель ошибок: стабильный код для клиентов, вид ошибки,
+// по которому HTTP-слой выбирает статус, и текст по-русски.
+
+import "errors"
+
+// Kind - вид ошибки.
+type Kind int
+
+const (
+	KindInternal     Kind = iota // сбой хранилища, сети и т.п.
+	KindBadRequest               // запрос не разобрать
+	KindValidation               // запрос понятен, но нарушает правила
+	KindUnauthorized             // нужно войти
+	KindForbidden                // вошли, но нельзя
+	KindNotFound                 // нет такой сущности
+	KindConflict                 // мешает текущее состояние: пересечение, дубликат
+	KindRateLimited              // слишком часто
+	KindGone                     // данных больше нет: например, устарел курсор синхронизации
+)
+
+// Error - ошибка с кодом. Доменные ошибки объявляются переменными,
+// поэтому errors.Is по-прежнему сравнивает их по указателю.
+type Error struct {
+	Code    Code
+	Kind    Kind
+	Message string // по-русски, для логов и бота
+}
+
+func New(code Code, kind Kind, message string) *Error {
+	return &Error{Code: code, Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Localized возвращает текст на языке lang ("ru" или "en"), по умолчанию русский.
+func (e *Error) Localized(lang string) string {
+	return Localize(e.Code, lang, e.Message)
+}
+
+// Localize - текст для кода на языке lang; ru - русский текст, он же запасной.
+func Localize(code Code, lang, ru string) string {
+	if lang == LangEN {
+		if msg, ok := english[code]; ok {
+			return msg
+		}
+	}
+	return ru
+}
+
+// Общие ошибки, не привязанные к конкретному домену.
+var (
+	Internal   = New(CodeInternal, KindInternal, "Внутренняя ошибка сервера.")
+	BadRequest = New(CodeBadRequest, KindBadRequest, "Некорректный запрос.")
+	Forbidden  = New(CodeForbidden, KindForbidden, "Операция запрещена для этого пользователя.")
+	NotFound   = New(CodeNotFound, KindNotFound, "Не найдено.")
+)
+
+// From достаёт типизированную ошибку из цепочки. Всё остальное - внутренняя ошибка.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal
+}
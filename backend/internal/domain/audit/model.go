@@ -0,0 +1,48 @@
+package audit
+
+// В этом файле журнал изменений броней: кто, что и откуда поменял.
+
+import (
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+// Action - что сделали с бронью.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete" // отмена брони; запись в bookings остаётся со статусом cancelled
+	// ActionRestore - админ вернул отменённую бронь.
+	ActionRestore Action = "restore"
+	// ActionNoShow - бронь освобождена автоматически: владелец не отметился.
+	ActionNoShow Action = "no_show"
+	// ActionAdminOverride - админ изменил или удалил чужую бронь (что именно - видно по After).
+	ActionAdminOverride Action = "admin_override"
+)
+
+// Entry - запись журнала. Before пуст у создания, After - у удаления.
+type Entry struct {
+	ID        string           `json:"id"`
+	At        time.Time        `json:"at"`
+	Actor     string           `json:"actor"` // Telegram ID; пусто - админ по токену
+	Admin     bool             `json:"admin"` // действовал с правами администратора
+	Action    Action           `json:"action"`
+	BookingID string           `json:"bookingId"`
+	Before    *booking.Booking `json:"before,omitempty"`
+	After     *booking.Booking `json:"after,omitempty"`
+	IP        string           `json:"ip,omitempty"`
+	UserAgent string           `json:"userAgent,omitempty"`
+}
+
+// Filter - выборка из журнала. Нулевое поле - не фильтровать. Записи идут от новых к старым.
+type Filter struct {
+	Actor     string
+	BookingID string
+	Action    Action
+	From      time.Time // At >= From
+	To        time.Time // At < To
+	Limit     int       // 0 - без ограничения
+}
@@ -0,0 +1,30 @@
+package audit
+
+// В этом файле хранилище журнала и данные запроса, которые пишутся в запись.
+
+import "context"
+
+// Repository - журнал только на добавление: записи не меняются и не удаляются.
+type Repository interface {
+	Append(ctx context.Context, e Entry) (Entry, error)
+	Find(ctx context.Context, f Filter) ([]Entry, error)
+}
+
+// Request - откуда пришло изменение. HTTP-слой кладёт его в контекст,
+// сервис бронирования достаёт при записи в журнал.
+type Request struct {
+	IP        string
+	UserAgent string
+}
+
+type requestKey struct{}
+
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+// RequestFrom возвращает данные запроса; у бота и фоновых задач их нет.
+func RequestFrom(ctx context.Context) Request {
+	r, _ := ctx.Value(requestKey{}).(Request)
+	return r
+}
@@ -0,0 +1,32 @@
+package booking
+
+// В этом файле журнал ревизий броней для инкрементальной синхронизации.
+// Каждая запись брони получает следующую ревизию из общей для всех броней
+// последовательности. Отмена - тоже запись, так что отменённая бронь остаётся
+// надгробием, по которому клиент узнаёт об удалении. Сами брони из журнала не удаляются:
+// старые надгробия только перестают попадать в выдачу, когда их накрывает горизонт.
+
+import (
+	"context"
+	"time"
+)
+
+// ChangeLog - изменения броней по ревизиям.
+type ChangeLog interface {
+	// Changes возвращает брони, изменённые после ревизии since, по возрастанию ревизии,
+	// не больше limit. since = 0 - всё с начала. ErrCursorExpired - since ниже горизонта
+	// (надгробия за это время устарели) или больше последней выданной ревизии.
+	// Отменённые брони с ревизией не выше горизонта не возвращаются.
+	Changes(ctx context.Context, since int64, limit int) (ChangeSet, error)
+	// ExpireTombstones поднимает горизонт до последней ревизии брони, отменённой раньше
+	// before. Сами брони остаются: по ним считается статистика отмен и неявок.
+	// Возвращает, сколько надгробий устарело.
+	ExpireTombstones(ctx context.Context, before time.Time) (int, error)
+}
+
+// ChangeSet - страница изменений.
+type ChangeSet struct {
+	Bookings []Booking // в том числе отменённые - надгробия
+	Revision int64     // курсор для следующего запроса; без изменений равен since
+	More     bool      // после Revision есть ещё изменения, надо запросить снова
+}
@@ -0,0 +1,35 @@
+package booking
+
+// В этом файле отметка о приходе: бронь без отметки после льготного окна освобождается,
+// а неявка записывается за владельцем.
+
+import (
+	"context"
+	"time"
+)
+
+// ReasonNoShow - причина отмены брони, на которую никто не пришёл.
+const ReasonNoShow = "no-show"
+
+// NoShow - неявка: бронь освободили, потому что владелец не отметился.
+type NoShow struct {
+	ID         string    `json:"id"`
+	TelegramID string    `json:"telegramId"`
+	BookingID  string    `json:"bookingId"`
+	Room       Room      `json:"room"`
+	Start      time.Time `json:"start"`
+	RecordedAt time.Time `json:"recordedAt"`
+}
+
+// NoShowFilter - выборка неявок. Нулевое поле - не фильтровать; From/To - по началу брони.
+type NoShowFilter struct {
+	Owner string
+	From  time.Time
+	To    time.Time
+}
+
+// NoShowRepository - учёт неявок по пользователям. Повторная запись той же брони ничего не меняет.
+type NoShowRepository interface {
+	Record(ctx context.Context, n NoShow) error
+	Find(ctx context.Context, f NoShowFilter) ([]NoShow, error)
+}
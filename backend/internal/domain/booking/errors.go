@@ -1,16 +1,32 @@
 package booking
 
-import "errors"
+import "Dormitory_Booking/internal/domain/apperror"
 
 var (
-	ErrNotFound            = errors.New("Бронь не найдена.")
-	ErrOverlap             = errors.New("Бронь пересекается с существующей.")
-	ErrInvalidPeriod       = errors.New("Время окончания должно быть позже времени начала.")
-	ErrForbidden           = errors.New("Операция запрещена для этого пользователя.")
-	ErrInvalidRoom         = errors.New("Недопустимый номер комнаты.")
-	ErrInPast              = errors.New("Нельзя создавать бронь в прошлом.")
-	ErrInvalidTime         = errors.New("Бронирование не разрешено в это время.")
-	ErrPrivateDailyLimit   = errors.New("Превышен суточный лимит частных бронирований.")
-	ErrPrivateEveningLimit = errors.New("Превышен вечерний лимит частных бронирований.")
-	ErrTooLongDuration     = errors.New("Длительность бронирования превышает максимально допустимую.")
+	ErrNotFound            = apperror.New(apperror.CodeNotFound, apperror.KindNotFound, "Бронь не найдена.")
+	ErrOverlap             = apperror.New(apperror.CodeOverlap, apperror.KindConflict, "Бронь пересекается с существующей.")
+	ErrInvalidPeriod       = apperror.New(apperror.CodeInvalidPeriod, apperror.KindValidation, "Время окончания должно быть позже времени начала.")
+	ErrForbidden           = apperror.Forbidden
+	ErrInvalidRoom         = apperror.New(apperror.CodeInvalidRoom, apperror.KindValidation, "Недопустимый номер комнаты.")
+	ErrInPast              = apperror.New(apperror.CodeInPast, apperror.KindValidation, "Нельзя создавать бронь в прошлом.")
+	ErrInvalidTime         = apperror.New(apperror.CodeInvalidTime, apperror.KindValidation, "Бронирование не разрешено в это время.")
+	ErrPrivateDailyLimit   = apperror.New(apperror.CodePrivateDailyLimit, apperror.KindValidation, "Превышен суточный лимит частных бронирований.")
+	ErrPrivateEveningLimit = apperror.New(apperror.CodePrivateEveningLimit, apperror.KindValidation, "Превышен вечерний лимит частных бронирований.")
+	ErrTooLongDuration     = apperror.New(apperror.CodeTooLongDuration, apperror.KindValidation, "Длительность бронирования превышает максимально допустимую.")
+	ErrInvalidRecurrence   = apperror.New(apperror.CodeInvalidRecurrence, apperror.KindValidation, "Некорректное правило повторения.")
+	ErrInvalidSchedule     = apperror.New(apperror.CodeInvalidSchedule, apperror.KindValidation, "Некорректный график работы комнаты.")
+	ErrRoomExists          = apperror.New(apperror.CodeRoomExists, apperror.KindConflict, "Комната с таким номером уже есть.")
+	ErrCancelled           = apperror.New(apperror.CodeBookingCancelled, apperror.KindConflict, "Бронь уже отменена.")
+	ErrNotCancelled        = apperror.New(apperror.CodeNotCancelled, apperror.KindConflict, "Бронь не отменена, восстанавливать нечего.")
+	ErrWaitlistNotFound    = apperror.New(apperror.CodeWaitlistNotFound, apperror.KindNotFound, "Заявка в очереди не найдена.")
+	ErrSlotFree            = apperror.New(apperror.CodeSlotFree, apperror.KindConflict, "Это время свободно, его можно забронировать сразу.")
+	ErrSlotHeld            = apperror.New(apperror.CodeSlotHeld, apperror.KindConflict, "Это время закреплено за следующим в очереди ожидания.")
+	ErrNoHold              = apperror.New(apperror.CodeNoHold, apperror.KindConflict, "Слот за вами не закреплён или время на подтверждение истекло.")
+	ErrCheckInClosed       = apperror.New(apperror.CodeCheckInClosed, apperror.KindConflict, "Отметиться можно только незадолго до начала брони и в льготное окно после.")
+	ErrNothingToCheckIn    = apperror.New(apperror.CodeNothingToCheckIn, apperror.KindNotFound, "Сейчас у вас нет брони в этой комнате.")
+	ErrQuotaActive         = apperror.New(apperror.CodeQuotaActive, apperror.KindValidation, "У вас уже максимум будущих броней.")
+	ErrQuotaWeeklyHours    = apperror.New(apperror.CodeQuotaWeeklyHours, apperror.KindValidation, "Превышен лимит часов бронирования за неделю.")
+	ErrQuotaHorizon        = apperror.New(apperror.CodeQuotaHorizon, apperror.KindValidation, "Так далеко вперёд бронировать нельзя.")
+	ErrRoomInUse           = apperror.New(apperror.CodeRoomInUse, apperror.KindConflict, "У комнаты есть брони, удалить её нельзя.")
+	ErrCursorExpired       = apperror.New(apperror.CodeCursorExpired, apperror.KindGone, "Курсор синхронизации устарел, начните заново с ревизии 0.")
 )
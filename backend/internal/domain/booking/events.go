@@ -0,0 +1,31 @@
+package booking
+
+// В этом файле события об изменениях броней - для живого обновления таблицы.
+
+import "context"
+
+// EventType - что случилось с бронью.
+type EventType string
+
+const (
+	EventCreated EventType = "created" // новая бронь или возвращённая после отмены
+	EventUpdated EventType = "updated"
+	EventDeleted EventType = "deleted" // отмена: вручную, серией или за неявку
+)
+
+// Event - изменение брони. Booking - бронь после изменения (у deleted - какой она была),
+// Previous - до изменения, только у updated: по нему видно, что бронь ушла из чужого фильтра.
+type Event struct {
+	Type     EventType `json:"type"`
+	Booking  Booking   `json:"booking"`
+	Previous *Booking  `json:"previous,omitempty"`
+}
+
+// EventBus разносит события всем подписчикам, в том числе на других экземплярах backend.
+// Доставка не гарантируется: отставший подписчик отключается и должен перечитать брони.
+type EventBus interface {
+	Publish(ctx context.Context, e Event) error
+	// Subscribe возвращает канал событий; канал закрывается, когда ctx отменён
+	// или подписчик не успевает читать.
+	Subscribe(ctx context.Context) <-chan Event
+}
@@ -4,7 +4,8 @@ package booking
 
 import "time"
 
-// Room - тип комнаты, чтобы не таскать магические числа.
+// Room - номер комнаты. Список комнат и их график лежат в каталоге (RoomRepository),
+// константы ниже - комнаты по умолчанию.
 type Room int
 
 const (
@@ -19,18 +20,55 @@ type Booking struct {
 	Start       time.Time `json:"start"`
 	End         time.Time `json:"end"`
 	Room        Room      `json:"room"`
+	Dormitory   string    `json:"dormitory,omitempty"` // общежитие; ставит хранилище, номер комнаты уникален только внутри него
 	Title       string    `json:"title"`
 	Description string    `json:"description,omitempty"` // опциональное описание, показываем по кнопке "Подробнее"
 	TelegramID  string    `json:"telegramId"`
 	IsPrivate   bool      `json:"isPrivate"`
+	SeriesID    string    `json:"seriesId,omitempty"` // если бронь - занятие из серии
+
+	// Отменённая бронь не удаляется, а остаётся в истории со статусом cancelled.
+	Status       Status     `json:"status,omitempty"` // пусто - то же, что active
+	CancelledAt  *time.Time `json:"cancelledAt,omitempty"`
+	CancelledBy  string     `json:"cancelledBy,omitempty"` // Telegram ID; пусто у отмены админом по токену
+	CancelReason string     `json:"cancelReason,omitempty"`
+
+	CheckedInAt *time.Time `json:"checkedInAt,omitempty"` // владелец отметился, что пришёл
+
+	// Revision - номер последнего изменения брони; ставит хранилище, растёт с каждой записью.
+	Revision int64 `json:"revision,omitempty"`
 }
 
-// IsValidRoom проверяет, что номер комнаты один из разрешённых.
-func IsValidRoom(r Room) bool {
-	switch r {
-	case Room21, Room132, Room256:
-		return true
+// Status - состояние брони.
+type Status string
+
+const (
+	StatusActive    Status = "active"
+	StatusCancelled Status = "cancelled"
+	// StatusCompleted не хранится: так показывается активная бронь, которая уже закончилась.
+	StatusCompleted Status = "completed"
+)
+
+// Cancelled - бронь отменена и комнату не занимает.
+func (b Booking) Cancelled() bool {
+	return b.Status == StatusCancelled
+}
+
+// StatusAt - статус брони на момент now с учётом того, что активная бронь могла уже пройти.
+func (b Booking) StatusAt(now time.Time) Status {
+	switch {
+	case b.Cancelled():
+		return StatusCancelled
+	case !b.End.After(now):
+		return StatusCompleted
 	default:
-		return false
+		return StatusActive
 	}
 }
+
+// Cancellation - кто, когда и почему отменил бронь.
+type Cancellation struct {
+	At     time.Time
+	By     string
+	Reason string
+}
@@ -2,12 +2,67 @@ package booking
 
 // В этом файле описан интерфейс хранилища бронирований.
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository описывает, что умеет слой работы с данными для модели Booking.
+// List и Find не возвращают отменённые брони (если не попросить IncludeCancelled), Get - возвращает.
 type Repository interface {
 	List(ctx context.Context) ([]Booking, error)
+	Find(ctx context.Context, f ListFilter) ([]Booking, error)
 	Get(ctx context.Context, id string) (Booking, error)
 	Create(ctx context.Context, b Booking) (Booking, error)
-	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, b Booking) (Booking, error)
+	// Cancel помечает бронь отменённой; строка остаётся в истории. Повторная отмена - ErrCancelled.
+	Cancel(ctx context.Context, id string, c Cancellation) (Booking, error)
+	// Restore возвращает отменённую бронь в active, если её время никто не занял (иначе ErrOverlap).
+	Restore(ctx context.Context, id string) (Booking, error)
+	// CheckIn ставит отметку о приходе (повторная отметка сохраняет первое время).
+	CheckIn(ctx context.Context, id string, at time.Time) (Booking, error)
+
+	// WithRoomLock выполняет fn атомарно относительно других вызовов по тем же комнатам
+	// и тем же владельцам: проверки лимитов и пересечений внутри fn и последующая запись
+	// не перемешиваются с параллельными созданиями. Владельцы нужны для личных лимитов,
+	// которые считаются по всем комнатам. fn должна работать только через переданный repo.
+	WithRoomLock(ctx context.Context, rooms []Room, owners []string, fn func(repo Repository) error) error
+}
+
+// ListFilter - условия выборки броней. Нулевое значение поля означает "не фильтровать".
+type ListFilter struct {
+	From      time.Time // бронь попадает в выборку, если пересекается с [From, To)
+	To        time.Time
+	Room      Room
+	Owner     string // Telegram ID владельца
+	IsPrivate *bool
+	SeriesID  string
+	// IncludeCancelled - вернуть и отменённые брони. По умолчанию их нет ни в выборках, ни в проверках пересечений.
+	IncludeCancelled bool
+}
+
+// Matches проверяет, подходит ли бронь под фильтр.
+func (f ListFilter) Matches(b Booking) bool {
+	if b.Cancelled() && !f.IncludeCancelled {
+		return false
+	}
+	if !f.From.IsZero() && !b.End.After(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !b.Start.Before(f.To) {
+		return false
+	}
+	if f.Room != 0 && b.Room != f.Room {
+		return false
+	}
+	if f.Owner != "" && b.TelegramID != f.Owner {
+		return false
+	}
+	if f.IsPrivate != nil && b.IsPrivate != *f.IsPrivate {
+		return false
+	}
+	if f.SeriesID != "" && b.SeriesID != f.SeriesID {
+		return false
+	}
+	return true
 }
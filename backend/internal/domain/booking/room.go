@@ -0,0 +1,97 @@
+package booking
+
+// В этом файле описан каталог комнат: описание, график работы и хранилище.
+
+import "context"
+
+// Schedule - часы работы комнаты в виде смещений от полуночи.
+// Закрытие может быть позже 24 (например, 25 = 01:00 следующего дня).
+type Schedule struct {
+	WeekdayOpen  int `json:"weekdayOpen"` // часы с 0 до 24
+	WeekdayClose int `json:"weekdayClose"`
+	FriSatOpen   int `json:"friSatOpen"`
+	FriSatClose  int `json:"friSatClose"`
+	SunOpen      int `json:"sunOpen"`
+	SunClose     int `json:"sunClose"`
+}
+
+// RoomInfo - запись в каталоге комнат.
+type RoomInfo struct {
+	Number    Room     `json:"number"`
+	Name      string   `json:"name"`
+	Building  string   `json:"building,omitempty"`
+	Floor     int      `json:"floor"`
+	Capacity  int      `json:"capacity"`
+	Amenities []string `json:"amenities"`
+	Active    bool     `json:"active"` // неактивную комнату видно в админке, но бронировать нельзя
+	Schedule  Schedule `json:"schedule"`
+
+	// CheckInToken - секрет в QR-коде на двери: скан отмечает приход на текущую бронь.
+	CheckInToken string `json:"-"`
+}
+
+// RoomRepository - хранилище каталога комнат.
+type RoomRepository interface {
+	List(ctx context.Context) ([]RoomInfo, error)
+	Get(ctx context.Context, number Room) (RoomInfo, error)
+	GetByCheckInToken(ctx context.Context, token string) (RoomInfo, error)
+	Create(ctx context.Context, r RoomInfo) (RoomInfo, error)
+	Update(ctx context.Context, r RoomInfo) (RoomInfo, error)
+	Delete(ctx context.Context, number Room) error
+}
+
+// Validate проверяет запись каталога: номер и вменяемый график.
+func (r RoomInfo) Validate() error {
+	if r.Number <= 0 {
+		return ErrInvalidRoom
+	}
+	if r.Capacity < 0 {
+		return ErrInvalidRoom
+	}
+	s := r.Schedule
+	for _, p := range [][2]int{
+		{s.WeekdayOpen, s.WeekdayClose},
+		{s.FriSatOpen, s.FriSatClose},
+		{s.SunOpen, s.SunClose},
+	} {
+		// закрыться можно не позже 06:00 следующего дня
+		if p[0] < 0 || p[0] >= 24 || p[1] <= p[0] || p[1] > 30 {
+			return ErrInvalidSchedule
+		}
+	}
+	return nil
+}
+
+// DefaultRooms - комнаты, с которых всё начиналось. Ими засевается каталог,
+// и на них же опирается сервис, если хранилище комнат не подключено.
+func DefaultRooms() []RoomInfo {
+	return []RoomInfo{
+		{
+			Number: Room21, Name: "Досуговая 21", Floor: 0, Active: true,
+			Amenities: []string{},
+			Schedule: Schedule{
+				WeekdayOpen: 6, WeekdayClose: 23,
+				FriSatOpen: 6, FriSatClose: 25, // до 01:00
+				SunOpen: 6, SunClose: 23,
+			},
+		},
+		{
+			Number: Room132, Name: "Досуговая 132", Floor: 1, Active: true,
+			Amenities: []string{},
+			Schedule: Schedule{
+				WeekdayOpen: 6, WeekdayClose: 22,
+				FriSatOpen: 6, FriSatClose: 23,
+				SunOpen: 6, SunClose: 22,
+			},
+		},
+		{
+			Number: Room256, Name: "Досуговая 256", Floor: 2, Active: true,
+			Amenities: []string{},
+			Schedule: Schedule{
+				WeekdayOpen: 6, WeekdayClose: 23,
+				FriSatOpen: 6, FriSatClose: 25,
+				SunOpen: 6, SunClose: 23,
+			},
+		},
+	}
+}
@@ -0,0 +1,152 @@
+package booking
+
+// В этом файле описаны повторяющиеся брони (серии) и правило повторения в духе RRULE.
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Frequency - как часто повторяется серия.
+type Frequency string
+
+const (
+	FreqWeekly   Frequency = "weekly"
+	FreqBiweekly Frequency = "biweekly"
+)
+
+// MaxSeriesOccurrences - потолок занятий в одной серии (семестр с запасом).
+const MaxSeriesOccurrences = 60
+
+// Recurrence - правило повторения: раз в неделю или в две, по дням недели, до даты или N раз.
+type Recurrence struct {
+	Freq     Frequency      `json:"freq"`
+	Weekdays []time.Weekday `json:"weekdays,omitempty"` // пусто - день недели первого занятия
+	Until    time.Time      `json:"until,omitempty"`    // включительно, по дате
+	Count    int            `json:"count,omitempty"`
+}
+
+// Series - родительская запись серии. Сами занятия лежат в bookings со ссылкой SeriesID.
+type Series struct {
+	ID          string     `json:"id"`
+	Start       time.Time  `json:"start"` // первое занятие, от него берём время суток и длительность
+	End         time.Time  `json:"end"`
+	Room        Room       `json:"room"`
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	TelegramID  string     `json:"telegramId"`
+	IsPrivate   bool       `json:"isPrivate"`
+	Rule        Recurrence `json:"rule"`
+	// EndedAt - когда серию отменили целиком. Запись остаётся, чтобы прошедшие
+	// занятия в истории не теряли ссылку на серию.
+	EndedAt *time.Time `json:"endedAt,omitempty"`
+}
+
+// SeriesRepository - хранилище родительских записей серий.
+type SeriesRepository interface {
+	Create(ctx context.Context, s Series) (Series, error)
+	Get(ctx context.Context, id string) (Series, error)
+	// End помечает серию завершённой; повторный вызов сохраняет первое время.
+	End(ctx context.Context, id string, at time.Time) error
+	// Delete удаляет серию, на которую не ссылается ни одно занятие.
+	Delete(ctx context.Context, id string) error
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// ParseWeekday переводит код дня из RRULE (MO, TU, ...) в time.Weekday.
+func ParseWeekday(code string) (time.Weekday, bool) {
+	wd, ok := weekdayCodes[strings.ToUpper(strings.TrimSpace(code))]
+	return wd, ok
+}
+
+// Validate проверяет правило повторения.
+func (r Recurrence) Validate() error {
+	if r.Freq != FreqWeekly && r.Freq != FreqBiweekly {
+		return ErrInvalidRecurrence
+	}
+	if r.Until.IsZero() && r.Count <= 0 {
+		return ErrInvalidRecurrence
+	}
+	if r.Count < 0 || r.Count > MaxSeriesOccurrences {
+		return ErrInvalidRecurrence
+	}
+	for _, wd := range r.Weekdays {
+		if wd < time.Sunday || wd > time.Saturday {
+			return ErrInvalidRecurrence
+		}
+	}
+	return nil
+}
+
+// Occurrences раскладывает серию в конкретные интервалы. Время суток и длительность берутся
+// из первого занятия в его часовом поясе, так что переход на летнее время не сдвигает занятия.
+func (s Series) Occurrences() [][2]time.Time {
+	loc := s.Start.Location()
+	first := s.Start.In(loc)
+	dur := s.End.Sub(s.Start)
+
+	weekdays := s.Rule.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []time.Weekday{first.Weekday()}
+	}
+	// считаем неделю с понедельника, поэтому воскресенье идёт последним
+	offsets := make([]int, 0, len(weekdays))
+	seen := make(map[int]bool)
+	for _, wd := range weekdays {
+		off := (int(wd) + 6) % 7
+		if !seen[off] {
+			seen[off] = true
+			offsets = append(offsets, off)
+		}
+	}
+	sort.Ints(offsets)
+
+	step := 1
+	if s.Rule.Freq == FreqBiweekly {
+		step = 2
+	}
+
+	limit := s.Rule.Count
+	if limit <= 0 || limit > MaxSeriesOccurrences {
+		limit = MaxSeriesOccurrences
+	}
+
+	var untilDay time.Time
+	if !s.Rule.Until.IsZero() {
+		u := s.Rule.Until.In(loc)
+		untilDay = time.Date(u.Year(), u.Month(), u.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	}
+
+	monday := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc).
+		AddDate(0, 0, -((int(first.Weekday()) + 6) % 7))
+
+	var out [][2]time.Time
+	for week := 0; len(out) < limit; week += step {
+		for _, off := range offsets {
+			d := monday.AddDate(0, 0, week*7+off)
+			start := time.Date(d.Year(), d.Month(), d.Day(), first.Hour(), first.Minute(), first.Second(), 0, loc)
+			if start.Before(first) {
+				continue
+			}
+			if !untilDay.IsZero() && !start.Before(untilDay) {
+				return out
+			}
+			out = append(out, [2]time.Time{start, start.Add(dur)})
+			if len(out) == limit {
+				return out
+			}
+		}
+	}
+	return out
+}
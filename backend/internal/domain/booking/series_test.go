@@ -0,0 +1,71 @@
+package booking_test
+
+import (
+	"Dormitory_Booking/internal/domain/booking"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSeriesOccurrences_WeeklyByCount(t *testing.T) {
+	// вторник, 19:00-21:00
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	s := booking.Series{
+		Start: start,
+		End:   start.Add(2 * time.Hour),
+		Rule:  booking.Recurrence{Freq: booking.FreqWeekly, Count: 4},
+	}
+
+	occ := s.Occurrences()
+	if len(occ) != 4 {
+		t.Fatalf("ожидали 4 занятия, получили %d", len(occ))
+	}
+	for i, o := range occ {
+		want := start.AddDate(0, 0, 7*i)
+		if !o[0].Equal(want) || o[1].Sub(o[0]) != 2*time.Hour {
+			t.Fatalf("занятие %d: ожидали %v, получили %v - %v", i, want, o[0], o[1])
+		}
+	}
+}
+
+func TestSeriesOccurrences_BiweeklyByDayUntil(t *testing.T) {
+	// понедельник, первое занятие; повторяем по вт и чт раз в две недели
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	s := booking.Series{
+		Start: start,
+		End:   start.Add(time.Hour),
+		Rule: booking.Recurrence{
+			Freq:     booking.FreqBiweekly,
+			Weekdays: []time.Weekday{time.Thursday, time.Tuesday},
+			Until:    time.Date(2099, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	occ := s.Occurrences()
+	want := []time.Time{
+		time.Date(2099, 1, 6, 18, 0, 0, 0, time.UTC),
+		time.Date(2099, 1, 8, 18, 0, 0, 0, time.UTC),
+		time.Date(2099, 1, 20, 18, 0, 0, 0, time.UTC), // until включительно по дате
+	}
+	if len(occ) != len(want) {
+		t.Fatalf("ожидали %d занятий, получили %d: %v", len(want), len(occ), occ)
+	}
+	for i := range want {
+		if !occ[i][0].Equal(want[i]) {
+			t.Fatalf("занятие %d: ожидали %v, получили %v", i, want[i], occ[i][0])
+		}
+	}
+}
+
+func TestRecurrenceValidate(t *testing.T) {
+	bad := []booking.Recurrence{
+		{Freq: "daily", Count: 3},
+		{Freq: booking.FreqWeekly},
+		{Freq: booking.FreqWeekly, Count: booking.MaxSeriesOccurrences + 1},
+	}
+	for _, r := range bad {
+		if err := r.Validate(); !errors.Is(err, booking.ErrInvalidRecurrence) {
+			t.Fatalf("%+v: ожидали ErrInvalidRecurrence, получили %v", r, err)
+		}
+	}
+}
@@ -5,7 +5,8 @@ import "time"
 // В этом файле простые функции валидации.
 
 func (b Booking) ValidateBasic() error {
-	if !IsValidRoom(b.Room) {
+	// есть ли такая комната в каталоге, проверяет сервис
+	if b.Room <= 0 {
 		return ErrInvalidRoom
 	}
 	if !b.Start.After(time.Now()) {
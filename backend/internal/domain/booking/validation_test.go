@@ -50,7 +50,7 @@ func TestValidateBasic_InvalidRoom(t *testing.T) {
 	b := booking.Booking{
 		Start: time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC),
 		End:   time.Date(2025, 1, 1, 11, 0, 0, 0, time.UTC),
-		Room:  999,
+		Room:  0,
 	}
 
 	if err := b.ValidateBasic(); !errors.Is(err, booking.ErrInvalidRoom) {
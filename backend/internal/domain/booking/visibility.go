@@ -0,0 +1,39 @@
+package booking
+
+// В этом файле уровни видимости: что из брони положено видеть конкретному зрителю.
+
+// Visibility - насколько подробно зритель видит бронь.
+type Visibility string
+
+const (
+	// VisibilityPublic - обычная бронь: название, описание и владелец видны всем.
+	VisibilityPublic Visibility = "public"
+	// VisibilityPrivate - частная бронь глазами владельца или админа: видно всё.
+	VisibilityPrivate Visibility = "private"
+	// VisibilityAnonymousBusy - чужая частная бронь: только комната, время и «Занято».
+	VisibilityAnonymousBusy Visibility = "anonymous-busy"
+)
+
+// BusyTitle - название брони, подробности которой зрителю не положены.
+const BusyTitle = "Занято"
+
+// VisibilityFor - уровень видимости брони для зрителя viewerID (пусто - аноним).
+func (b Booking) VisibilityFor(viewerID string, isAdmin bool) Visibility {
+	return visibilityFor(b.IsPrivate, b.TelegramID, viewerID, isAdmin)
+}
+
+// VisibilityFor - то же для серии: частная серия видна целиком только владельцу и админу.
+func (s Series) VisibilityFor(viewerID string, isAdmin bool) Visibility {
+	return visibilityFor(s.IsPrivate, s.TelegramID, viewerID, isAdmin)
+}
+
+func visibilityFor(isPrivate bool, owner, viewerID string, isAdmin bool) Visibility {
+	switch {
+	case !isPrivate:
+		return VisibilityPublic
+	case isAdmin || (viewerID != "" && viewerID == owner):
+		return VisibilityPrivate
+	default:
+		return VisibilityAnonymousBusy
+	}
+}
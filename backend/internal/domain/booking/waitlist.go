@@ -0,0 +1,96 @@
+package booking
+
+// В этом файле очередь ожидания на занятое время: студент встаёт в очередь,
+// а когда время освобождается, слот на время закрепляется за первым подходящим.
+
+import (
+	"context"
+	"time"
+)
+
+// WaitlistStatus - состояние заявки в очереди.
+type WaitlistStatus string
+
+const (
+	WaitlistWaiting   WaitlistStatus = "waiting"
+	WaitlistOffered   WaitlistStatus = "offered" // слот держится за заявителем до HoldUntil
+	WaitlistConfirmed WaitlistStatus = "confirmed"
+	WaitlistExpired   WaitlistStatus = "expired" // не подтвердил вовремя или время уже прошло
+	WaitlistWithdrawn WaitlistStatus = "withdrawn"
+)
+
+// WaitlistEntry - заявка на время, которое сейчас занято.
+type WaitlistEntry struct {
+	ID          string         `json:"id"`
+	Start       time.Time      `json:"start"`
+	End         time.Time      `json:"end"`
+	Room        Room           `json:"room"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	TelegramID  string         `json:"telegramId"`
+	IsPrivate   bool           `json:"isPrivate"`
+	CreatedAt   time.Time      `json:"createdAt"` // порядок в очереди
+	Status      WaitlistStatus `json:"status"`
+	HoldUntil   *time.Time     `json:"holdUntil,omitempty"`
+	BookingID   string         `json:"bookingId,omitempty"` // бронь, созданная подтверждением
+}
+
+// Booking - бронь, которую просит заявка.
+func (e WaitlistEntry) Booking() Booking {
+	return Booking{
+		Start:       e.Start,
+		End:         e.End,
+		Room:        e.Room,
+		Title:       e.Title,
+		Description: e.Description,
+		TelegramID:  e.TelegramID,
+		IsPrivate:   e.IsPrivate,
+	}
+}
+
+// Holds - держит ли заявка слот в момент now.
+func (e WaitlistEntry) Holds(now time.Time) bool {
+	return e.Status == WaitlistOffered && e.HoldUntil != nil && e.HoldUntil.After(now)
+}
+
+// WaitlistFilter - выборка заявок. Нулевое поле - не фильтровать.
+type WaitlistFilter struct {
+	From     time.Time // заявка пересекается с [From, To)
+	To       time.Time
+	Room     Room
+	Owner    string
+	Statuses []WaitlistStatus
+}
+
+// Matches проверяет, подходит ли заявка под фильтр.
+func (f WaitlistFilter) Matches(e WaitlistEntry) bool {
+	if !f.From.IsZero() && !e.End.After(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.Start.Before(f.To) {
+		return false
+	}
+	if f.Room != 0 && e.Room != f.Room {
+		return false
+	}
+	if f.Owner != "" && e.TelegramID != f.Owner {
+		return false
+	}
+	if len(f.Statuses) == 0 {
+		return true
+	}
+	for _, s := range f.Statuses {
+		if e.Status == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WaitlistRepository - хранилище очереди. Find отдаёт заявки в порядке очереди (по CreatedAt).
+type WaitlistRepository interface {
+	Add(ctx context.Context, e WaitlistEntry) (WaitlistEntry, error)
+	Get(ctx context.Context, id string) (WaitlistEntry, error)
+	Find(ctx context.Context, f WaitlistFilter) ([]WaitlistEntry, error)
+	Update(ctx context.Context, e WaitlistEntry) (WaitlistEntry, error)
+}
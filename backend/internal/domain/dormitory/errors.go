@@ -0,0 +1,9 @@
+package dormitory
+
+import "Dormitory_Booking/internal/domain/apperror"
+
+var (
+	ErrNotFound = apperror.New(apperror.CodeDormitoryNotFound, apperror.KindNotFound, "Общежитие не найдено.")
+	ErrExists   = apperror.New(apperror.CodeDormitoryExists, apperror.KindConflict, "Общежитие с таким именем уже есть.")
+	ErrInvalid  = apperror.New(apperror.CodeInvalidDormitory, apperror.KindValidation, "Некорректное общежитие: проверьте имя и часовой пояс.")
+)
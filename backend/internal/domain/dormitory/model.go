@@ -0,0 +1,103 @@
+package dormitory
+
+// В этом файле описано общежитие - арендатор, внутри которого живут комнаты, брони,
+// политика и свои администраторы. Номера комнат в разных общежитиях могут совпадать.
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultID - общежитие, с которого всё начиналось. К нему относятся старые данные
+// и запросы без префикса /d/{id}.
+const DefaultID = "main"
+
+// Dormitory - общежитие.
+type Dormitory struct {
+	ID       string   `json:"id"` // короткое имя для адреса: /d/{id}/bookings
+	Name     string   `json:"name"`
+	Hosts    []string `json:"hosts,omitempty"`  // домены, по которым открывается это общежитие
+	Timezone string   `json:"timezone"`         // IANA, например Europe/Moscow; пусто - пояс по умолчанию
+	Admins   []string `json:"admins,omitempty"` // почты администраторов этого общежития
+}
+
+var idPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// Normalize приводит хосты и почты к виду, в котором они сравниваются.
+func (d Dormitory) Normalize() Dormitory {
+	d.ID = strings.ToLower(strings.TrimSpace(d.ID))
+	d.Name = strings.TrimSpace(d.Name)
+	hosts := make([]string, 0, len(d.Hosts))
+	for _, h := range d.Hosts {
+		if h = NormalizeHost(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	d.Hosts = hosts
+	admins := make([]string, 0, len(d.Admins))
+	for _, a := range d.Admins {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			admins = append(admins, a)
+		}
+	}
+	d.Admins = admins
+	return d
+}
+
+// Validate проверяет короткое имя, название и часовой пояс.
+func (d Dormitory) Validate() error {
+	if !idPattern.MatchString(d.ID) || d.Name == "" {
+		return ErrInvalid
+	}
+	if _, err := time.LoadLocation(d.Timezone); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+// Location - часовой пояс общежития; если он не задан или неизвестен - fallback.
+func (d Dormitory) Location(fallback *time.Location) *time.Location {
+	if d.Timezone == "" {
+		return fallback
+	}
+	loc, err := time.LoadLocation(d.Timezone)
+	if err != nil {
+		return fallback
+	}
+	return loc
+}
+
+// IsAdmin - входит ли почта в список администраторов общежития.
+func (d Dormitory) IsAdmin(email string) bool {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return false
+	}
+	for _, a := range d.Admins {
+		if a == email {
+			return true
+		}
+	}
+	return false
+}
+
+// ServesHost - открывается ли общежитие по этому домену.
+func (d Dormitory) ServesHost(host string) bool {
+	host = NormalizeHost(host)
+	for _, h := range d.Hosts {
+		if h == host {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeHost убирает порт и приводит домен к нижнему регистру.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.LastIndex(host, ":"); i >= 0 && !strings.Contains(host[i:], "]") {
+		host = host[:i]
+	}
+	return host
+}
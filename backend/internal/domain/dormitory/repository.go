@@ -0,0 +1,13 @@
+package dormitory
+
+// В этом файле описано хранилище общежитий.
+
+import "context"
+
+// Repository - каталог общежитий.
+type Repository interface {
+	List(ctx context.Context) ([]Dormitory, error)
+	Get(ctx context.Context, id string) (Dormitory, error)
+	Create(ctx context.Context, d Dormitory) (Dormitory, error)
+	Update(ctx context.Context, d Dormitory) (Dormitory, error)
+}
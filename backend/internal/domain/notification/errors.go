@@ -0,0 +1,8 @@
+package notification
+
+import "Dormitory_Booking/internal/domain/apperror"
+
+var (
+	ErrNotFound           = apperror.New(apperror.CodePreferencesNotFound, apperror.KindNotFound, "Настройки уведомлений не найдены.")
+	ErrInvalidPreferences = apperror.New(apperror.CodeInvalidPreferences, apperror.KindValidation, "Некорректные настройки уведомлений.")
+)
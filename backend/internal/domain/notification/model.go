@@ -0,0 +1,79 @@
+package notification
+
+// В этом файле доменная модель уведомлений: сообщения в outbox и настройки пользователя.
+
+import (
+	"net/url"
+	"time"
+)
+
+// Channel - куда доставляется сообщение.
+type Channel string
+
+const (
+	ChannelTelegram Channel = "telegram"
+	ChannelEmail    Channel = "email"
+	ChannelWebhook  Channel = "webhook"
+)
+
+// Kind - повод для сообщения.
+type Kind string
+
+const (
+	KindReminder  Kind = "reminder"  // скоро начнётся бронь
+	KindCancelled Kind = "cancelled" // админ удалил чужую бронь
+	KindWaitlist  Kind = "waitlist"  // освободилось время из заявки в очереди
+)
+
+// Message - запись в outbox. DedupKey уникален: повторная постановка того же
+// напоминания (например, после перезапуска) ничего не добавляет.
+type Message struct {
+	ID        string
+	DedupKey  string
+	Kind      Kind
+	Channel   Channel
+	Recipient string // chat_id в Telegram, почта или URL вебхука
+	BookingID string
+	Subject   string
+	Body      string
+	SendAfter time.Time
+	Attempts  int
+	LastError string
+}
+
+// Preferences - настройки уведомлений пользователя.
+type Preferences struct {
+	UserID          string `json:"-"`
+	Telegram        bool   `json:"telegram"`
+	Email           bool   `json:"email"`
+	WebhookURL      string `json:"webhookUrl,omitempty"`
+	Reminders       bool   `json:"reminders"`
+	ReminderMinutes int    `json:"reminderMinutes"`
+}
+
+// DefaultPreferences - что получает пользователь, который ничего не настраивал.
+func DefaultPreferences(userID string) Preferences {
+	return Preferences{
+		UserID:          userID,
+		Telegram:        true,
+		Reminders:       true,
+		ReminderMinutes: 30,
+	}
+}
+
+// MaxReminderMinutes - напоминание не раньше, чем за сутки.
+const MaxReminderMinutes = 24 * 60
+
+// Validate проверяет настройки.
+func (p Preferences) Validate() error {
+	if p.ReminderMinutes < 0 || p.ReminderMinutes > MaxReminderMinutes {
+		return ErrInvalidPreferences
+	}
+	if p.WebhookURL != "" {
+		u, err := url.Parse(p.WebhookURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return ErrInvalidPreferences
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package notification
+
+// В этом файле описаны outbox и хранилище настроек уведомлений.
+
+import (
+	"context"
+	"time"
+)
+
+// Outbox - очередь исходящих сообщений, переживающая перезапуск.
+type Outbox interface {
+	// Enqueue добавляет сообщения; сообщения с уже известным DedupKey пропускаются.
+	Enqueue(ctx context.Context, msgs ...Message) error
+	// Claim забирает до limit сообщений, которым пора уйти, и прячет их от других
+	// обработчиков на lease - чтобы два экземпляра не отправили одно и то же.
+	Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]Message, error)
+	MarkSent(ctx context.Context, id string) error
+	// MarkFailed откладывает сообщение до retryAt; нулевой retryAt - больше не пытаться.
+	MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error
+}
+
+// PreferencesRepository - настройки уведомлений по пользователю.
+type PreferencesRepository interface {
+	Get(ctx context.Context, userID string) (Preferences, error)
+	Save(ctx context.Context, p Preferences) error
+}
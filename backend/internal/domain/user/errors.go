@@ -0,0 +1,14 @@
+package user
+
+import "Dormitory_Booking/internal/domain/apperror"
+
+var (
+	ErrNotFound          = apperror.New(apperror.CodeUserNotFound, apperror.KindNotFound, "Пользователь не найден.")
+	ErrInvalidEmail      = apperror.New(apperror.CodeInvalidEmail, apperror.KindValidation, "Войти можно только с корпоративной почты.")
+	ErrInvalidCode       = apperror.New(apperror.CodeInvalidLoginCode, apperror.KindValidation, "Неверный или просроченный код входа.")
+	ErrTelegramTaken     = apperror.New(apperror.CodeTelegramTaken, apperror.KindConflict, "Этот Telegram уже привязан к другому пользователю.")
+	ErrTelegramNotLinked = apperror.New(apperror.CodeTelegramNotLinked, apperror.KindForbidden, "Сначала привяжите Telegram в профиле.")
+	ErrInvalidLinkCode   = apperror.New(apperror.CodeInvalidLinkCode, apperror.KindValidation, "Код привязки неверный, просрочен или выдан для другого Telegram.")
+	ErrUnauthorized      = apperror.New(apperror.CodeUnauthorized, apperror.KindUnauthorized, "Нужно войти в систему.")
+	ErrTooManyAttempts   = apperror.New(apperror.CodeTooManyAttempts, apperror.KindRateLimited, "Слишком много попыток входа, попробуйте позже.")
+)
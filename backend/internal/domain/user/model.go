@@ -0,0 +1,60 @@
+package user
+
+// В этом файле описана доменная модель пользователя.
+
+import (
+	"strings"
+	"time"
+)
+
+// User - студент, вошедший по корпоративной почте.
+type User struct {
+	ID         string `json:"id"`
+	Email      string `json:"email"`
+	TelegramID string `json:"telegramId,omitempty"` // от него считается владелец брони
+	// TelegramChatID - числовой ID в Telegram, бот узнаёт его из первого сообщения.
+	// Без него бот не может написать пользователю сам.
+	TelegramChatID int64 `json:"-"`
+	// CalendarToken - секрет в адресе личной подписки на календарь броней.
+	CalendarToken string    `json:"-"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// LoginCode - одноразовый код входа, отправленный на почту. Храним только хэш.
+type LoginCode struct {
+	Email     string
+	CodeHash  string
+	ExpiresAt time.Time
+	Attempts  int
+}
+
+// TelegramLink - запрос на привязку Telegram, который ещё не подтвердили в боте.
+// Привязка сохраняется, только когда код пришлют боту с этого самого ника.
+type TelegramLink struct {
+	CodeHash   string
+	UserID     string
+	TelegramID string
+	ExpiresAt  time.Time
+}
+
+// NormalizeEmail приводит адрес к виду, в котором он хранится.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// NormalizeTelegram убирает @ и приводит ник к нижнему регистру.
+func NormalizeTelegram(tg string) string {
+	tg = strings.TrimSpace(tg)
+	tg = strings.TrimPrefix(tg, "@")
+	return strings.ToLower(tg)
+}
+
+// Session - серверная запись о выданной сессии. Подписанный токен ссылается на неё по ID,
+// так что сессию можно отозвать до истечения срока.
+type Session struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	Revoked   bool
+}
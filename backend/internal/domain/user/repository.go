@@ -0,0 +1,37 @@
+package user
+
+// В этом файле описаны хранилища пользователей и кодов входа.
+
+import "context"
+
+// Repository - хранилище пользователей.
+type Repository interface {
+	Get(ctx context.Context, id string) (User, error)
+	GetByEmail(ctx context.Context, email string) (User, error)
+	GetByTelegram(ctx context.Context, telegramID string) (User, error)
+	GetByCalendarToken(ctx context.Context, token string) (User, error)
+	Create(ctx context.Context, u User) (User, error)
+	Update(ctx context.Context, u User) (User, error)
+}
+
+// LoginCodeRepository - одноразовые коды входа, не больше одного на почту.
+type LoginCodeRepository interface {
+	Save(ctx context.Context, c LoginCode) error
+	Get(ctx context.Context, email string) (LoginCode, error)
+	Delete(ctx context.Context, email string) error
+}
+
+// TelegramLinkRepository - неподтверждённые привязки Telegram, не больше одной на пользователя.
+// Take достаёт привязку по хэшу кода и сразу удаляет её: код одноразовый.
+type TelegramLinkRepository interface {
+	Save(ctx context.Context, l TelegramLink) error
+	Take(ctx context.Context, codeHash string) (TelegramLink, error)
+}
+
+// SessionRepository - выданные сессии. RevokeAll разлогинивает пользователя на всех устройствах.
+type SessionRepository interface {
+	Create(ctx context.Context, s Session) error
+	Get(ctx context.Context, id string) (Session, error)
+	Revoke(ctx context.Context, id string) error
+	RevokeAll(ctx context.Context, userID string) error
+}
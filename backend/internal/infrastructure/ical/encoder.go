@@ -0,0 +1,165 @@
+package ical
+
+// В этом файле кодировщик iCalendar (RFC 5545): календарь из броней для Google/Apple Calendar.
+// Время событий пишется в местном поясе с TZID, а сам пояс описывается блоком VTIMEZONE.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+const (
+	prodID    = "-//Dormitory Booking//RU"
+	uidDomain = "dormitory-booking"
+)
+
+// Event - одно событие календаря.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	Private     bool // CLASS:PRIVATE
+}
+
+// Calendar - набор событий в одном часовом поясе.
+type Calendar struct {
+	Name     string
+	Location *time.Location
+	Events   []Event
+}
+
+// FromBooking превращает бронь в событие. Бронь должна быть уже спроецирована
+// для зрителя ленты (appbooking.Project): скрывать подробности здесь некому.
+func FromBooking(b booking.Booking) Event {
+	return Event{
+		UID:         b.ID + "@" + uidDomain,
+		Summary:     b.Title,
+		Description: b.Description,
+		Location:    fmt.Sprintf("Комната %d", b.Room),
+		Start:       b.Start,
+		End:         b.End,
+		Private:     b.IsPrivate,
+	}
+}
+
+// Encode пишет календарь в w. Строки длиннее 75 октетов переносятся, как требует RFC 5545.
+func Encode(w io.Writer, cal Calendar) error {
+	loc := cal.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	bw := bufio.NewWriter(w)
+	lw := &lineWriter{w: bw}
+
+	lw.line("BEGIN:VCALENDAR")
+	lw.line("VERSION:2.0")
+	lw.line("PRODID:" + prodID)
+	lw.line("CALSCALE:GREGORIAN")
+	lw.line("METHOD:PUBLISH")
+	if cal.Name != "" {
+		lw.line("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+	if loc != time.UTC {
+		lw.line("X-WR-TIMEZONE:" + loc.String())
+		writeTimezone(lw, loc, cal.Events)
+	}
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, e := range cal.Events {
+		lw.line("BEGIN:VEVENT")
+		lw.line("UID:" + e.UID)
+		lw.line("DTSTAMP:" + stamp)
+		lw.line("DTSTART" + formatTime(e.Start, loc))
+		lw.line("DTEND" + formatTime(e.End, loc))
+		lw.line("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			lw.line("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if e.Location != "" {
+			lw.line("LOCATION:" + escapeText(e.Location))
+		}
+		if e.Private {
+			lw.line("CLASS:PRIVATE")
+		}
+		lw.line("END:VEVENT")
+	}
+	lw.line("END:VCALENDAR")
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return bw.Flush()
+}
+
+// formatTime - ";TZID=Europe/Moscow:20250314T190000" или ":20250314T160000Z" для UTC.
+func formatTime(t time.Time, loc *time.Location) string {
+	if loc == time.UTC {
+		return ":" + t.UTC().Format("20060102T150405Z")
+	}
+	return ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+// escapeText экранирует TEXT-значение: обратный слэш, точку с запятой, запятую и переводы строк.
+func escapeText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// lineWriter пишет строки с CRLF и переносит их по 75 октетов, не разрывая UTF-8 символы.
+type lineWriter struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (lw *lineWriter) line(s string) {
+	if lw.err != nil {
+		return
+	}
+	const limit = 75
+	first := true
+	for len(s) > 0 {
+		max := limit
+		if !first {
+			max = limit - 1 // продолжение начинается с пробела
+		}
+		cut := len(s)
+		if cut > max {
+			cut = max
+			for cut > 0 && !isRuneStart(s[cut]) {
+				cut--
+			}
+		}
+		if !first {
+			lw.write(" ")
+		}
+		lw.write(s[:cut] + "\r\n")
+		s = s[cut:]
+		first = false
+	}
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err == nil {
+		_, lw.err = lw.w.WriteString(s)
+	}
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
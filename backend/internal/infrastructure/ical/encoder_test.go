@@ -0,0 +1,88 @@
+package ical_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/ical"
+)
+
+func encode(t *testing.T, cal ical.Calendar) string {
+	t.Helper()
+	var sb strings.Builder
+	if err := ical.Encode(&sb, cal); err != nil {
+		t.Fatalf("Encode вернул ошибку: %v", err)
+	}
+	return sb.String()
+}
+
+func TestEncode_MoscowTimezone(t *testing.T) {
+	msk, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("нет базы часовых поясов: %v", err)
+	}
+
+	start := time.Date(2025, 3, 14, 16, 0, 0, 0, time.UTC) // 19:00 по Москве
+	b := booking.Booking{ID: "b1", Start: start, End: start.Add(2 * time.Hour), Room: booking.Room21, Title: "Настолки"}
+	out := encode(t, ical.Calendar{Location: msk, Events: []ical.Event{ical.FromBooking(b)}})
+
+	for _, want := range []string{
+		"BEGIN:VTIMEZONE\r\nTZID:Europe/Moscow\r\nBEGIN:STANDARD\r\n",
+		"TZOFFSETFROM:+0300\r\nTZOFFSETTO:+0300\r\nTZNAME:MSK\r\n",
+		"DTSTART;TZID=Europe/Moscow:20250314T190000\r\n",
+		"DTEND;TZID=Europe/Moscow:20250314T210000\r\n",
+		"UID:b1@dormitory-booking\r\n",
+		"SUMMARY:Настолки\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("в календаре нет %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DAYLIGHT") {
+		t.Fatalf("в Москве нет летнего времени:\n%s", out)
+	}
+}
+
+func TestEncode_DaylightTransitions(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("нет базы часовых поясов: %v", err)
+	}
+
+	start := time.Date(2025, 7, 1, 10, 0, 0, 0, berlin)
+	out := encode(t, ical.Calendar{Location: berlin, Events: []ical.Event{{UID: "x", Start: start, End: start.Add(time.Hour)}}})
+
+	// переход на летнее время 30 марта 2025 в 02:00 по зимнему времени
+	if !strings.Contains(out, "BEGIN:DAYLIGHT\r\nDTSTART:20250330T020000\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\n") {
+		t.Fatalf("не нашли переход на летнее время:\n%s", out)
+	}
+}
+
+func TestEncode_BusyAndEscaping(t *testing.T) {
+	start := time.Date(2025, 3, 14, 16, 0, 0, 0, time.UTC)
+	private := booking.Booking{ID: "p", Start: start, End: start.Add(time.Hour), Title: "ДР Пети", Description: "секрет", IsPrivate: true}
+	long := booking.Booking{ID: "l", Start: start, End: start.Add(time.Hour), Title: strings.Repeat("Очень длинное название; с запятой, ", 4)}
+
+	out := encode(t, ical.Calendar{Events: []ical.Event{ical.FromBooking(appbooking.Project(private, "", false)), ical.FromBooking(long)}})
+
+	if strings.Contains(out, "Пети") || strings.Contains(out, "секрет") {
+		t.Fatalf("частная бронь раскрыта:\n%s", out)
+	}
+	if !strings.Contains(out, "SUMMARY:"+booking.BusyTitle+"\r\n") || !strings.Contains(out, "CLASS:PRIVATE\r\n") {
+		t.Fatalf("частная бронь должна быть «Занято»:\n%s", out)
+	}
+	if !strings.Contains(out, `название\; с запятой\,`) {
+		t.Fatalf("текст не экранирован:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\r\n") {
+		if len(line) > 75 {
+			t.Fatalf("строка длиннее 75 октетов: %q", line)
+		}
+	}
+	if !strings.Contains(out, "DTSTART:20250314T160000Z\r\n") {
+		t.Fatalf("в UTC время пишется с Z:\n%s", out)
+	}
+}
@@ -0,0 +1,100 @@
+package ical
+
+// В этом файле блок VTIMEZONE. Правила пояса берём из базы Go: ищем переходы смещения
+// в интервале, который покрывают события, и описываем каждый отдельным STANDARD/DAYLIGHT.
+// Для Europe/Moscow с 2014 года это один STANDARD с +0300 без летнего времени.
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+type transition struct {
+	at         time.Time // момент перехода
+	offsetFrom int
+	offsetTo   int
+	name       string
+	dst        bool
+}
+
+func writeTimezone(lw *lineWriter, loc *time.Location, events []Event) {
+	from, to := eventRange(events)
+
+	lw.line("BEGIN:VTIMEZONE")
+	lw.line("TZID:" + loc.String())
+	for _, tr := range transitions(loc, from, to) {
+		kind := "STANDARD"
+		if tr.dst {
+			kind = "DAYLIGHT"
+		}
+		lw.line("BEGIN:" + kind)
+		// DTSTART - местное время перехода по старому смещению
+		lw.line("DTSTART:" + tr.at.In(time.FixedZone("", tr.offsetFrom)).Format("20060102T150405"))
+		lw.line("TZOFFSETFROM:" + formatOffset(tr.offsetFrom))
+		lw.line("TZOFFSETTO:" + formatOffset(tr.offsetTo))
+		if tr.name != "" {
+			lw.line("TZNAME:" + tr.name)
+		}
+		lw.line("END:" + kind)
+	}
+	lw.line("END:VTIMEZONE")
+}
+
+// eventRange - интервал, который покрывают события, с запасом в год назад.
+func eventRange(events []Event) (time.Time, time.Time) {
+	if len(events) == 0 {
+		now := time.Now()
+		return now.AddDate(-1, 0, 0), now
+	}
+	from, to := events[0].Start, events[0].End
+	for _, e := range events[1:] {
+		if e.Start.Before(from) {
+			from = e.Start
+		}
+		if e.End.After(to) {
+			to = e.End
+		}
+	}
+	return from.AddDate(-1, 0, 0), to
+}
+
+// transitions возвращает смещение, действующее на начало интервала, и все переходы внутри него.
+func transitions(loc *time.Location, from, to time.Time) []transition {
+	name, offset := from.In(loc).Zone()
+	out := []transition{{
+		at:         from,
+		offsetFrom: offset,
+		offsetTo:   offset,
+		name:       name,
+		dst:        from.In(loc).IsDST(),
+	}}
+
+	// переходы случаются не чаще раза в несколько месяцев, шага в сутки хватает
+	prev := offset
+	for day := from; day.Before(to); day = day.Add(24 * time.Hour) {
+		next := day.Add(24 * time.Hour)
+		if _, off := next.In(loc).Zone(); off == prev {
+			continue
+		}
+		// бинарный поиск момента смены смещения внутри суток
+		i := sort.Search(24*60, func(m int) bool {
+			_, off := day.Add(time.Duration(m+1) * time.Minute).In(loc).Zone()
+			return off != prev
+		})
+		at := day.Add(time.Duration(i+1) * time.Minute)
+		name, off := at.In(loc).Zone()
+		out = append(out, transition{at: at, offsetFrom: prev, offsetTo: off, name: name, dst: at.In(loc).IsDST()})
+		prev = off
+	}
+	return out
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d%02d", sign, seconds/3600, seconds/60%60)
+}
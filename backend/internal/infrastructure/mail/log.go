@@ -0,0 +1,45 @@
+package mail
+
+// В этом файле «почта» для разработки: письма пишутся в лог или дописываются в файл.
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	appauth "Dormitory_Booking/internal/application/auth"
+)
+
+// LogMailer печатает письма в лог. Если задан path - дописывает их в файл.
+type LogMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{path: path}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg appauth.Message) error {
+	text := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+
+	if m.path == "" {
+		log.Printf("письмо (dev mode):\n%s", text)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "--- %s\n%s\n", time.Now().Format(time.RFC3339), text)
+	return err
+}
@@ -0,0 +1,58 @@
+package memory
+
+// В этом файле in-memory журнал изменений броней.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/audit"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryAuditLog struct {
+	mu      sync.Mutex
+	entries []audit.Entry // в порядке добавления
+}
+
+func NewInMemoryAuditLog() *InMemoryAuditLog {
+	return &InMemoryAuditLog{}
+}
+
+func (l *InMemoryAuditLog) Append(ctx context.Context, e audit.Entry) (audit.Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	l.entries = append(l.entries, e)
+	return e, nil
+}
+
+func (l *InMemoryAuditLog) Find(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var out []audit.Entry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if f.Actor != "" && e.Actor != f.Actor ||
+			f.BookingID != "" && e.BookingID != f.BookingID ||
+			f.Action != "" && e.Action != f.Action ||
+			!f.From.IsZero() && e.At.Before(f.From) ||
+			!f.To.IsZero() && !e.At.Before(f.To) {
+			continue
+		}
+		out = append(out, e)
+		if f.Limit > 0 && len(out) == f.Limit {
+			break
+		}
+	}
+	return out, nil
+}
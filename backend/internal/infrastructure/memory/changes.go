@@ -0,0 +1,83 @@
+package memory
+
+// В этом файле журнал ревизий in-memory броней: выборка изменений и устаревание старых надгробий.
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+// Changes возвращает брони общежития, изменённые после ревизии since.
+func (r *InMemoryBookingRepo) Changes(ctx context.Context, since int64, limit int) (booking.ChangeSet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if since < 0 || since > r.revision || (since > 0 && since < r.horizonLocked()) {
+		return booking.ChangeSet{}, booking.ErrCursorExpired
+	}
+
+	out := make([]booking.Booking, 0)
+	for _, b := range r.bookings {
+		if b.Revision > since && r.owns(b) && !r.expiredLocked(b) {
+			out = append(out, b)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Revision < out[j].Revision })
+
+	set := booking.ChangeSet{Revision: since}
+	if limit > 0 && len(out) > limit {
+		out, set.More = out[:limit], true
+	}
+	if len(out) > 0 {
+		set.Revision = out[len(out)-1].Revision
+	}
+	set.Bookings = out
+	return set, nil
+}
+
+// ExpireTombstones поднимает горизонт общежитий до брони, отменённой раньше before.
+// Брони остаются в хранилище.
+func (r *InMemoryBookingRepo) ExpireTombstones(ctx context.Context, before time.Time) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	horizon := make(map[string]int64)
+	expired := 0
+	for _, b := range r.bookings {
+		if !r.owns(b) || !b.Cancelled() || b.CancelledAt == nil || !b.CancelledAt.Before(before) || r.expiredLocked(b) {
+			continue
+		}
+		if b.Revision > horizon[b.Dormitory] {
+			horizon[b.Dormitory] = b.Revision
+		}
+		expired++
+	}
+	for dorm, rev := range horizon {
+		r.horizon[dorm] = rev
+	}
+	return expired, nil
+}
+
+// expiredLocked - надгробие под горизонтом своего общежития, в журнал оно уже не попадает.
+// Вызывать под r.mu.
+func (r *InMemoryBookingRepo) expiredLocked(b booking.Booking) bool {
+	return b.Cancelled() && b.Revision <= r.horizon[b.Dormitory]
+}
+
+// horizonLocked - горизонт этого среза; у среза по всем общежитиям - самый высокий.
+// Вызывать под r.mu.
+func (r *InMemoryBookingRepo) horizonLocked() int64 {
+	if r.dorm != "" {
+		return r.horizon[r.dorm]
+	}
+	var h int64
+	for _, v := range r.horizon {
+		if v > h {
+			h = v
+		}
+	}
+	return h
+}
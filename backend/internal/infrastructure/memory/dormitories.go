@@ -0,0 +1,74 @@
+package memory
+
+// В этом файле лежит in-memory каталог общежитий.
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"Dormitory_Booking/internal/domain/dormitory"
+)
+
+type InMemoryDormitoryRepo struct {
+	mu    sync.RWMutex
+	dorms map[string]dormitory.Dormitory
+}
+
+// NewInMemoryDormitoryRepo создаёт каталог, засеянный переданными общежитиями.
+func NewInMemoryDormitoryRepo(seed ...dormitory.Dormitory) *InMemoryDormitoryRepo {
+	r := &InMemoryDormitoryRepo{
+		dorms: make(map[string]dormitory.Dormitory),
+	}
+	for _, d := range seed {
+		r.dorms[d.ID] = d
+	}
+	return r
+}
+
+// List возвращает общежития по алфавиту коротких имён.
+func (r *InMemoryDormitoryRepo) List(ctx context.Context) ([]dormitory.Dormitory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]dormitory.Dormitory, 0, len(r.dorms))
+	for _, d := range r.dorms {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+
+	return out, nil
+}
+
+func (r *InMemoryDormitoryRepo) Get(ctx context.Context, id string) (dormitory.Dormitory, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	d, ok := r.dorms[id]
+	if !ok {
+		return dormitory.Dormitory{}, dormitory.ErrNotFound
+	}
+	return d, nil
+}
+
+func (r *InMemoryDormitoryRepo) Create(ctx context.Context, d dormitory.Dormitory) (dormitory.Dormitory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.dorms[d.ID]; ok {
+		return dormitory.Dormitory{}, dormitory.ErrExists
+	}
+	r.dorms[d.ID] = d
+	return d, nil
+}
+
+func (r *InMemoryDormitoryRepo) Update(ctx context.Context, d dormitory.Dormitory) (dormitory.Dormitory, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.dorms[d.ID]; !ok {
+		return dormitory.Dormitory{}, dormitory.ErrNotFound
+	}
+	r.dorms[d.ID] = d
+	return d, nil
+}
@@ -0,0 +1,58 @@
+package memory
+
+// В этом файле рассылка событий о бронях внутри одного процесса.
+
+import (
+	"context"
+	"sync"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+// eventBuffer - сколько событий подписчик может не прочитать, прежде чем его отключат.
+const eventBuffer = 64
+
+type EventBroker struct {
+	mu   sync.Mutex
+	subs map[chan booking.Event]struct{}
+}
+
+func NewEventBroker() *EventBroker {
+	return &EventBroker{subs: make(map[chan booking.Event]struct{})}
+}
+
+// Publish отдаёт событие всем подписчикам, не дожидаясь их. Подписчик с полным буфером
+// отключается: ждать его значит тормозить запись броней.
+func (b *EventBroker) Publish(ctx context.Context, e booking.Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+	return nil
+}
+
+func (b *EventBroker) Subscribe(ctx context.Context) <-chan booking.Event {
+	ch := make(chan booking.Event, eventBuffer)
+
+	b.mu.Lock()
+	b.subs[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}()
+	return ch
+}
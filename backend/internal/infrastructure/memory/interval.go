@@ -0,0 +1,135 @@
+package memory
+
+// В этом файле лежит индекс интервалов для выборки броней по диапазону времени.
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+// intervalIndex - дерево интервалов: декартово дерево (treap) по началу брони и ID,
+// в каждом узле maxEnd - самый поздний конец в его поддереве. Запрос по диапазону
+// не обходит ветки, которые целиком раньше или позже него, а вставка и удаление
+// стоят O(log n) в среднем - индекс не пересобирается при каждой записи.
+type intervalIndex struct {
+	root *intervalNode
+}
+
+type intervalNode struct {
+	b           booking.Booking
+	maxEnd      time.Time
+	prio        uint64
+	left, right *intervalNode
+}
+
+// insert добавляет бронь. Бронь с тем же ID и началом должна быть сначала удалена.
+func (ix *intervalIndex) insert(b booking.Booking) {
+	l, r := split(ix.root, b)
+	n := &intervalNode{b: b, maxEnd: b.End, prio: rand.Uint64()}
+	ix.root = merge(merge(l, n), r)
+}
+
+// remove удаляет бронь, найденную по началу и ID; если её нет, ничего не делает.
+func (ix *intervalIndex) remove(b booking.Booking) {
+	ix.root = removeNode(ix.root, b)
+}
+
+// query обходит брони, пересекающиеся с [from, to), в порядке начала.
+// Нулевые from/to означают открытую границу.
+func (ix *intervalIndex) query(from, to time.Time, visit func(b booking.Booking)) {
+	ix.root.query(from, to, visit)
+}
+
+func (n *intervalNode) query(from, to time.Time, visit func(b booking.Booking)) {
+	if n == nil {
+		return
+	}
+	// всё поддерево закончилось до начала диапазона
+	if !from.IsZero() && !n.maxEnd.After(from) {
+		return
+	}
+
+	n.left.query(from, to, visit)
+
+	// n и всё правее начинаются не раньше конца диапазона
+	if !to.IsZero() && !n.b.Start.Before(to) {
+		return
+	}
+	if from.IsZero() || n.b.End.After(from) {
+		visit(n.b)
+	}
+
+	n.right.query(from, to, visit)
+}
+
+// before - порядок в дереве: по началу, при равном начале по ID.
+func before(a, b booking.Booking) bool {
+	if a.Start.Equal(b.Start) {
+		return a.ID < b.ID
+	}
+	return a.Start.Before(b.Start)
+}
+
+// split делит дерево на брони строго раньше b и все остальные.
+func split(n *intervalNode, b booking.Booking) (*intervalNode, *intervalNode) {
+	if n == nil {
+		return nil, nil
+	}
+	if before(n.b, b) {
+		l, r := split(n.right, b)
+		n.right = l
+		n.fix()
+		return n, r
+	}
+	l, r := split(n.left, b)
+	n.left = r
+	n.fix()
+	return l, n
+}
+
+// merge склеивает два дерева, если все брони l раньше всех броней r.
+func merge(l, r *intervalNode) *intervalNode {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.prio > r.prio:
+		l.right = merge(l.right, r)
+		l.fix()
+		return l
+	default:
+		r.left = merge(l, r.left)
+		r.fix()
+		return r
+	}
+}
+
+func removeNode(n *intervalNode, b booking.Booking) *intervalNode {
+	if n == nil {
+		return nil
+	}
+	switch {
+	case n.b.ID == b.ID && n.b.Start.Equal(b.Start):
+		return merge(n.left, n.right)
+	case before(b, n.b):
+		n.left = removeNode(n.left, b)
+	default:
+		n.right = removeNode(n.right, b)
+	}
+	n.fix()
+	return n
+}
+
+// fix пересчитывает maxEnd узла по его детям.
+func (n *intervalNode) fix() {
+	n.maxEnd = n.b.End
+	if n.left != nil && n.left.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.left.maxEnd
+	}
+	if n.right != nil && n.right.maxEnd.After(n.maxEnd) {
+		n.maxEnd = n.right.maxEnd
+	}
+}
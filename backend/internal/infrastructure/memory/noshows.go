@@ -0,0 +1,62 @@
+package memory
+
+// В этом файле in-memory учёт неявок.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryNoShowRepo struct {
+	mu      sync.RWMutex
+	noShows []booking.NoShow
+}
+
+func NewInMemoryNoShowRepo() *InMemoryNoShowRepo {
+	return &InMemoryNoShowRepo{}
+}
+
+// Record записывает неявку; повторная запись той же брони ничего не меняет.
+func (r *InMemoryNoShowRepo) Record(ctx context.Context, n booking.NoShow) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.noShows {
+		if existing.BookingID == n.BookingID {
+			return nil
+		}
+	}
+	if n.ID == "" {
+		n.ID = uuid.NewString()
+	}
+	if n.RecordedAt.IsZero() {
+		n.RecordedAt = time.Now()
+	}
+	r.noShows = append(r.noShows, n)
+	return nil
+}
+
+func (r *InMemoryNoShowRepo) Find(ctx context.Context, f booking.NoShowFilter) ([]booking.NoShow, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var out []booking.NoShow
+	for _, n := range r.noShows {
+		if f.Owner != "" && n.TelegramID != f.Owner {
+			continue
+		}
+		if !f.From.IsZero() && n.Start.Before(f.From) {
+			continue
+		}
+		if !f.To.IsZero() && !n.Start.Before(f.To) {
+			continue
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -0,0 +1,134 @@
+package memory
+
+// В этом файле лежат in-memory outbox уведомлений и настройки уведомлений.
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/notification"
+
+	"github.com/google/uuid"
+)
+
+type outboxEntry struct {
+	msg          notification.Message
+	claimedUntil time.Time
+	sent         bool
+	dead         bool
+}
+
+type InMemoryOutbox struct {
+	mu      sync.Mutex
+	entries map[string]*outboxEntry // по ID
+	keys    map[string]bool         // DedupKey, в том числе уже отправленных
+}
+
+func NewInMemoryOutbox() *InMemoryOutbox {
+	return &InMemoryOutbox{
+		entries: make(map[string]*outboxEntry),
+		keys:    make(map[string]bool),
+	}
+}
+
+func (o *InMemoryOutbox) Enqueue(ctx context.Context, msgs ...notification.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, m := range msgs {
+		if o.keys[m.DedupKey] {
+			continue
+		}
+		if m.ID == "" {
+			m.ID = uuid.NewString()
+		}
+		o.keys[m.DedupKey] = true
+		o.entries[m.ID] = &outboxEntry{msg: m}
+	}
+	return nil
+}
+
+func (o *InMemoryOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]notification.Message, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var due []*outboxEntry
+	for _, e := range o.entries {
+		if e.sent || e.dead || e.msg.SendAfter.After(now) || e.claimedUntil.After(now) {
+			continue
+		}
+		due = append(due, e)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].msg.SendAfter.Before(due[j].msg.SendAfter) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	out := make([]notification.Message, 0, len(due))
+	for _, e := range due {
+		e.claimedUntil = now.Add(lease)
+		out = append(out, e.msg)
+	}
+	return out, nil
+}
+
+func (o *InMemoryOutbox) MarkSent(ctx context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if e, ok := o.entries[id]; ok {
+		e.sent = true
+	}
+	return nil
+}
+
+func (o *InMemoryOutbox) MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	e, ok := o.entries[id]
+	if !ok {
+		return nil
+	}
+	e.msg.Attempts++
+	e.msg.LastError = reason
+	e.claimedUntil = time.Time{}
+	if retryAt.IsZero() {
+		e.dead = true
+	} else {
+		e.msg.SendAfter = retryAt
+	}
+	return nil
+}
+
+type InMemoryPreferencesRepo struct {
+	mu    sync.RWMutex
+	prefs map[string]notification.Preferences
+}
+
+func NewInMemoryPreferencesRepo() *InMemoryPreferencesRepo {
+	return &InMemoryPreferencesRepo{
+		prefs: make(map[string]notification.Preferences),
+	}
+}
+
+func (r *InMemoryPreferencesRepo) Get(ctx context.Context, userID string) (notification.Preferences, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.prefs[userID]
+	if !ok {
+		return notification.Preferences{}, notification.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *InMemoryPreferencesRepo) Save(ctx context.Context, p notification.Preferences) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.prefs[p.UserID] = p
+	return nil
+}
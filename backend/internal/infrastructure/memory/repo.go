@@ -1,47 +1,110 @@
 package memory
 
 // В этом файле лежит in-memory репозиторий для бронирований.
+// Брони всех общежитий лежат в одном хранилище, а репозиторий - его срез по одному
+// общежитию, как строки таблицы bookings с dormitory_id в Postgres.
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
 	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
 
 	"github.com/google/uuid"
 )
 
 type InMemoryBookingRepo struct {
+	*bookingStore
+	dorm string // "" - все общежития сразу, так репозиторий только читают
+}
+
+type bookingStore struct {
 	mu       sync.RWMutex
 	bookings map[string]booking.Booking
+	index    intervalIndex    // обновляется в put вместе с bookings
+	revision int64            // последняя выданная ревизия, общая для всех общежитий
+	horizon  map[string]int64 // ревизия последнего устаревшего надгробия по общежитиям
+
+	locksMu sync.Mutex
+	locks   map[lockKey]*sync.Mutex // аналог advisory lock по комнате и владельцу в Postgres
+}
+
+// lockKey - комната или владелец в общежитии: номера и ники в разных общежитиях совпадают.
+type lockKey struct {
+	dorm  string
+	room  booking.Room
+	owner string
 }
 
+// NewInMemoryBookingRepo создаёт хранилище и возвращает его срез по общежитию по умолчанию.
 func NewInMemoryBookingRepo() *InMemoryBookingRepo {
-	return &InMemoryBookingRepo{
+	store := &bookingStore{
 		bookings: make(map[string]booking.Booking),
+		horizon:  make(map[string]int64),
+		locks:    make(map[lockKey]*sync.Mutex),
+	}
+	return &InMemoryBookingRepo{bookingStore: store, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - срез того же хранилища по другому общежитию. Пустой id - все общежития
+// (для рассылки напоминаний); создавать брони через такой срез можно только с заданным Dormitory.
+func (r *InMemoryBookingRepo) ForDormitory(id string) *InMemoryBookingRepo {
+	return &InMemoryBookingRepo{bookingStore: r.bookingStore, dorm: id}
+}
+
+// owns - видна ли бронь из этого среза.
+func (r *InMemoryBookingRepo) owns(b booking.Booking) bool {
+	return r.dorm == "" || b.Dormitory == r.dorm
+}
+
+// get - бронь по ID, если она из этого общежития. Вызывать под r.mu.
+func (r *InMemoryBookingRepo) get(id string) (booking.Booking, bool) {
+	b, ok := r.bookings[id]
+	if !ok || !r.owns(b) {
+		return booking.Booking{}, false
 	}
+	return b, true
 }
 
-// List возвращает все брони.
+// List возвращает все неотменённые брони.
 func (r *InMemoryBookingRepo) List(ctx context.Context) ([]booking.Booking, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	out := make([]booking.Booking, 0, len(r.bookings))
 	for _, b := range r.bookings {
-		out = append(out, b)
+		if !b.Cancelled() && r.owns(b) {
+			out = append(out, b)
+		}
 	}
 
 	return out, nil
 }
 
+// Find возвращает брони под фильтр, отсортированные по началу.
+func (r *InMemoryBookingRepo) Find(ctx context.Context, f booking.ListFilter) ([]booking.Booking, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]booking.Booking, 0)
+	r.index.query(f.From, f.To, func(b booking.Booking) {
+		if f.Matches(b) && r.owns(b) {
+			out = append(out, b)
+		}
+	})
+
+	return out, nil
+}
+
 // Get возвращает бронь по ID.
 func (r *InMemoryBookingRepo) Get(ctx context.Context, id string) (booking.Booking, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	b, ok := r.bookings[id]
+	b, ok := r.get(id)
 	if !ok {
 		return booking.Booking{}, booking.ErrNotFound
 	}
@@ -62,19 +125,164 @@ func (r *InMemoryBookingRepo) Create(ctx context.Context, b booking.Booking) (bo
 	if b.End.IsZero() {
 		b.End = b.Start.Add(time.Hour)
 	}
+	if b.Status == "" {
+		b.Status = booking.StatusActive
+	}
+	if r.dorm != "" {
+		b.Dormitory = r.dorm
+	} else if b.Dormitory == "" {
+		b.Dormitory = dormitory.DefaultID
+	}
+
+	if r.overlapsLocked(b) {
+		return booking.Booking{}, booking.ErrOverlap
+	}
 
-	r.bookings[b.ID] = b
+	r.put(&b)
 	return b, nil
 }
 
-// Delete удаляет бронь, если она существует.
-func (r *InMemoryBookingRepo) Delete(ctx context.Context, id string) error {
+// Update перезаписывает существующую бронь.
+func (r *InMemoryBookingRepo) Update(ctx context.Context, b booking.Booking) (booking.Booking, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if _, ok := r.bookings[id]; !ok {
-		return booking.ErrNotFound
+	old, ok := r.get(b.ID)
+	if !ok {
+		return booking.Booking{}, booking.ErrNotFound
+	}
+	b.Dormitory = old.Dormitory
+	if r.overlapsLocked(b) {
+		return booking.Booking{}, booking.ErrOverlap
 	}
-	delete(r.bookings, id)
-	return nil
+	r.put(&b)
+	return b, nil
+}
+
+// Cancel помечает бронь отменённой, сама запись остаётся.
+func (r *InMemoryBookingRepo) Cancel(ctx context.Context, id string, c booking.Cancellation) (booking.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.get(id)
+	if !ok {
+		return booking.Booking{}, booking.ErrNotFound
+	}
+	if b.Cancelled() {
+		return booking.Booking{}, booking.ErrCancelled
+	}
+	at := c.At
+	b.Status = booking.StatusCancelled
+	b.CancelledAt = &at
+	b.CancelledBy = c.By
+	b.CancelReason = c.Reason
+	r.put(&b)
+	return b, nil
+}
+
+// Restore снимает отмену, если время брони за это время никто не занял.
+func (r *InMemoryBookingRepo) Restore(ctx context.Context, id string) (booking.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.get(id)
+	if !ok {
+		return booking.Booking{}, booking.ErrNotFound
+	}
+	if !b.Cancelled() {
+		return booking.Booking{}, booking.ErrNotCancelled
+	}
+	if r.overlapsLocked(b) {
+		return booking.Booking{}, booking.ErrOverlap
+	}
+	b.Status = booking.StatusActive
+	b.CancelledAt = nil
+	b.CancelledBy = ""
+	b.CancelReason = ""
+	r.put(&b)
+	return b, nil
+}
+
+// CheckIn отмечает приход; у уже отмеченной брони время первой отметки не меняется.
+func (r *InMemoryBookingRepo) CheckIn(ctx context.Context, id string, at time.Time) (booking.Booking, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.get(id)
+	if !ok {
+		return booking.Booking{}, booking.ErrNotFound
+	}
+	if b.Cancelled() {
+		return booking.Booking{}, booking.ErrCancelled
+	}
+	if b.CheckedInAt == nil {
+		b.CheckedInAt = &at
+		r.put(&b)
+	}
+	return b, nil
+}
+
+// put записывает бронь со следующей ревизией. Вызывать под r.mu.
+func (r *InMemoryBookingRepo) put(b *booking.Booking) {
+	r.revision++
+	b.Revision = r.revision
+	if old, ok := r.bookings[b.ID]; ok {
+		r.index.remove(old)
+	}
+	r.bookings[b.ID] = *b
+	r.index.insert(*b)
+}
+
+// WithRoomLock выполняет fn, удерживая мьютексы комнат и владельцев общежития. Сначала
+// комнаты по возрастанию номера, потом владельцы по алфавиту, чтобы два вызова
+// с разным порядком не взаимоблокировались.
+func (r *InMemoryBookingRepo) WithRoomLock(ctx context.Context, rooms []booking.Room, owners []string, fn func(repo booking.Repository) error) error {
+	sorted := append([]booking.Room(nil), rooms...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	for i, room := range sorted {
+		if i > 0 && room == sorted[i-1] {
+			continue
+		}
+		l := r.lock(lockKey{dorm: r.dorm, room: room})
+		l.Lock()
+		defer l.Unlock()
+	}
+
+	names := append([]string(nil), owners...)
+	sort.Strings(names)
+	for i, owner := range names {
+		if owner == "" || (i > 0 && owner == names[i-1]) {
+			continue
+		}
+		l := r.lock(lockKey{dorm: r.dorm, owner: owner})
+		l.Lock()
+		defer l.Unlock()
+	}
+
+	return fn(r)
+}
+
+func (r *InMemoryBookingRepo) lock(key lockKey) *sync.Mutex {
+	r.locksMu.Lock()
+	defer r.locksMu.Unlock()
+
+	l, ok := r.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		r.locks[key] = l
+	}
+	return l
+}
+
+// overlapsLocked - то же, что exclusion constraint room_time_no_overlap в Postgres:
+// пересекаться нельзя только в той же комнате того же общежития, отменённые брони
+// время не занимают. Вызывать под r.mu.
+func (r *InMemoryBookingRepo) overlapsLocked(b booking.Booking) bool {
+	overlaps := false
+	r.index.query(b.Start, b.End, func(e booking.Booking) {
+		if e.Room == b.Room && e.Dormitory == b.Dormitory && e.ID != b.ID && !e.Cancelled() {
+			overlaps = true
+		}
+	})
+	return overlaps
 }
@@ -3,6 +3,7 @@ package memory_test
 import (
 	"context"
 	"errors"
+	"math/rand/v2"
 	"testing"
 	"time"
 
@@ -41,21 +42,50 @@ func TestMemoryRepo_CreateAndGet(t *testing.T) {
 	}
 }
 
-func TestMemoryRepo_Delete(t *testing.T) {
+func TestMemoryRepo_CancelAndRestore(t *testing.T) {
 	r := memory.NewInMemoryBookingRepo()
 	ctx := context.Background()
 
-	b := newBooking()
-	created, _ := r.Create(ctx, b)
+	created, _ := r.Create(ctx, newBooking())
 
-	err := r.Delete(ctx, created.ID)
+	cancelled, err := r.Cancel(ctx, created.ID, booking.Cancellation{At: time.Now(), By: "owner", Reason: "заболел"})
 	if err != nil {
-		t.Fatalf("неожиданная ошибка при удалении: %v", err)
+		t.Fatalf("неожиданная ошибка при отмене: %v", err)
+	}
+	if !cancelled.Cancelled() || cancelled.CancelReason != "заболел" || cancelled.CancelledBy != "owner" {
+		t.Fatalf("отмена не записалась: %+v", cancelled)
+	}
+	if _, err := r.Cancel(ctx, created.ID, booking.Cancellation{At: time.Now()}); !errors.Is(err, booking.ErrCancelled) {
+		t.Fatalf("ожидалось booking.ErrCancelled, получили %v", err)
 	}
 
-	_, err = r.Get(ctx, created.ID)
-	if !errors.Is(err, booking.ErrNotFound) {
-		t.Fatalf("ожидалось booking.ErrNotFound, получили %v", err)
+	// в истории бронь есть, в выборках - нет
+	if got, err := r.Get(ctx, created.ID); err != nil || !got.Cancelled() {
+		t.Fatalf("отменённая бронь должна читаться по ID: %+v, %v", got, err)
+	}
+	if list, _ := r.Find(ctx, booking.ListFilter{}); len(list) != 0 {
+		t.Fatalf("отменённая бронь попала в выборку: %+v", list)
+	}
+	if list, _ := r.Find(ctx, booking.ListFilter{IncludeCancelled: true}); len(list) != 1 {
+		t.Fatalf("IncludeCancelled должен вернуть отменённую бронь, получили %+v", list)
+	}
+
+	// время освободилось
+	taken, err := r.Create(ctx, newBooking())
+	if err != nil {
+		t.Fatalf("время отменённой брони должно быть свободно: %v", err)
+	}
+	if _, err := r.Restore(ctx, created.ID); !errors.Is(err, booking.ErrOverlap) {
+		t.Fatalf("ожидалось booking.ErrOverlap, получили %v", err)
+	}
+
+	r.Cancel(ctx, taken.ID, booking.Cancellation{At: time.Now()})
+	restored, err := r.Restore(ctx, created.ID)
+	if err != nil || restored.Cancelled() || restored.CancelledAt != nil {
+		t.Fatalf("бронь не восстановилась: %+v, %v", restored, err)
+	}
+	if _, err := r.Restore(ctx, created.ID); !errors.Is(err, booking.ErrNotCancelled) {
+		t.Fatalf("ожидалось booking.ErrNotCancelled, получили %v", err)
 	}
 }
 
@@ -63,8 +93,13 @@ func TestMemoryRepo_List(t *testing.T) {
 	r := memory.NewInMemoryBookingRepo()
 	ctx := context.Background()
 
-	r.Create(ctx, newBooking())
-	r.Create(ctx, newBooking())
+	first := newBooking()
+	second := newBooking()
+	second.Start = first.End
+	second.End = first.End.Add(time.Hour)
+
+	r.Create(ctx, first)
+	r.Create(ctx, second)
 
 	list, err := r.List(ctx)
 	if err != nil {
@@ -75,3 +110,312 @@ func TestMemoryRepo_List(t *testing.T) {
 		t.Fatalf("ожидалось 2, получили %d", len(list))
 	}
 }
+
+func TestMemoryRepo_Update(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+
+	created, _ := r.Create(ctx, newBooking())
+	created.Title = "Updated"
+
+	if _, err := r.Update(ctx, created); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	got, _ := r.Get(ctx, created.ID)
+	if got.Title != "Updated" {
+		t.Fatalf("ожидался заголовок Updated, получили %s", got.Title)
+	}
+
+	if _, err := r.Update(ctx, booking.Booking{ID: "missing"}); !errors.Is(err, booking.ErrNotFound) {
+		t.Fatalf("ожидалось booking.ErrNotFound, получили %v", err)
+	}
+}
+
+func TestMemoryRepo_FindByRange(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+
+	base := time.Date(2099, 3, 2, 6, 0, 0, 0, time.UTC)
+	// 200 броней по часу подряд в двух комнатах: проверяем дерево интервалов против перебора
+	for i := 0; i < 200; i++ {
+		room := booking.Room21
+		if i%2 == 1 {
+			room = booking.Room132
+		}
+		start := base.Add(time.Duration(i) * time.Hour)
+		r.Create(ctx, booking.Booking{
+			Start:      start,
+			End:        start.Add(time.Hour + 30*time.Minute),
+			Room:       room,
+			Title:      "B",
+			TelegramID: "123",
+			IsPrivate:  i%3 == 0,
+		})
+	}
+
+	all, _ := r.List(ctx)
+	isPrivate := true
+	filters := []booking.ListFilter{
+		{},
+		{From: base.Add(10 * time.Hour), To: base.Add(20 * time.Hour)},
+		{From: base.Add(10*time.Hour + 45*time.Minute), To: base.Add(11 * time.Hour)},
+		{From: base.Add(150 * time.Hour)},
+		{To: base.Add(3 * time.Hour), Room: booking.Room132},
+		{From: base.Add(50 * time.Hour), To: base.Add(90 * time.Hour), IsPrivate: &isPrivate},
+	}
+
+	for _, f := range filters {
+		want := 0
+		for _, b := range all {
+			if f.Matches(b) {
+				want++
+			}
+		}
+
+		got, err := r.Find(ctx, f)
+		if err != nil {
+			t.Fatalf("неожиданная ошибка: %v", err)
+		}
+		if len(got) != want {
+			t.Fatalf("фильтр %+v: ожидалось %d, получили %d", f, want, len(got))
+		}
+		for i := 1; i < len(got); i++ {
+			if got[i].Start.Before(got[i-1].Start) {
+				t.Fatalf("результат должен быть отсортирован по началу")
+			}
+		}
+	}
+}
+
+func TestMemoryRepo_OverlapGuard(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+
+	base := newBooking()
+	if _, err := r.Create(ctx, base); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	overlap := newBooking()
+	overlap.Start = base.Start.Add(30 * time.Minute)
+	overlap.End = base.End.Add(30 * time.Minute)
+	if _, err := r.Create(ctx, overlap); !errors.Is(err, booking.ErrOverlap) {
+		t.Fatalf("ожидалось booking.ErrOverlap, получили %v", err)
+	}
+
+	overlap.Room = booking.Room132
+	if _, err := r.Create(ctx, overlap); err != nil {
+		t.Fatalf("в другой комнате пересечения нет, получили %v", err)
+	}
+}
+
+func TestMemoryRoomRepo_CRUD(t *testing.T) {
+	r := memory.NewInMemoryRoomRepo(booking.DefaultRooms()...)
+	ctx := context.Background()
+
+	if _, err := r.Create(ctx, booking.RoomInfo{Number: booking.Room21}); !errors.Is(err, booking.ErrRoomExists) {
+		t.Fatalf("ожидалось booking.ErrRoomExists, получили %v", err)
+	}
+
+	room, err := r.Get(ctx, booking.Room132)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	room.Capacity = 20
+	if _, err := r.Update(ctx, room); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if err := r.Delete(ctx, booking.Room256); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	list, _ := r.List(ctx)
+	if len(list) != 2 || list[0].Number != booking.Room21 || list[1].Capacity != 20 {
+		t.Fatalf("неожиданный каталог: %+v", list)
+	}
+}
+
+func TestMemoryRepo_CheckIn(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+
+	created, _ := r.Create(ctx, newBooking())
+	first := time.Now()
+	checked, err := r.CheckIn(ctx, created.ID, first)
+	if err != nil || checked.CheckedInAt == nil {
+		t.Fatalf("CheckIn: %+v, %v", checked, err)
+	}
+	if again, _ := r.CheckIn(ctx, created.ID, first.Add(time.Minute)); !again.CheckedInAt.Equal(first) {
+		t.Fatalf("повторная отметка изменила время: %v", again.CheckedInAt)
+	}
+	if list, _ := r.Find(ctx, booking.ListFilter{}); len(list) != 1 || list[0].CheckedInAt == nil {
+		t.Fatalf("отметка должна быть видна в выборках: %+v", list)
+	}
+
+	r.Cancel(ctx, created.ID, booking.Cancellation{At: time.Now()})
+	if _, err := r.CheckIn(ctx, created.ID, time.Now()); !errors.Is(err, booking.ErrCancelled) {
+		t.Fatalf("ожидалось booking.ErrCancelled, получили %v", err)
+	}
+}
+
+func TestMemoryRepo_DormitoryIsolation(t *testing.T) {
+	main := memory.NewInMemoryBookingRepo()
+	east := main.ForDormitory("east")
+	ctx := context.Background()
+
+	b := newBooking()
+	mb, err := main.Create(ctx, b)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	eb, err := east.Create(ctx, b)
+	if err != nil {
+		t.Fatalf("та же комната в другом общежитии: неожиданная ошибка: %v", err)
+	}
+	if eb.Dormitory != "east" {
+		t.Fatalf("ожидали общежитие east, получили %q", eb.Dormitory)
+	}
+	if _, err := east.Create(ctx, b); !errors.Is(err, booking.ErrOverlap) {
+		t.Fatalf("ожидали ErrOverlap в том же общежитии, получили %v", err)
+	}
+
+	if _, err := east.Get(ctx, mb.ID); !errors.Is(err, booking.ErrNotFound) {
+		t.Fatalf("бронь main через east: ожидали ErrNotFound, получили %v", err)
+	}
+	if _, err := east.Cancel(ctx, mb.ID, booking.Cancellation{At: time.Now()}); !errors.Is(err, booking.ErrNotFound) {
+		t.Fatalf("отмена брони main через east: ожидали ErrNotFound, получили %v", err)
+	}
+
+	list, _ := east.List(ctx)
+	if len(list) != 1 || list[0].ID != eb.ID {
+		t.Fatalf("east видит чужие брони: %+v", list)
+	}
+	all, _ := main.ForDormitory("").List(ctx)
+	if len(all) != 2 {
+		t.Fatalf("срез по всем общежитиям: ожидали 2 брони, получили %d", len(all))
+	}
+}
+
+func TestMemoryRepo_Changes(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+
+	a, _ := r.Create(ctx, newBooking())
+	b := newBooking()
+	b.Room = booking.Room132
+	b, _ = r.Create(ctx, b)
+	if a.Revision == 0 || b.Revision <= a.Revision {
+		t.Fatalf("ревизии должны расти: %d, %d", a.Revision, b.Revision)
+	}
+
+	set, err := r.Changes(ctx, 0, 0)
+	if err != nil || len(set.Bookings) != 2 || set.Revision != b.Revision || set.More {
+		t.Fatalf("все изменения: %+v %v", set, err)
+	}
+	page, _ := r.Changes(ctx, 0, 1)
+	if len(page.Bookings) != 1 || page.Bookings[0].ID != a.ID || !page.More || page.Revision != a.Revision {
+		t.Fatalf("первая страница: %+v", page)
+	}
+
+	// отмена - тоже изменение, бронь приходит надгробием
+	cursor := set.Revision
+	if _, err := r.Cancel(ctx, a.ID, booking.Cancellation{At: time.Now()}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	set, _ = r.Changes(ctx, cursor, 0)
+	if len(set.Bookings) != 1 || set.Bookings[0].ID != a.ID || !set.Bookings[0].Cancelled() {
+		t.Fatalf("ожидали надгробие брони %s, получили %+v", a.ID, set.Bookings)
+	}
+	if empty, _ := r.Changes(ctx, set.Revision, 0); len(empty.Bookings) != 0 || empty.Revision != set.Revision {
+		t.Fatalf("без изменений курсор не должен двигаться: %+v", empty)
+	}
+
+	if _, err := r.Changes(ctx, set.Revision+1, 0); !errors.Is(err, booking.ErrCursorExpired) {
+		t.Fatalf("курсор из будущего: ожидали ErrCursorExpired, получили %v", err)
+	}
+
+	// когда надгробие устарело, старый курсор уже не восстановить
+	if n, _ := r.ExpireTombstones(ctx, time.Now().Add(time.Minute)); n != 1 {
+		t.Fatalf("ожидали 1 устаревшее надгробие, получили %d", n)
+	}
+	if n, _ := r.ExpireTombstones(ctx, time.Now().Add(time.Minute)); n != 0 {
+		t.Fatalf("повторный запуск не должен находить новых надгробий, получили %d", n)
+	}
+	if _, err := r.Changes(ctx, cursor, 0); !errors.Is(err, booking.ErrCursorExpired) {
+		t.Fatalf("курсор ниже горизонта: ожидали ErrCursorExpired, получили %v", err)
+	}
+	if set, err := r.Changes(ctx, 0, 0); err != nil || len(set.Bookings) != 1 || set.Bookings[0].ID != b.ID {
+		t.Fatalf("полная синхронизация: %+v %v", set, err)
+	}
+	// сама отменённая бронь остаётся для истории и статистики
+	if got, err := r.Get(ctx, a.ID); err != nil || !got.Cancelled() {
+		t.Fatalf("отменённая бронь должна сохраниться: %+v %v", got, err)
+	}
+	if other, err := r.ForDormitory("east").Changes(ctx, cursor, 0); err != nil || len(other.Bookings) != 0 {
+		t.Fatalf("горизонт и изменения другого общежития: %+v %v", other, err)
+	}
+}
+
+// Индекс интервалов обновляется при каждой записи; выборка должна совпадать с полным перебором.
+func TestMemoryRepo_FindMatchesScan(t *testing.T) {
+	r := memory.NewInMemoryBookingRepo()
+	ctx := context.Background()
+	rnd := rand.New(rand.NewPCG(1, 2))
+	base := time.Date(2099, 1, 5, 0, 0, 0, 0, time.UTC)
+	slot := func(n int) time.Time { return base.Add(time.Duration(n) * 15 * time.Minute) }
+
+	var ids []string
+	for i := 0; i < 400; i++ {
+		start := rnd.IntN(500)
+		b, err := r.Create(ctx, booking.Booking{
+			Start: slot(start), End: slot(start + 1 + rnd.IntN(8)),
+			Room: booking.Room(1 + rnd.IntN(40)), Title: "Случайная", TelegramID: "rnd",
+		})
+		if err == nil {
+			ids = append(ids, b.ID)
+		}
+	}
+	for i := 0; i < 300; i++ {
+		id := ids[rnd.IntN(len(ids))]
+		if rnd.IntN(3) == 0 {
+			_, _ = r.Cancel(ctx, id, booking.Cancellation{At: time.Now()})
+			continue
+		}
+		b, err := r.Get(ctx, id)
+		if err != nil || b.Cancelled() {
+			continue
+		}
+		start := rnd.IntN(500)
+		b.Start, b.End = slot(start), slot(start+1+rnd.IntN(8))
+		_, _ = r.Update(ctx, b)
+	}
+
+	all := make([]booking.Booking, 0, len(ids))
+	for _, id := range ids {
+		b, err := r.Get(ctx, id)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		all = append(all, b)
+	}
+
+	for i := 0; i < 200; i++ {
+		from := slot(rnd.IntN(520))
+		to := from.Add(time.Duration(1+rnd.IntN(40)) * 15 * time.Minute)
+		got, err := r.Find(ctx, booking.ListFilter{From: from, To: to})
+		if err != nil {
+			t.Fatalf("Find: %v", err)
+		}
+		want := 0
+		for _, b := range all {
+			if !b.Cancelled() && b.Start.Before(to) && b.End.After(from) {
+				want++
+			}
+		}
+		if len(got) != want {
+			t.Fatalf("[%v, %v): индекс нашёл %d броней, перебор - %d", from, to, len(got), want)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package memory
+
+// В этом файле лежит in-memory каталог комнат.
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+type InMemoryRoomRepo struct {
+	mu    sync.RWMutex
+	rooms map[booking.Room]booking.RoomInfo
+}
+
+// NewInMemoryRoomRepo создаёт каталог, засеянный переданными комнатами.
+func NewInMemoryRoomRepo(seed ...booking.RoomInfo) *InMemoryRoomRepo {
+	r := &InMemoryRoomRepo{
+		rooms: make(map[booking.Room]booking.RoomInfo),
+	}
+	for _, room := range seed {
+		r.rooms[room.Number] = room
+	}
+	return r
+}
+
+// List возвращает все комнаты по возрастанию номера.
+func (r *InMemoryRoomRepo) List(ctx context.Context) ([]booking.RoomInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]booking.RoomInfo, 0, len(r.rooms))
+	for _, room := range r.rooms {
+		out = append(out, room)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Number < out[j].Number })
+
+	return out, nil
+}
+
+// Get возвращает комнату по номеру.
+func (r *InMemoryRoomRepo) Get(ctx context.Context, number booking.Room) (booking.RoomInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	room, ok := r.rooms[number]
+	if !ok {
+		return booking.RoomInfo{}, booking.ErrNotFound
+	}
+	return room, nil
+}
+
+// GetByCheckInToken возвращает комнату по токену из QR-кода.
+func (r *InMemoryRoomRepo) GetByCheckInToken(ctx context.Context, token string) (booking.RoomInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, room := range r.rooms {
+		if token != "" && room.CheckInToken == token {
+			return room, nil
+		}
+	}
+	return booking.RoomInfo{}, booking.ErrNotFound
+}
+
+// Create добавляет комнату, если такого номера ещё нет.
+func (r *InMemoryRoomRepo) Create(ctx context.Context, room booking.RoomInfo) (booking.RoomInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rooms[room.Number]; ok {
+		return booking.RoomInfo{}, booking.ErrRoomExists
+	}
+	r.rooms[room.Number] = room
+	return room, nil
+}
+
+// Update перезаписывает существующую комнату.
+func (r *InMemoryRoomRepo) Update(ctx context.Context, room booking.RoomInfo) (booking.RoomInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rooms[room.Number]; !ok {
+		return booking.RoomInfo{}, booking.ErrNotFound
+	}
+	r.rooms[room.Number] = room
+	return room, nil
+}
+
+// Delete удаляет комнату, если она существует.
+func (r *InMemoryRoomRepo) Delete(ctx context.Context, number booking.Room) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.rooms[number]; !ok {
+		return booking.ErrNotFound
+	}
+	delete(r.rooms, number)
+	return nil
+}
@@ -0,0 +1,76 @@
+package memory
+
+// В этом файле лежит in-memory хранилище серий бронирований.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+
+	"github.com/google/uuid"
+)
+
+type InMemorySeriesRepo struct {
+	mu     sync.RWMutex
+	series map[string]booking.Series
+}
+
+func NewInMemorySeriesRepo() *InMemorySeriesRepo {
+	return &InMemorySeriesRepo{
+		series: make(map[string]booking.Series),
+	}
+}
+
+// Create сохраняет серию. Если у серии нет ID, генерируем новый UUID.
+func (r *InMemorySeriesRepo) Create(ctx context.Context, s booking.Series) (booking.Series, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+	r.series[s.ID] = s
+	return s, nil
+}
+
+// Get возвращает серию по ID.
+func (r *InMemorySeriesRepo) Get(ctx context.Context, id string) (booking.Series, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.series[id]
+	if !ok {
+		return booking.Series{}, booking.ErrNotFound
+	}
+	return s, nil
+}
+
+// End помечает серию завершённой, если она ещё не завершена.
+func (r *InMemorySeriesRepo) End(ctx context.Context, id string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.series[id]
+	if !ok {
+		return booking.ErrNotFound
+	}
+	if s.EndedAt == nil {
+		s.EndedAt = &at
+		r.series[id] = s
+	}
+	return nil
+}
+
+// Delete удаляет серию, если она существует.
+func (r *InMemorySeriesRepo) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.series[id]; !ok {
+		return booking.ErrNotFound
+	}
+	delete(r.series, id)
+	return nil
+}
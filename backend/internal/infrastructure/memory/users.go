@@ -0,0 +1,222 @@
+package memory
+
+// В этом файле лежат in-memory хранилища пользователей и кодов входа.
+
+import (
+	"context"
+	"sync"
+
+	"Dormitory_Booking/internal/domain/user"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryUserRepo struct {
+	mu    sync.RWMutex
+	users map[string]user.User
+}
+
+func NewInMemoryUserRepo() *InMemoryUserRepo {
+	return &InMemoryUserRepo{
+		users: make(map[string]user.User),
+	}
+}
+
+func (r *InMemoryUserRepo) Get(ctx context.Context, id string) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *InMemoryUserRepo) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	return r.findBy(func(u user.User) bool { return u.Email == email })
+}
+
+func (r *InMemoryUserRepo) GetByTelegram(ctx context.Context, telegramID string) (user.User, error) {
+	if telegramID == "" {
+		return user.User{}, user.ErrNotFound
+	}
+	return r.findBy(func(u user.User) bool { return u.TelegramID == telegramID })
+}
+
+func (r *InMemoryUserRepo) GetByCalendarToken(ctx context.Context, token string) (user.User, error) {
+	if token == "" {
+		return user.User{}, user.ErrNotFound
+	}
+	return r.findBy(func(u user.User) bool { return u.CalendarToken == token })
+}
+
+// Create сохраняет пользователя. Если у пользователя нет ID, генерируем новый UUID.
+func (r *InMemoryUserRepo) Create(ctx context.Context, u user.User) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+	r.users[u.ID] = u
+	return u, nil
+}
+
+// Update перезаписывает пользователя. Telegram должен быть уникальным, как в Postgres.
+func (r *InMemoryUserRepo) Update(ctx context.Context, u user.User) (user.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.users[u.ID]; !ok {
+		return user.User{}, user.ErrNotFound
+	}
+	for _, other := range r.users {
+		if other.ID != u.ID && u.TelegramID != "" && other.TelegramID == u.TelegramID {
+			return user.User{}, user.ErrTelegramTaken
+		}
+	}
+	r.users[u.ID] = u
+	return u, nil
+}
+
+func (r *InMemoryUserRepo) findBy(match func(u user.User) bool) (user.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if match(u) {
+			return u, nil
+		}
+	}
+	return user.User{}, user.ErrNotFound
+}
+
+type InMemoryLoginCodeRepo struct {
+	mu    sync.Mutex
+	codes map[string]user.LoginCode
+}
+
+func NewInMemoryLoginCodeRepo() *InMemoryLoginCodeRepo {
+	return &InMemoryLoginCodeRepo{
+		codes: make(map[string]user.LoginCode),
+	}
+}
+
+func (r *InMemoryLoginCodeRepo) Save(ctx context.Context, c user.LoginCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.codes[c.Email] = c
+	return nil
+}
+
+func (r *InMemoryLoginCodeRepo) Get(ctx context.Context, email string) (user.LoginCode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c, ok := r.codes[email]
+	if !ok {
+		return user.LoginCode{}, user.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *InMemoryLoginCodeRepo) Delete(ctx context.Context, email string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.codes, email)
+	return nil
+}
+
+type InMemoryTelegramLinkRepo struct {
+	mu    sync.Mutex
+	links map[string]user.TelegramLink // по хэшу кода
+}
+
+func NewInMemoryTelegramLinkRepo() *InMemoryTelegramLinkRepo {
+	return &InMemoryTelegramLinkRepo{
+		links: make(map[string]user.TelegramLink),
+	}
+}
+
+// Save заменяет прежний запрос пользователя, как ON CONFLICT (user_id) в Postgres.
+func (r *InMemoryTelegramLinkRepo) Save(ctx context.Context, l user.TelegramLink) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, old := range r.links {
+		if old.UserID == l.UserID {
+			delete(r.links, hash)
+		}
+	}
+	r.links[l.CodeHash] = l
+	return nil
+}
+
+func (r *InMemoryTelegramLinkRepo) Take(ctx context.Context, codeHash string) (user.TelegramLink, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	l, ok := r.links[codeHash]
+	if !ok {
+		return user.TelegramLink{}, user.ErrNotFound
+	}
+	delete(r.links, codeHash)
+	return l, nil
+}
+
+type InMemorySessionRepo struct {
+	mu       sync.RWMutex
+	sessions map[string]user.Session
+}
+
+func NewInMemorySessionRepo() *InMemorySessionRepo {
+	return &InMemorySessionRepo{
+		sessions: make(map[string]user.Session),
+	}
+}
+
+func (r *InMemorySessionRepo) Create(ctx context.Context, s user.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sessions[s.ID] = s
+	return nil
+}
+
+func (r *InMemorySessionRepo) Get(ctx context.Context, id string) (user.Session, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.sessions[id]
+	if !ok {
+		return user.Session{}, user.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *InMemorySessionRepo) Revoke(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.sessions[id]; ok {
+		s.Revoked = true
+		r.sessions[id] = s
+	}
+	return nil
+}
+
+func (r *InMemorySessionRepo) RevokeAll(ctx context.Context, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, s := range r.sessions {
+		if s.UserID == userID {
+			s.Revoked = true
+			r.sessions[id] = s
+		}
+	}
+	return nil
+}
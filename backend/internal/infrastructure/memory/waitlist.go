@@ -0,0 +1,79 @@
+package memory
+
+// В этом файле in-memory очередь ожидания на занятое время.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+
+	"github.com/google/uuid"
+)
+
+type InMemoryWaitlistRepo struct {
+	mu      sync.RWMutex
+	entries []booking.WaitlistEntry // в порядке очереди
+}
+
+func NewInMemoryWaitlistRepo() *InMemoryWaitlistRepo {
+	return &InMemoryWaitlistRepo{}
+}
+
+// Add ставит заявку в конец очереди.
+func (r *InMemoryWaitlistRepo) Add(ctx context.Context, e booking.WaitlistEntry) (booking.WaitlistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.Status == "" {
+		e.Status = booking.WaitlistWaiting
+	}
+	r.entries = append(r.entries, e)
+	return e, nil
+}
+
+func (r *InMemoryWaitlistRepo) Get(ctx context.Context, id string) (booking.WaitlistEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, e := range r.entries {
+		if e.ID == id {
+			return e, nil
+		}
+	}
+	return booking.WaitlistEntry{}, booking.ErrWaitlistNotFound
+}
+
+func (r *InMemoryWaitlistRepo) Find(ctx context.Context, f booking.WaitlistFilter) ([]booking.WaitlistEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]booking.WaitlistEntry, 0)
+	for _, e := range r.entries {
+		if f.Matches(e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Update перезаписывает заявку, место в очереди не меняется.
+func (r *InMemoryWaitlistRepo) Update(ctx context.Context, e booking.WaitlistEntry) (booking.WaitlistEntry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.entries {
+		if r.entries[i].ID == e.ID {
+			r.entries[i] = e
+			return e, nil
+		}
+	}
+	return booking.WaitlistEntry{}, booking.ErrWaitlistNotFound
+}
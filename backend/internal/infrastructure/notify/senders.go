@@ -0,0 +1,140 @@
+package notify
+
+// В этом файле каналы доставки уведомлений: Telegram, почта и вебхук.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	appauth "Dormitory_Booking/internal/application/auth"
+	"Dormitory_Booking/internal/domain/notification"
+	"Dormitory_Booking/internal/infrastructure/telegram"
+)
+
+// TelegramSender пишет в личный чат с ботом. Recipient - числовой chat_id.
+type TelegramSender struct {
+	client *telegram.Client
+}
+
+func NewTelegramSender(client *telegram.Client) *TelegramSender {
+	return &TelegramSender{client: client}
+}
+
+func (s *TelegramSender) Send(ctx context.Context, m notification.Message) error {
+	chatID, err := strconv.ParseInt(m.Recipient, 10, 64)
+	if err != nil {
+		return fmt.Errorf("некорректный chat_id %q", m.Recipient)
+	}
+	return s.client.SendMessage(ctx, chatID, m.Body)
+}
+
+// EmailSender отправляет уведомление тем же почтовым клиентом, что и коды входа.
+type EmailSender struct {
+	mailer appauth.Mailer
+}
+
+func NewEmailSender(mailer appauth.Mailer) *EmailSender {
+	return &EmailSender{mailer: mailer}
+}
+
+func (s *EmailSender) Send(ctx context.Context, m notification.Message) error {
+	return s.mailer.Send(ctx, appauth.Message{To: m.Recipient, Subject: m.Subject, Body: m.Body})
+}
+
+// WebhookSender делает POST с JSON на адрес пользователя. Успех - любой 2xx.
+// Адрес задаёт пользователь, поэтому во внутреннюю сеть сервер не ходит: адрес
+// проверяется уже после разрешения имени, при каждом соединении (см. dialPublic),
+// а редиректы не выполняются - 3xx считается неудачей.
+type WebhookSender struct {
+	http *http.Client
+}
+
+func NewWebhookSender() *WebhookSender {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: dialPublic}
+	return &WebhookSender{http: &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			// без прокси из окружения: иначе проверялся бы адрес прокси, а не получателя
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// errPrivateTarget - вебхук смотрит во внутреннюю сеть.
+var errPrivateTarget = errors.New("webhook: адрес во внутренней сети запрещён")
+
+// cgnat - 100.64.0.0/10, общий адрес провайдеров; там же бывают сервисы метаданных облаков.
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// dialPublic вызывается для каждого адреса, к которому идёт соединение, уже после DNS,
+// так что имя, которое при проверке указывало наружу, а потом на 127.0.0.1, не пройдёт.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap()
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() || cgnat.Contains(ip) {
+		return errPrivateTarget
+	}
+	return nil
+}
+
+type webhookPayload struct {
+	ID        string `json:"id"` // один и тот же при повторных попытках, получатель может отсеять дубли
+	Kind      string `json:"kind"`
+	BookingID string `json:"bookingId"`
+	Subject   string `json:"subject"`
+	Text      string `json:"text"`
+}
+
+func (s *WebhookSender) Send(ctx context.Context, m notification.Message) error {
+	body, err := json.Marshal(webhookPayload{
+		ID:        m.ID,
+		Kind:      string(m.Kind),
+		BookingID: m.BookingID,
+		Subject:   m.Subject,
+		Text:      m.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.Recipient, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook: %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package notify_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"Dormitory_Booking/internal/domain/notification"
+	"Dormitory_Booking/internal/infrastructure/notify"
+)
+
+func TestWebhookSender_RejectsInternalTargets(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+	port := srv.URL[strings.LastIndex(srv.URL, ":"):]
+
+	sender := notify.NewWebhookSender()
+	for _, target := range []string{
+		srv.URL,
+		"http://localhost" + port,
+		"http://[::1]" + port,
+		"http://169.254.169.254/latest/meta-data/",
+		"http://10.0.0.1/",
+		"http://0.0.0.0" + port,
+	} {
+		err := sender.Send(context.Background(), notification.Message{ID: "1", Recipient: target, Body: "x"})
+		if err == nil || !strings.Contains(err.Error(), "внутренней сети") {
+			t.Fatalf("%s: вебхук во внутреннюю сеть должен отклоняться, получили %v", target, err)
+		}
+	}
+	if n := hits.Load(); n != 0 {
+		t.Fatalf("до внутреннего сервера дошло %d запросов", n)
+	}
+}
@@ -0,0 +1,103 @@
+package policyfile
+
+// В этом файле хранилище политики бронирования в YAML-файле.
+// Файл можно править руками: изменения подхватываются по времени модификации.
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Store struct {
+	path string
+
+	mu      sync.Mutex
+	cached  appbooking.Policy
+	modTime time.Time
+	loaded  bool
+}
+
+// NewStore создаёт хранилище поверх файла path. Если файла нет, действует appbooking.DefaultPolicy.
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Current возвращает политику из файла, перечитывая его, если он поменялся.
+func (s *Store) Current(ctx context.Context) (appbooking.Policy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	info, err := os.Stat(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return appbooking.DefaultPolicy(), nil
+		}
+		return appbooking.Policy{}, err
+	}
+	if s.loaded && info.ModTime().Equal(s.modTime) {
+		return s.cached, nil
+	}
+
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		return appbooking.Policy{}, err
+	}
+	var p appbooking.Policy
+	if err := yaml.Unmarshal(raw, &p); err != nil {
+		return appbooking.Policy{}, err
+	}
+	if err := p.Validate(); err != nil {
+		return appbooking.Policy{}, err
+	}
+
+	s.cached = p
+	s.modTime = info.ModTime()
+	s.loaded = true
+	return p, nil
+}
+
+// Save записывает новую версию в файл (через временный файл, чтобы не оставить его битым).
+func (s *Store) Save(ctx context.Context, p appbooking.Policy) (appbooking.Policy, error) {
+	current, err := s.Current(ctx)
+	if err != nil {
+		return appbooking.Policy{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p.Version = current.Version + 1
+	raw, err := yaml.Marshal(p)
+	if err != nil {
+		return appbooking.Policy{}, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".policy-*.yaml")
+	if err != nil {
+		return appbooking.Policy{}, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return appbooking.Policy{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return appbooking.Policy{}, err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return appbooking.Policy{}, err
+	}
+
+	s.loaded = false
+	return p, nil
+}
@@ -0,0 +1,83 @@
+package policyfile_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/policyfile"
+)
+
+func TestStore_MissingFileMeansDefault(t *testing.T) {
+	s := policyfile.NewStore(filepath.Join(t.TempDir(), "policy.yaml"))
+
+	p, err := s.Current(context.Background())
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if p.Default.PrivateDailyLimit != appbooking.DefaultPolicy().Default.PrivateDailyLimit {
+		t.Fatalf("без файла должна действовать политика по умолчанию, получили %+v", p)
+	}
+}
+
+func TestStore_ReadHandWrittenYAML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	yaml := `version: 7
+default:
+  maxPrivateMinutes: 120
+  privateDailyLimit: 2
+  privateEveningLimit: 1
+  eveningStartHour: 19
+  quietNights:
+    - weekday: 5
+      startHour: 22
+      hours: 8
+rooms:
+  256:
+    maxPrivateMinutes: 240
+    privateDailyLimit: 4
+    eveningStartHour: 18
+`
+	if err := os.WriteFile(path, []byte(yaml), 0o644); err != nil {
+		t.Fatalf("не смогли записать файл: %v", err)
+	}
+
+	p, err := policyfile.NewStore(path).Current(context.Background())
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if p.Version != 7 || p.Default.MaxPrivateMinutes != 120 || len(p.Default.QuietNights) != 1 || p.Default.QuietNights[0].Weekday != time.Friday {
+		t.Fatalf("файл прочитан неверно: %+v", p)
+	}
+	if p.For(domain.Room256).PrivateDailyLimit != 4 || p.For(domain.Room21).PrivateDailyLimit != 2 {
+		t.Fatalf("правила комнат прочитаны неверно: %+v", p.Rooms)
+	}
+}
+
+func TestStore_SaveBumpsVersion(t *testing.T) {
+	ctx := context.Background()
+	s := policyfile.NewStore(filepath.Join(t.TempDir(), "policy.yaml"))
+
+	p := appbooking.DefaultPolicy()
+	p.Default.PrivateDailyLimit = 5
+
+	saved, err := s.Save(ctx, p)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if saved.Version != 1 {
+		t.Fatalf("ожидали версию 1, получили %d", saved.Version)
+	}
+
+	got, err := s.Current(ctx)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Version != 1 || got.Default.PrivateDailyLimit != 5 {
+		t.Fatalf("после сохранения прочитали %+v", got)
+	}
+}
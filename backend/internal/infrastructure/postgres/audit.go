@@ -0,0 +1,140 @@
+package postgres
+
+// В этом файле журнал изменений броней (audit_log). Таблица только на добавление.
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"Dormitory_Booking/internal/domain/audit"
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AuditPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewAuditPostgresRepo создаёт журнал общежития по умолчанию поверх пула соединений pgx.
+func NewAuditPostgresRepo(pool *pgxpool.Pool) *AuditPostgresRepo {
+	return &AuditPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - журнал другого общежития.
+func (r *AuditPostgresRepo) ForDormitory(id string) *AuditPostgresRepo {
+	return &AuditPostgresRepo{pool: r.pool, dorm: id}
+}
+
+func (r *AuditPostgresRepo) Append(ctx context.Context, e audit.Entry) (audit.Entry, error) {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	before, err := snapshot(e.Before)
+	if err != nil {
+		return audit.Entry{}, err
+	}
+	after, err := snapshot(e.After)
+	if err != nil {
+		return audit.Entry{}, err
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		INSERT INTO audit_log (id, at, actor, admin, action, booking_id, before, after, ip, user_agent, dormitory_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+		e.ID, e.At, e.Actor, e.Admin, string(e.Action), e.BookingID, before, after, e.IP, e.UserAgent, r.dorm)
+	if err != nil {
+		return audit.Entry{}, err
+	}
+	return e, nil
+}
+
+func (r *AuditPostgresRepo) Find(ctx context.Context, f audit.Filter) ([]audit.Entry, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conds = append(conds, "dormitory_id = "+arg(r.dorm))
+	if f.Actor != "" {
+		conds = append(conds, "actor = "+arg(f.Actor))
+	}
+	if f.BookingID != "" {
+		conds = append(conds, "booking_id = "+arg(f.BookingID))
+	}
+	if f.Action != "" {
+		conds = append(conds, "action = "+arg(string(f.Action)))
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "at >= "+arg(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "at < "+arg(f.To))
+	}
+
+	query := `SELECT id, at, actor, admin, action, booking_id, before, after, ip, user_agent FROM audit_log
+		WHERE ` + strings.Join(conds, " AND ")
+	query += ` ORDER BY at DESC, id`
+	if f.Limit > 0 {
+		query += ` LIMIT ` + arg(f.Limit)
+	}
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []audit.Entry
+	for rows.Next() {
+		var (
+			e             audit.Entry
+			action        string
+			before, after []byte
+		)
+		if err := rows.Scan(&e.ID, &e.At, &e.Actor, &e.Admin, &action, &e.BookingID, &before, &after, &e.IP, &e.UserAgent); err != nil {
+			return nil, err
+		}
+		e.Action = audit.Action(action)
+		if e.Before, err = fromSnapshot(before); err != nil {
+			return nil, err
+		}
+		if e.After, err = fromSnapshot(after); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// snapshot - состояние брони для JSONB-колонки; nil - NULL.
+func snapshot(b *booking.Booking) ([]byte, error) {
+	if b == nil {
+		return nil, nil
+	}
+	return json.Marshal(b)
+}
+
+func fromSnapshot(raw []byte) (*booking.Booking, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var b booking.Booking
+	if err := json.Unmarshal(raw, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
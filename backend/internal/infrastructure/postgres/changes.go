@@ -0,0 +1,102 @@
+package postgres
+
+// В этом файле журнал ревизий броней: выборка изменений и устаревание старых надгробий.
+// Ревизии ставит триггер bookings_revision_* (миграция 015) под блокировкой общежития
+// (миграция 018): по возрастанию они коммитятся только внутри одного общежития.
+
+import (
+	"context"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+)
+
+// Changes возвращает брони общежития, изменённые после ревизии since. У среза по всем
+// общежитиям курсор может обогнать незакоммиченную запись в другом общежитии и пропустить её.
+func (r *BookingPostgresRepo) Changes(ctx context.Context, since int64, limit int) (booking.ChangeSet, error) {
+	if since < 0 {
+		return booking.ChangeSet{}, booking.ErrCursorExpired
+	}
+
+	// на одну больше, чтобы узнать, есть ли следующая страница; NULL - без ограничения
+	var fetch any
+	if limit > 0 {
+		fetch = limit + 1
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT `+bookingColumns+`
+		 FROM bookings
+		 WHERE revision > $1 AND `+inDorm(2)+`
+		   AND NOT (status = 'cancelled' AND revision <= COALESCE(
+		       (SELECT h.revision FROM booking_changes_horizon h WHERE h.dormitory_id = bookings.dormitory_id), 0))
+		 ORDER BY revision
+		 LIMIT $3`,
+		since,
+		r.dorm,
+		fetch,
+	)
+	if err != nil {
+		return booking.ChangeSet{}, err
+	}
+	defer rows.Close()
+
+	out := make([]booking.Booking, 0)
+	for rows.Next() {
+		var b booking.Booking
+		if err := rows.Scan(bookingDest(&b)...); err != nil {
+			return booking.ChangeSet{}, err
+		}
+		out = append(out, b)
+	}
+	if err := rows.Err(); err != nil {
+		return booking.ChangeSet{}, err
+	}
+
+	// горизонт читаем после выборки: если очистка успела между ними, курсор честно устареет
+	var head, horizon int64
+	err = r.db.QueryRow(ctx,
+		`SELECT (SELECT CASE WHEN is_called THEN last_value ELSE 0 END FROM booking_revision_seq),
+		        COALESCE((SELECT max(revision) FROM booking_changes_horizon WHERE `+inDorm(1)+`), 0)`,
+		r.dorm,
+	).Scan(&head, &horizon)
+	if err != nil {
+		return booking.ChangeSet{}, err
+	}
+	if since > head || (since > 0 && since < horizon) {
+		return booking.ChangeSet{}, booking.ErrCursorExpired
+	}
+
+	set := booking.ChangeSet{Revision: since}
+	if limit > 0 && len(out) > limit {
+		out, set.More = out[:limit], true
+	}
+	if len(out) > 0 {
+		set.Revision = out[len(out)-1].Revision
+	}
+	set.Bookings = out
+	return set, nil
+}
+
+// ExpireTombstones поднимает горизонт общежития до брони, отменённой раньше before.
+// Строки bookings не трогаем: отмены и неявки нужны истории и статистике.
+func (r *BookingPostgresRepo) ExpireTombstones(ctx context.Context, before time.Time) (int, error) {
+	var expired int
+	err := r.db.QueryRow(ctx,
+		`WITH old AS (
+		     SELECT dormitory_id, revision
+		     FROM bookings b
+		     WHERE status = 'cancelled' AND cancelled_at < $1 AND `+inDorm(2)+`
+		       AND revision > COALESCE(
+		           (SELECT h.revision FROM booking_changes_horizon h WHERE h.dormitory_id = b.dormitory_id), 0)
+		 ), horizon AS (
+		     INSERT INTO booking_changes_horizon (dormitory_id, revision)
+		     SELECT dormitory_id, max(revision) FROM old GROUP BY dormitory_id
+		     ON CONFLICT (dormitory_id) DO UPDATE
+		     SET revision = GREATEST(booking_changes_horizon.revision, EXCLUDED.revision)
+		 )
+		 SELECT count(*) FROM old`,
+		before,
+		r.dorm,
+	).Scan(&expired)
+	return expired, err
+}
@@ -0,0 +1,96 @@
+package postgres
+
+// В этом файле лежит каталог общежитий (таблица dormitories).
+
+import (
+	"context"
+	"errors"
+
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DormitoryPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewDormitoryPostgresRepo создаёт каталог общежитий поверх пула соединений pgx.
+func NewDormitoryPostgresRepo(pool *pgxpool.Pool) *DormitoryPostgresRepo {
+	return &DormitoryPostgresRepo{pool: pool}
+}
+
+const dormitoryColumns = `id, name, hosts, timezone, admins`
+
+func dormitoryDest(d *dormitory.Dormitory) []any {
+	return []any{&d.ID, &d.Name, &d.Hosts, &d.Timezone, &d.Admins}
+}
+
+func (r *DormitoryPostgresRepo) List(ctx context.Context) ([]dormitory.Dormitory, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+dormitoryColumns+` FROM dormitories ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []dormitory.Dormitory
+	for rows.Next() {
+		var d dormitory.Dormitory
+		if err := rows.Scan(dormitoryDest(&d)...); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+func (r *DormitoryPostgresRepo) Get(ctx context.Context, id string) (dormitory.Dormitory, error) {
+	var d dormitory.Dormitory
+	err := r.pool.QueryRow(ctx, `SELECT `+dormitoryColumns+` FROM dormitories WHERE id = $1`, id).Scan(dormitoryDest(&d)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return dormitory.Dormitory{}, dormitory.ErrNotFound
+	}
+	if err != nil {
+		return dormitory.Dormitory{}, err
+	}
+	return d, nil
+}
+
+func (r *DormitoryPostgresRepo) Create(ctx context.Context, d dormitory.Dormitory) (dormitory.Dormitory, error) {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO dormitories (id, name, hosts, timezone, admins) VALUES ($1,$2,$3,$4,$5)`,
+		d.ID,
+		d.Name,
+		textArray(d.Hosts),
+		d.Timezone,
+		textArray(d.Admins),
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return dormitory.Dormitory{}, dormitory.ErrExists
+		}
+		return dormitory.Dormitory{}, err
+	}
+	return d, nil
+}
+
+func (r *DormitoryPostgresRepo) Update(ctx context.Context, d dormitory.Dormitory) (dormitory.Dormitory, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE dormitories SET name = $2, hosts = $3, timezone = $4, admins = $5 WHERE id = $1`,
+		d.ID,
+		d.Name,
+		textArray(d.Hosts),
+		d.Timezone,
+		textArray(d.Admins),
+	)
+	if err != nil {
+		return dormitory.Dormitory{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return dormitory.Dormitory{}, dormitory.ErrNotFound
+	}
+	return d, nil
+}
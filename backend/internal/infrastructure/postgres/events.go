@@ -0,0 +1,120 @@
+package postgres
+
+// В этом файле рассылка событий о бронях между экземплярами backend через LISTEN/NOTIFY.
+// В NOTIFY уходит только тип, ID и прежнее состояние брони (полезная нагрузка ограничена
+// 8000 байт), а саму бронь каждый экземпляр перечитывает из таблицы.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// eventsChannel - канал NOTIFY с изменениями броней.
+const eventsChannel = "booking_events"
+
+type EventPostgresBus struct {
+	pool     *pgxpool.Pool
+	bookings *BookingPostgresRepo // все общежития сразу
+	local    booking.EventBus     // раздаёт события подписчикам этого экземпляра
+}
+
+// NewEventPostgresBus создаёт рассылку поверх пула; local раздаёт полученные события
+// подписчикам этого процесса. Без запущенного Listen события только отправляются.
+func NewEventPostgresBus(pool *pgxpool.Pool, local booking.EventBus) *EventPostgresBus {
+	return &EventPostgresBus{
+		pool:     pool,
+		bookings: NewBookingPostgresRepo(pool).ForDormitory(""),
+		local:    local,
+	}
+}
+
+// eventNotice - полезная нагрузка NOTIFY.
+type eventNotice struct {
+	Type     booking.EventType `json:"type"`
+	ID       string            `json:"id"`
+	Previous *booking.Booking  `json:"previous,omitempty"`
+}
+
+// Publish отправляет событие всем экземплярам, включая этот: он получит его через Listen.
+func (b *EventPostgresBus) Publish(ctx context.Context, e booking.Event) error {
+	n := eventNotice{Type: e.Type, ID: e.Booking.ID}
+	if e.Previous != nil {
+		// прежнее состояние нужно только для фильтров, описание может не влезть в NOTIFY
+		prev := *e.Previous
+		prev.Description = ""
+		n.Previous = &prev
+	}
+	payload, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	_, err = b.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, eventsChannel, string(payload))
+	return err
+}
+
+func (b *EventPostgresBus) Subscribe(ctx context.Context) <-chan booking.Event {
+	return b.local.Subscribe(ctx)
+}
+
+// Listen слушает канал до отмены ctx и раздаёт события локальным подписчикам.
+// Соединение при обрыве открывается заново; пропущенные за это время события теряются.
+func (b *EventPostgresBus) Listen(ctx context.Context) {
+	for {
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("events: LISTEN %s: %v", eventsChannel, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func (b *EventPostgresBus) listen(ctx context.Context) error {
+	conn, err := b.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `LISTEN `+eventsChannel); err != nil {
+		return err
+	}
+	// соединение вернётся в пул; без UNLISTEN на нём копились бы уведомления
+	defer conn.Exec(context.Background(), `UNLISTEN `+eventsChannel)
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var notice eventNotice
+		if err := json.Unmarshal([]byte(n.Payload), &notice); err != nil {
+			log.Printf("events: некорректное уведомление %q: %v", n.Payload, err)
+			continue
+		}
+		bk, err := b.bookings.Get(ctx, notice.ID)
+		if errors.Is(err, booking.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		e := booking.Event{Type: notice.Type, Booking: bk, Previous: notice.Previous}
+		if err := b.local.Publish(ctx, e); err != nil {
+			log.Printf("events: %s %s: %v", e.Type, e.Booking.ID, err)
+		}
+	}
+}
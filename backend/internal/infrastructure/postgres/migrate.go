@@ -0,0 +1,304 @@
+package postgres
+
+// В этом файле накатка схемы самим backend'ом: миграции вшиты в бинарник,
+// применённые версии и их контрольные суммы лежат в schema_migrations.
+// Параллельные запуски (сервер и бот) разводит advisory lock.
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ключ advisory lock'а миграций, произвольная константа
+const migrationLockKey = 7_310_420_015
+
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+?)(\.down)?\.sql$`)
+
+var (
+	// ErrLegacySchema - схема создана до появления schema_migrations (docker-entrypoint, psql в CI).
+	// Что уже применено, неизвестно: нужно один раз выполнить `migrate baseline <версия>`.
+	ErrLegacySchema = errors.New("схема создана без schema_migrations: выполните `migrate baseline <последняя применённая версия>`")
+	ErrNoDown       = errors.New("у миграции нет файла отката")
+)
+
+// Migration - одна версия схемы: NNN_name.sql накатывает, NNN_name.down.sql откатывает.
+type Migration struct {
+	Version  int
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 от Up
+}
+
+func (m Migration) String() string { return fmt.Sprintf("%03d_%s", m.Version, m.Name) }
+
+// MigrationStatus - миграция и когда её применили (nil - ещё не применена).
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// LoadMigrations читает миграции из fsys и упорядочивает по версии.
+// Версии должны идти подряд с 1, у каждой - свой файл отката.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		match := migrationFile.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, _ := strconv.Atoi(match[1])
+		body, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m := byVersion[version]
+		if m == nil {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("миграция %03d: разные имена %q и %q", version, m.Name, match[2])
+		}
+		if match[3] != "" {
+			m.Down = string(body)
+			continue
+		}
+		if m.Up != "" {
+			return nil, fmt.Errorf("миграция %03d объявлена дважды", version)
+		}
+		sum := sha256.Sum256(body)
+		m.Up = string(body)
+		m.Checksum = hex.EncodeToString(sum[:])
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		out = append(out, *m)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+
+	for i, m := range out {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("миграции должны идти подряд: после %d идёт %d", i, m.Version)
+		}
+		if m.Up == "" {
+			return nil, fmt.Errorf("миграция %s: нет файла %s.sql", m, m)
+		}
+		if m.Down == "" {
+			return nil, fmt.Errorf("миграция %s: %w", m, ErrNoDown)
+		}
+	}
+	return out, nil
+}
+
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+func NewMigrator(pool *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// Up накатывает все неприменённые миграции по порядку, каждую в своей транзакции.
+// Возвращает, сколько применено.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(done) == 0 {
+			legacy, err := hasLegacySchema(ctx, conn)
+			if err != nil {
+				return err
+			}
+			if legacy {
+				return ErrLegacySchema
+			}
+		}
+
+		for _, mig := range m.migrations {
+			if _, ok := done[mig.Version]; ok {
+				continue
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Up); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("миграция %s: %w", mig, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down откатывает последние steps применённых миграций.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	reverted := 0
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && reverted < steps; i-- {
+			mig := m.migrations[i]
+			if _, ok := done[mig.Version]; !ok {
+				continue
+			}
+			err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+				if _, err := tx.Exec(ctx, mig.Down); err != nil {
+					return err
+				}
+				_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("откат %s: %w", mig, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Baseline помечает миграции до version включительно применёнными, не выполняя их.
+// Нужен один раз для базы, схему которой накатывали руками.
+func (m *Migrator) Baseline(ctx context.Context, version int) error {
+	if version < 1 || version > len(m.migrations) {
+		return fmt.Errorf("нет миграции %d", version)
+	}
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		if len(done) > 0 {
+			return errors.New("schema_migrations уже заполнена, baseline не нужен")
+		}
+		return pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+			for _, mig := range m.migrations[:version] {
+				if _, err := tx.Exec(ctx, `INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					mig.Version, mig.Name, mig.Checksum); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// Status - все известные миграции и отметка о применении.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var out []MigrationStatus
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		done, err := m.verify(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			s := MigrationStatus{Migration: mig}
+			if at, ok := done[mig.Version]; ok {
+				s.AppliedAt = &at
+			}
+			out = append(out, s)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// withLock берёт соединение, advisory lock и создаёт schema_migrations, если её ещё нет.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, int64(migrationLockKey)); err != nil {
+		return err
+	}
+	// отпускаем даже при отменённом ctx, иначе блокировка останется на соединении в пуле
+	defer conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, int64(migrationLockKey))
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+	if err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// verify читает применённые версии и сверяет контрольные суммы с вшитыми файлами.
+func (m *Migrator) verify(ctx context.Context, conn *pgxpool.Conn) (map[int]time.Time, error) {
+	rows, err := conn.Query(ctx, `SELECT version, name, checksum, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	done := map[int]time.Time{}
+	for rows.Next() {
+		var (
+			version        int
+			name, checksum string
+			appliedAt      time.Time
+		)
+		if err := rows.Scan(&version, &name, &checksum, &appliedAt); err != nil {
+			return nil, err
+		}
+		if version < 1 || version > len(m.migrations) {
+			return nil, fmt.Errorf("в базе применена миграция %03d_%s, которой нет в этой сборке", version, name)
+		}
+		if mig := m.migrations[version-1]; mig.Checksum != checksum {
+			return nil, fmt.Errorf("миграция %s изменена после применения: контрольная сумма не совпадает", mig)
+		}
+		done[version] = appliedAt
+	}
+	return done, rows.Err()
+}
+
+// hasLegacySchema - есть ли таблицы приложения, созданные в обход раннера.
+func hasLegacySchema(ctx context.Context, conn *pgxpool.Conn) (bool, error) {
+	var exists bool
+	err := conn.QueryRow(ctx, `SELECT to_regclass('bookings') IS NOT NULL`).Scan(&exists)
+	return exists, err
+}
@@ -0,0 +1,84 @@
+package postgres_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"Dormitory_Booking/deploy/migrations"
+	pgrepo "Dormitory_Booking/internal/infrastructure/postgres"
+)
+
+func TestLoadMigrations_Embedded(t *testing.T) {
+	list, err := pgrepo.LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("вшитые миграции не читаются: %v", err)
+	}
+	if len(list) < 9 || list[0].String() != "001_init" || list[0].Checksum == "" {
+		t.Fatalf("неожиданный список миграций: %v", list)
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	file := func(s string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(s)} }
+
+	cases := map[string]fstest.MapFS{
+		"пропуск версии": {
+			"001_a.sql": file("SELECT 1"), "001_a.down.sql": file("SELECT 1"),
+			"003_c.sql": file("SELECT 1"), "003_c.down.sql": file("SELECT 1"),
+		},
+		"нет отката": {
+			"001_a.sql": file("SELECT 1"),
+		},
+		"нет наката": {
+			"001_a.down.sql": file("SELECT 1"),
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := pgrepo.LoadMigrations(fsys); err == nil {
+			t.Fatalf("%s: ожидали ошибку", name)
+		}
+	}
+
+	_, err := pgrepo.LoadMigrations(fstest.MapFS{"001_a.sql": file("SELECT 1")})
+	if !errors.Is(err, pgrepo.ErrNoDown) {
+		t.Fatalf("ожидали ErrNoDown, получили %v", err)
+	}
+}
+
+func TestMigrator_UpIsIdempotentAndDetectsEdits(t *testing.T) {
+	pool := requireTestDB(t)
+	defer pool.Close()
+	ctx := context.Background()
+
+	m, err := pgrepo.NewMigrator(pool, migrations.FS)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+	// схема уже накатана перед тестами, повторный запуск ничего не делает
+	if n, err := m.Up(ctx); err != nil || n != 0 {
+		t.Fatalf("повторный Up: применено %d, ошибка %v", n, err)
+	}
+	status, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, s := range status {
+		if s.AppliedAt == nil {
+			t.Fatalf("миграция %s не применена", s.Migration)
+		}
+	}
+
+	// изменённый после применения файл - ошибка, а не тихое расхождение схемы
+	edited := fstest.MapFS{}
+	for _, mig := range status {
+		edited[mig.String()+".sql"] = &fstest.MapFile{Data: []byte(mig.Up)}
+		edited[mig.String()+".down.sql"] = &fstest.MapFile{Data: []byte(mig.Down)}
+	}
+	edited["001_init.sql"] = &fstest.MapFile{Data: []byte(status[0].Up + "\n-- правка")}
+	m, _ = pgrepo.NewMigrator(pool, edited)
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatalf("ожидали ошибку контрольной суммы")
+	}
+}
@@ -0,0 +1,96 @@
+package postgres
+
+// В этом файле учёт неявок (таблица no_shows).
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NoShowPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewNoShowPostgresRepo создаёт учёт неявок общежития по умолчанию поверх пула соединений pgx.
+func NewNoShowPostgresRepo(pool *pgxpool.Pool) *NoShowPostgresRepo {
+	return &NoShowPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - неявки другого общежития.
+func (r *NoShowPostgresRepo) ForDormitory(id string) *NoShowPostgresRepo {
+	return &NoShowPostgresRepo{pool: r.pool, dorm: id}
+}
+
+// Record записывает неявку; повторная запись той же брони ничего не меняет.
+func (r *NoShowPostgresRepo) Record(ctx context.Context, n booking.NoShow) error {
+	if n.ID == "" {
+		n.ID = uuid.NewString()
+	}
+	if n.RecordedAt.IsZero() {
+		n.RecordedAt = time.Now()
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO no_shows (id, telegram_id, booking_id, room, start_at, recorded_at, dormitory_id)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7)
+		 ON CONFLICT (booking_id) DO NOTHING`,
+		n.ID,
+		n.TelegramID,
+		n.BookingID,
+		int(n.Room),
+		n.Start,
+		n.RecordedAt,
+		r.dorm,
+	)
+	return err
+}
+
+func (r *NoShowPostgresRepo) Find(ctx context.Context, f booking.NoShowFilter) ([]booking.NoShow, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conds = append(conds, "dormitory_id = "+arg(r.dorm))
+	if f.Owner != "" {
+		conds = append(conds, "telegram_id = "+arg(f.Owner))
+	}
+	if !f.From.IsZero() {
+		conds = append(conds, "start_at >= "+arg(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "start_at < "+arg(f.To))
+	}
+
+	query := `SELECT id, telegram_id, booking_id, room, start_at, recorded_at FROM no_shows WHERE ` + strings.Join(conds, " AND ")
+	query += ` ORDER BY start_at, id`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []booking.NoShow
+	for rows.Next() {
+		var n booking.NoShow
+		if err := rows.Scan(&n.ID, &n.TelegramID, &n.BookingID, &n.Room, &n.Start, &n.RecordedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, n)
+	}
+	return out, rows.Err()
+}
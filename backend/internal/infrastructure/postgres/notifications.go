@@ -0,0 +1,141 @@
+package postgres
+
+// В этом файле outbox уведомлений (notification_outbox) и настройки уведомлений (notification_prefs).
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"Dormitory_Booking/internal/domain/notification"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OutboxPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxPostgresRepo создаёт outbox поверх пула соединений pgx.
+func NewOutboxPostgresRepo(pool *pgxpool.Pool) *OutboxPostgresRepo {
+	return &OutboxPostgresRepo{pool: pool}
+}
+
+func (r *OutboxPostgresRepo) Enqueue(ctx context.Context, msgs ...notification.Message) error {
+	batch := &pgx.Batch{}
+	for _, m := range msgs {
+		if m.ID == "" {
+			m.ID = uuid.NewString()
+		}
+		batch.Queue(
+			`INSERT INTO notification_outbox (id, dedup_key, kind, channel, recipient, booking_id, subject, body, send_after)
+			 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+			 ON CONFLICT (dedup_key) DO NOTHING`,
+			m.ID, m.DedupKey, string(m.Kind), string(m.Channel), m.Recipient, m.BookingID, m.Subject, m.Body, m.SendAfter,
+		)
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// Claim помечает подходящие строки claimed_until; SKIP LOCKED разводит параллельных обработчиков.
+func (r *OutboxPostgresRepo) Claim(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]notification.Message, error) {
+	rows, err := r.pool.Query(ctx,
+		`UPDATE notification_outbox SET claimed_until = $2
+		 WHERE id IN (
+		     SELECT id FROM notification_outbox
+		     WHERE sent_at IS NULL AND failed_at IS NULL
+		       AND send_after <= $1
+		       AND (claimed_until IS NULL OR claimed_until <= $1)
+		     ORDER BY send_after
+		     LIMIT $3
+		     FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING id::text, dedup_key, kind, channel, recipient, booking_id, subject, body, send_after, attempts, last_error`,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []notification.Message
+	for rows.Next() {
+		var (
+			m             notification.Message
+			kind, channel string
+		)
+		if err := rows.Scan(&m.ID, &m.DedupKey, &kind, &channel, &m.Recipient, &m.BookingID,
+			&m.Subject, &m.Body, &m.SendAfter, &m.Attempts, &m.LastError); err != nil {
+			return nil, err
+		}
+		m.Kind = notification.Kind(kind)
+		m.Channel = notification.Channel(channel)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (r *OutboxPostgresRepo) MarkSent(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx, `UPDATE notification_outbox SET sent_at = now(), claimed_until = NULL WHERE id = $1`, id)
+	return err
+}
+
+func (r *OutboxPostgresRepo) MarkFailed(ctx context.Context, id string, reason string, retryAt time.Time) error {
+	if retryAt.IsZero() {
+		_, err := r.pool.Exec(ctx,
+			`UPDATE notification_outbox
+			 SET attempts = attempts + 1, last_error = $2, failed_at = now(), claimed_until = NULL
+			 WHERE id = $1`,
+			id, reason,
+		)
+		return err
+	}
+	_, err := r.pool.Exec(ctx,
+		`UPDATE notification_outbox
+		 SET attempts = attempts + 1, last_error = $2, send_after = $3, claimed_until = NULL
+		 WHERE id = $1`,
+		id, reason, retryAt,
+	)
+	return err
+}
+
+type PreferencesPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewPreferencesPostgresRepo создаёт хранилище настроек уведомлений поверх пула соединений pgx.
+func NewPreferencesPostgresRepo(pool *pgxpool.Pool) *PreferencesPostgresRepo {
+	return &PreferencesPostgresRepo{pool: pool}
+}
+
+func (r *PreferencesPostgresRepo) Get(ctx context.Context, userID string) (notification.Preferences, error) {
+	if _, err := uuid.Parse(userID); err != nil {
+		return notification.Preferences{}, notification.ErrNotFound
+	}
+	p := notification.Preferences{UserID: userID}
+	err := r.pool.QueryRow(ctx,
+		`SELECT telegram, email, webhook_url, reminders, reminder_minutes FROM notification_prefs WHERE user_id = $1`,
+		userID,
+	).Scan(&p.Telegram, &p.Email, &p.WebhookURL, &p.Reminders, &p.ReminderMinutes)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return notification.Preferences{}, notification.ErrNotFound
+		}
+		return notification.Preferences{}, err
+	}
+	return p, nil
+}
+
+func (r *PreferencesPostgresRepo) Save(ctx context.Context, p notification.Preferences) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO notification_prefs (user_id, telegram, email, webhook_url, reminders, reminder_minutes)
+		 VALUES ($1,$2,$3,$4,$5,$6)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET telegram = EXCLUDED.telegram, email = EXCLUDED.email, webhook_url = EXCLUDED.webhook_url,
+		     reminders = EXCLUDED.reminders, reminder_minutes = EXCLUDED.reminder_minutes`,
+		p.UserID, p.Telegram, p.Email, p.WebhookURL, p.Reminders, p.ReminderMinutes,
+	)
+	return err
+}
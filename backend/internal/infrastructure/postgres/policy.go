@@ -0,0 +1,74 @@
+package postgres
+
+// В этом файле хранилище версий политики бронирования (таблица booking_policies).
+// У каждого общежития своя политика; номера версий общие на всю таблицу.
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PolicyPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewPolicyPostgresRepo создаёт хранилище политики общежития по умолчанию поверх пула соединений pgx.
+func NewPolicyPostgresRepo(pool *pgxpool.Pool) *PolicyPostgresRepo {
+	return &PolicyPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - политика другого общежития.
+func (r *PolicyPostgresRepo) ForDormitory(id string) *PolicyPostgresRepo {
+	return &PolicyPostgresRepo{pool: r.pool, dorm: id}
+}
+
+// Current возвращает последнюю сохранённую версию. Пока версий нет - appbooking.DefaultPolicy.
+func (r *PolicyPostgresRepo) Current(ctx context.Context) (appbooking.Policy, error) {
+	var (
+		version int
+		body    []byte
+	)
+	err := r.pool.QueryRow(ctx,
+		`SELECT version, body FROM booking_policies WHERE dormitory_id = $1 ORDER BY version DESC LIMIT 1`,
+		r.dorm,
+	).Scan(&version, &body)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return appbooking.DefaultPolicy(), nil
+		}
+		return appbooking.Policy{}, err
+	}
+
+	var p appbooking.Policy
+	if err := json.Unmarshal(body, &p); err != nil {
+		return appbooking.Policy{}, err
+	}
+	p.Version = version
+	return p, nil
+}
+
+// Save добавляет новую версию; старые остаются в таблице как история.
+func (r *PolicyPostgresRepo) Save(ctx context.Context, p appbooking.Policy) (appbooking.Policy, error) {
+	p.Version = 0
+	body, err := json.Marshal(p)
+	if err != nil {
+		return appbooking.Policy{}, err
+	}
+
+	if err := r.pool.QueryRow(ctx,
+		`INSERT INTO booking_policies (body, dormitory_id) VALUES ($1, $2) RETURNING version`,
+		string(body),
+		r.dorm,
+	).Scan(&p.Version); err != nil {
+		return appbooking.Policy{}, err
+	}
+	return p, nil
+}
@@ -2,12 +2,19 @@ package postgres
 
 // В этом файле лежит реализация репозитория бронирований через Postgres.
 // По сути это адаптер между доменной моделью и таблицей bookings в БД.
+// Каждый экземпляр видит брони одного общежития (dormitory_id).
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -15,39 +22,199 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// querier - общее у пула и транзакции, чтобы одни и те же запросы работали в обоих режимах.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 type BookingPostgresRepo struct {
 	pool *pgxpool.Pool
+	db   querier // pool или открытая транзакция внутри WithRoomLock
+	dorm string  // "" - все общежития сразу, так репозиторий только читают
 }
 
-// NewBookingPostgresRepo создаёт репозиторий поверх пула соединений pgx.
+// NewBookingPostgresRepo создаёт репозиторий общежития по умолчанию поверх пула соединений pgx.
 func NewBookingPostgresRepo(pool *pgxpool.Pool) *BookingPostgresRepo {
-	return &BookingPostgresRepo{pool: pool}
+	return &BookingPostgresRepo{pool: pool, db: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - тот же репозиторий, но с бронями другого общежития. Пустой id - все
+// общежития (для рассылки напоминаний); создавать брони через него можно только с заданным Dormitory.
+func (r *BookingPostgresRepo) ForDormitory(id string) *BookingPostgresRepo {
+	return &BookingPostgresRepo{pool: r.pool, db: r.db, dorm: id}
+}
+
+// dormOf - в какое общежитие писать бронь.
+func (r *BookingPostgresRepo) dormOf(b booking.Booking) string {
+	switch {
+	case r.dorm != "":
+		return r.dorm
+	case b.Dormitory != "":
+		return b.Dormitory
+	default:
+		return dormitory.DefaultID
+	}
+}
+
+// inDorm - условие на общежитие с параметром $n; пустое значение параметра условие снимает.
+func inDorm(n int) string {
+	return fmt.Sprintf("(dormitory_id = $%d OR $%d = '')", n, n)
+}
+
+// Первый ключ pg_advisory_xact_lock - класс блокировки, второй - хэш общежития
+// и номера комнаты или ника владельца.
+const (
+	roomLockClass  = 1
+	ownerLockClass = 2
+)
+
+// WithRoomLock открывает транзакцию, берёт advisory lock на каждую комнату и каждого владельца
+// общежития и выполняет fn с репозиторием поверх этой транзакции. Сначала комнаты, потом
+// владельцы, каждые по порядку - так параллельные вызовы не взаимоблокируются.
+// Блокировки снимаются на commit/rollback.
+func (r *BookingPostgresRepo) WithRoomLock(ctx context.Context, rooms []booking.Room, owners []string, fn func(repo booking.Repository) error) error {
+	sorted := append([]booking.Room(nil), rooms...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	names := append([]string(nil), owners...)
+	sort.Strings(names)
+
+	// уже внутри транзакции: просто докладываем блокировки
+	if tx, ok := r.db.(pgx.Tx); ok {
+		if err := lockKeys(ctx, tx, r.dorm, sorted, names); err != nil {
+			return err
+		}
+		return fn(r)
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockKeys(ctx, tx, r.dorm, sorted, names); err != nil {
+		return err
+	}
+	if err := fn(&BookingPostgresRepo{pool: r.pool, db: tx, dorm: r.dorm}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func lockKeys(ctx context.Context, tx pgx.Tx, dorm string, rooms []booking.Room, owners []string) error {
+	for _, room := range rooms {
+		key := dorm + "/" + strconv.Itoa(int(room))
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, roomLockClass, key); err != nil {
+			return err
+		}
+	}
+	for _, owner := range owners {
+		if owner == "" {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1, hashtext($2))`, ownerLockClass, dorm+"/"+owner); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+const bookingColumns = `id, start_at, end_at, room, dormitory_id, title, COALESCE(description, ''), telegram_id, is_private, COALESCE(series_id, ''),
+	status, cancelled_at, COALESCE(cancelled_by, ''), COALESCE(cancel_reason, ''), checked_in_at, revision`
+
+// bookingDest - куда сканировать строку из bookingColumns.
+func bookingDest(b *booking.Booking) []any {
+	return []any{
+		&b.ID,
+		&b.Start,
+		&b.End,
+		&b.Room,
+		&b.Dormitory,
+		&b.Title,
+		&b.Description,
+		&b.TelegramID,
+		&b.IsPrivate,
+		&b.SeriesID,
+		&b.Status,
+		&b.CancelledAt,
+		&b.CancelledBy,
+		&b.CancelReason,
+		&b.CheckedInAt,
+		&b.Revision,
+	}
 }
 
 func (r *BookingPostgresRepo) List(ctx context.Context) ([]booking.Booking, error) {
-	rows, err := r.pool.Query(ctx,
-		`SELECT id, start_at, end_at, room, title, COALESCE(description, ''), telegram_id, is_private
+	rows, err := r.db.Query(ctx,
+		`SELECT `+bookingColumns+`
 		 FROM bookings
+		 WHERE status <> 'cancelled' AND `+inDorm(1)+`
 		 ORDER BY start_at`,
+		r.dorm,
 	)
 	if err != nil {
 		return nil, err
 	}
+	return scanBookings(rows)
+}
+
+// Find выбирает брони под фильтр. Диапазон времени проверяется через tstzrange,
+// чтобы запрос шёл по GiST-индексу bookings_period_idx.
+func (r *BookingPostgresRepo) Find(ctx context.Context, f booking.ListFilter) ([]booking.Booking, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if r.dorm != "" {
+		conds = append(conds, "dormitory_id = "+arg(r.dorm))
+	}
+	if !f.From.IsZero() || !f.To.IsZero() {
+		conds = append(conds, "tstzrange(start_at, end_at, '[)') && tstzrange("+arg(nullIfZero(f.From))+"::timestamptz, "+arg(nullIfZero(f.To))+"::timestamptz, '[)')")
+	}
+	if f.Room != 0 {
+		conds = append(conds, "room = "+arg(int(f.Room)))
+	}
+	if f.Owner != "" {
+		conds = append(conds, "telegram_id = "+arg(f.Owner))
+	}
+	if f.IsPrivate != nil {
+		conds = append(conds, "is_private = "+arg(*f.IsPrivate))
+	}
+	if f.SeriesID != "" {
+		conds = append(conds, "series_id = "+arg(f.SeriesID))
+	}
+	if !f.IncludeCancelled {
+		conds = append(conds, "status <> 'cancelled'")
+	}
+
+	query := `SELECT ` + bookingColumns + ` FROM bookings`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, " AND ")
+	}
+	query += ` ORDER BY start_at`
+
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanBookings(rows)
+}
+
+func scanBookings(rows pgx.Rows) ([]booking.Booking, error) {
 	defer rows.Close()
 
 	var out []booking.Booking
 	for rows.Next() {
 		var b booking.Booking
-		if err := rows.Scan(
-			&b.ID,
-			&b.Start,
-			&b.End,
-			&b.Room,
-			&b.Title,
-			&b.Description,
-			&b.TelegramID,
-			&b.IsPrivate,
-		); err != nil {
+		if err := rows.Scan(bookingDest(&b)...); err != nil {
 			return nil, err
 		}
 		out = append(out, b)
@@ -57,21 +224,13 @@ func (r *BookingPostgresRepo) List(ctx context.Context) ([]booking.Booking, erro
 
 func (r *BookingPostgresRepo) Get(ctx context.Context, id string) (booking.Booking, error) {
 	var b booking.Booking
-	err := r.pool.QueryRow(ctx,
-		`SELECT id, start_at, end_at, room, title, COALESCE(description, ''), telegram_id, is_private
+	err := r.db.QueryRow(ctx,
+		`SELECT `+bookingColumns+`
 		 FROM bookings
-		 WHERE id = $1`,
+		 WHERE id = $1 AND `+inDorm(2),
 		id,
-	).Scan(
-		&b.ID,
-		&b.Start,
-		&b.End,
-		&b.Room,
-		&b.Title,
-		&b.Description,
-		&b.TelegramID,
-		&b.IsPrivate,
-	)
+		r.dorm,
+	).Scan(bookingDest(&b)...)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return booking.Booking{}, booking.ErrNotFound
@@ -85,19 +244,24 @@ func (r *BookingPostgresRepo) Create(ctx context.Context, b booking.Booking) (bo
 	if b.ID == "" {
 		b.ID = uuid.NewString()
 	}
+	b.Status = booking.StatusActive
+	b.Dormitory = r.dormOf(b)
 
-	_, err := r.pool.Exec(ctx,
-		`INSERT INTO bookings (id, start_at, end_at, room, title, description, telegram_id, is_private)
-		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8)`,
+	err := r.db.QueryRow(ctx,
+		`INSERT INTO bookings (id, start_at, end_at, room, dormitory_id, title, description, telegram_id, is_private, series_id)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+		 RETURNING revision`,
 		b.ID,
 		b.Start,
 		b.End,
 		int(b.Room),
+		b.Dormitory,
 		b.Title,
 		nullIfEmpty(b.Description),
 		b.TelegramID,
 		b.IsPrivate,
-	)
+		nullIfEmpty(b.SeriesID),
+	).Scan(&b.Revision)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -111,15 +275,113 @@ func (r *BookingPostgresRepo) Create(ctx context.Context, b booking.Booking) (bo
 	return b, nil
 }
 
-func (r *BookingPostgresRepo) Delete(ctx context.Context, id string) error {
-	tag, err := r.pool.Exec(ctx, `DELETE FROM bookings WHERE id = $1`, id)
+func (r *BookingPostgresRepo) Update(ctx context.Context, b booking.Booking) (booking.Booking, error) {
+	var dorm string
+	err := r.db.QueryRow(ctx,
+		`UPDATE bookings
+		 SET start_at = $2, end_at = $3, room = $4, title = $5, description = $6, is_private = $7
+		 WHERE id = $1 AND `+inDorm(8)+`
+		 RETURNING dormitory_id, revision`,
+		b.ID,
+		b.Start,
+		b.End,
+		int(b.Room),
+		b.Title,
+		nullIfEmpty(b.Description),
+		b.IsPrivate,
+		r.dorm,
+	).Scan(&dorm, &b.Revision)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return booking.Booking{}, booking.ErrNotFound
+	}
 	if err != nil {
-		return err
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23P01" {
+				return booking.Booking{}, booking.ErrOverlap
+			}
+		}
+		return booking.Booking{}, err
 	}
-	if tag.RowsAffected() == 0 {
-		return booking.ErrNotFound
+
+	b.Dormitory = dorm
+	return b, nil
+}
+
+// Cancel помечает бронь отменённой. Строка остаётся, но exclusion constraint её больше не видит.
+func (r *BookingPostgresRepo) Cancel(ctx context.Context, id string, c booking.Cancellation) (booking.Booking, error) {
+	var b booking.Booking
+	err := r.db.QueryRow(ctx,
+		`UPDATE bookings
+		 SET status = 'cancelled', cancelled_at = $2, cancelled_by = $3, cancel_reason = $4
+		 WHERE id = $1 AND status <> 'cancelled' AND `+inDorm(5)+`
+		 RETURNING `+bookingColumns,
+		id,
+		c.At,
+		nullIfEmpty(c.By),
+		nullIfEmpty(c.Reason),
+		r.dorm,
+	).Scan(bookingDest(&b)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return booking.Booking{}, r.missing(ctx, id, booking.ErrCancelled)
 	}
-	return nil
+	if err != nil {
+		return booking.Booking{}, err
+	}
+	return b, nil
+}
+
+// Restore снимает отмену. Если время уже занято, сработает room_time_no_overlap.
+func (r *BookingPostgresRepo) Restore(ctx context.Context, id string) (booking.Booking, error) {
+	var b booking.Booking
+	err := r.db.QueryRow(ctx,
+		`UPDATE bookings
+		 SET status = 'active', cancelled_at = NULL, cancelled_by = NULL, cancel_reason = NULL
+		 WHERE id = $1 AND status = 'cancelled' AND `+inDorm(2)+`
+		 RETURNING `+bookingColumns,
+		id,
+		r.dorm,
+	).Scan(bookingDest(&b)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return booking.Booking{}, r.missing(ctx, id, booking.ErrNotCancelled)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23P01" {
+			return booking.Booking{}, booking.ErrOverlap
+		}
+		return booking.Booking{}, err
+	}
+	return b, nil
+}
+
+// CheckIn отмечает приход; у уже отмеченной брони время первой отметки не меняется.
+func (r *BookingPostgresRepo) CheckIn(ctx context.Context, id string, at time.Time) (booking.Booking, error) {
+	var b booking.Booking
+	err := r.db.QueryRow(ctx,
+		`UPDATE bookings
+		 SET checked_in_at = COALESCE(checked_in_at, $2)
+		 WHERE id = $1 AND status <> 'cancelled' AND `+inDorm(3)+`
+		 RETURNING `+bookingColumns,
+		id,
+		at,
+		r.dorm,
+	).Scan(bookingDest(&b)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return booking.Booking{}, r.missing(ctx, id, booking.ErrCancelled)
+	}
+	if err != nil {
+		return booking.Booking{}, err
+	}
+	return b, nil
+}
+
+// missing объясняет, почему UPDATE не нашёл строку: брони нет вовсе или она не в том статусе.
+func (r *BookingPostgresRepo) missing(ctx context.Context, id string, wrongStatus error) error {
+	if _, err := r.Get(ctx, id); err != nil {
+		return err
+	}
+	return wrongStatus
 }
 
 func nullIfEmpty(s string) any {
@@ -128,3 +390,10 @@ func nullIfEmpty(s string) any {
 	}
 	return s
 }
+
+func nullIfZero(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
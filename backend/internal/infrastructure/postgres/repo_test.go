@@ -3,11 +3,19 @@ package postgres_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/audit"
 	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+	"Dormitory_Booking/internal/domain/notification"
+	"Dormitory_Booking/internal/domain/user"
+	"Dormitory_Booking/internal/infrastructure/memory"
 	pgrepo "Dormitory_Booking/internal/infrastructure/postgres"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -34,6 +42,32 @@ func requireTestDB(t *testing.T) *pgxpool.Pool {
 	if _, err := pool.Exec(ctx, `DELETE FROM bookings`); err != nil {
 		t.Skipf("не удалось очистить таблицу bookings, пропуск тестов Postgres репозитория: %v", err)
 	}
+	if _, err := pool.Exec(ctx, `DELETE FROM booking_series`); err != nil {
+		t.Skipf("не удалось очистить таблицу booking_series, пропуск тестов Postgres репозитория: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM waitlist`); err != nil {
+		t.Skipf("не удалось очистить таблицу waitlist, пропуск тестов Postgres репозитория: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM no_shows`); err != nil {
+		t.Skipf("не удалось очистить таблицу no_shows, пропуск тестов Postgres репозитория: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM notification_outbox`); err != nil {
+		t.Skipf("не удалось очистить таблицу notification_outbox, пропуск тестов Postgres репозитория: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM users`); err != nil {
+		t.Skipf("не удалось очистить таблицу users, пропуск тестов Postgres репозитория: %v", err)
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM booking_changes_horizon`); err != nil {
+		t.Skipf("не удалось очистить таблицу booking_changes_horizon, пропуск тестов Postgres репозитория: %v", err)
+	}
+	for _, table := range []string{"rooms", "booking_policies"} {
+		if _, err := pool.Exec(ctx, `DELETE FROM `+table+` WHERE dormitory_id <> 'main'`); err != nil {
+			t.Skipf("не удалось очистить таблицу %s, пропуск тестов Postgres репозитория: %v", table, err)
+		}
+	}
+	if _, err := pool.Exec(ctx, `DELETE FROM dormitories WHERE id <> 'main'`); err != nil {
+		t.Skipf("не удалось очистить таблицу dormitories, пропуск тестов Postgres репозитория: %v", err)
+	}
 
 	return pool
 }
@@ -98,26 +132,49 @@ func TestPostgresRepo_List(t *testing.T) {
 	}
 }
 
-func TestPostgresRepo_Delete(t *testing.T) {
+func TestPostgresRepo_CancelAndRestore(t *testing.T) {
 	pool := requireTestDB(t)
 	repo := pgrepo.NewBookingPostgresRepo(pool)
 	ctx := context.Background()
 
+	start := time.Date(2025, 1, 2, 10, 0, 0, 0, time.UTC)
 	created, _ := repo.Create(ctx, booking.Booking{
-		Start:      time.Now(),
-		End:        time.Now().Add(time.Hour),
+		Start:      start,
+		End:        start.Add(time.Hour),
 		Room:       booking.Room21,
-		Title:      "To delete",
+		Title:      "To cancel",
 		TelegramID: "222",
 	})
 
-	err := repo.Delete(ctx, created.ID)
+	cancelled, err := repo.Cancel(ctx, created.ID, booking.Cancellation{At: time.Now(), By: "222", Reason: "заболел"})
 	if err != nil {
 		t.Fatalf("неожиданная ошибка: %v", err)
 	}
+	if cancelled.Status != booking.StatusCancelled || cancelled.CancelReason != "заболел" || cancelled.CancelledAt == nil {
+		t.Fatalf("отмена не записалась: %+v", cancelled)
+	}
+	if _, err := repo.Cancel(ctx, created.ID, booking.Cancellation{At: time.Now()}); !errors.Is(err, booking.ErrCancelled) {
+		t.Fatalf("ожидалось ErrCancelled, получено %v", err)
+	}
+	if list, _ := repo.Find(ctx, booking.ListFilter{Room: booking.Room21}); len(list) != 0 {
+		t.Fatalf("отменённая бронь попала в выборку: %+v", list)
+	}
+
+	// exclusion constraint не видит отменённую бронь
+	taken, err := repo.Create(ctx, booking.Booking{Start: start, End: start.Add(time.Hour), Room: booking.Room21, Title: "New", TelegramID: "333"})
+	if err != nil {
+		t.Fatalf("время отменённой брони должно быть свободно: %v", err)
+	}
+	if _, err := repo.Restore(ctx, created.ID); !errors.Is(err, booking.ErrOverlap) {
+		t.Fatalf("ожидалось ErrOverlap, получено %v", err)
+	}
 
-	_, err = repo.Get(ctx, created.ID)
-	if err != booking.ErrNotFound {
+	repo.Cancel(ctx, taken.ID, booking.Cancellation{At: time.Now()})
+	restored, err := repo.Restore(ctx, created.ID)
+	if err != nil || restored.Status != booking.StatusActive || restored.CancelledAt != nil {
+		t.Fatalf("бронь не восстановилась: %+v, %v", restored, err)
+	}
+	if _, err := repo.Restore(ctx, "missing"); !errors.Is(err, booking.ErrNotFound) {
 		t.Fatalf("ожидалось ErrNotFound, получено %v", err)
 	}
 }
@@ -150,3 +207,696 @@ func TestPostgresRepo_OverlapConstraint(t *testing.T) {
 		t.Fatalf("ожидалось ErrOverlap, got %v", err)
 	}
 }
+
+func TestPostgresRepo_Update(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewBookingPostgresRepo(pool)
+	ctx := context.Background()
+
+	created, err := repo.Create(ctx, booking.Booking{
+		Start:      time.Now(),
+		End:        time.Now().Add(time.Hour),
+		Room:       booking.Room21,
+		Title:      "Before",
+		TelegramID: "444",
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	created.Title = "After"
+	created.Room = booking.Room132
+	if _, err := repo.Update(ctx, created); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	got, err := repo.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Title != "After" || got.Room != booking.Room132 {
+		t.Fatalf("бронь не обновилась: %+v", got)
+	}
+}
+
+func TestPostgresRepo_Find(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewBookingPostgresRepo(pool)
+	ctx := context.Background()
+
+	start := time.Date(2099, 1, 1, 10, 0, 0, 0, time.UTC)
+	repo.Create(ctx, booking.Booking{Start: start, End: start.Add(time.Hour), Room: booking.Room21, Title: "A", TelegramID: "1"})
+	repo.Create(ctx, booking.Booking{Start: start, End: start.Add(time.Hour), Room: booking.Room132, Title: "B", TelegramID: "2", IsPrivate: true})
+	repo.Create(ctx, booking.Booking{Start: start.AddDate(0, 0, 7), End: start.AddDate(0, 0, 7).Add(time.Hour), Room: booking.Room21, Title: "C", TelegramID: "1"})
+
+	week, err := repo.Find(ctx, booking.ListFilter{From: start.Add(-time.Hour), To: start.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(week) != 2 {
+		t.Fatalf("ожидалось 2, получено %d", len(week))
+	}
+
+	isPrivate := true
+	private, err := repo.Find(ctx, booking.ListFilter{IsPrivate: &isPrivate})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(private) != 1 || private[0].Title != "B" {
+		t.Fatalf("ожидалась только бронь B, получено %+v", private)
+	}
+
+	own, err := repo.Find(ctx, booking.ListFilter{Owner: "1", Room: booking.Room21})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if len(own) != 2 {
+		t.Fatalf("ожидалось 2, получено %d", len(own))
+	}
+}
+
+func TestPostgresRepo_ParallelPrivateCreates(t *testing.T) {
+	pool := requireTestDB(t)
+	svc := appbooking.NewService(pgrepo.NewBookingPostgresRepo(pool))
+	ctx := context.Background()
+
+	day := time.Date(2099, 1, 5, 6, 0, 0, 0, time.UTC)
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := day.Add(time.Duration(i%144) * 5 * time.Minute)
+			_, err := svc.CreateBooking(ctx, appbooking.CreateBookingInput{
+				Start:      start,
+				End:        start.Add(5 * time.Minute),
+				Room:       booking.Room256,
+				Title:      "ЧП",
+				TelegramID: fmt.Sprintf("user-%d", i),
+				IsPrivate:  true,
+			})
+			switch {
+			case err == nil:
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			case errors.Is(err, booking.ErrPrivateDailyLimit), errors.Is(err, booking.ErrOverlap):
+			default:
+				t.Errorf("неожиданная ошибка: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if ok != 3 {
+		t.Fatalf("ожидали ровно 3 частные брони за день, получили %d", ok)
+	}
+}
+
+func TestPostgresRepo_ParallelQuotaAcrossRooms(t *testing.T) {
+	pool := requireTestDB(t)
+	svc := appbooking.NewService(pgrepo.NewBookingPostgresRepo(pool), appbooking.WithPolicyStore(appbooking.NewStaticPolicyStore()))
+	ctx := context.Background()
+
+	p := appbooking.DefaultPolicy()
+	p.Quota = appbooking.Quota{MaxActiveBookings: 2}
+	if _, err := svc.SavePolicy(ctx, p); err != nil {
+		t.Fatalf("SavePolicy: %v", err)
+	}
+
+	day := time.Date(2099, 1, 5, 6, 0, 0, 0, time.UTC)
+	rooms := []booking.Room{booking.Room21, booking.Room132, booking.Room256}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+		ok int
+	)
+	for i := 0; i < 90; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := day.Add(time.Duration(i/3) * 5 * time.Minute)
+			_, err := svc.CreateBooking(ctx, appbooking.CreateBookingInput{
+				Start:      start,
+				End:        start.Add(5 * time.Minute),
+				Room:       rooms[i%3],
+				Title:      "Жадность",
+				TelegramID: "greedy",
+			})
+			switch {
+			case err == nil:
+				mu.Lock()
+				ok++
+				mu.Unlock()
+			case errors.Is(err, booking.ErrQuotaActive):
+			default:
+				t.Errorf("неожиданная ошибка: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if ok != 2 {
+		t.Fatalf("ожидали ровно 2 активные брони на все комнаты, получили %d", ok)
+	}
+}
+
+func TestSeriesPostgresRepo_CreateAndGet(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewSeriesPostgresRepo(pool)
+	ctx := context.Background()
+
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	created, err := repo.Create(ctx, booking.Series{
+		Start:      start,
+		End:        start.Add(2 * time.Hour),
+		Room:       booking.Room256,
+		Title:      "Настолки",
+		TelegramID: "club",
+		Rule: booking.Recurrence{
+			Freq:     booking.FreqWeekly,
+			Weekdays: []time.Weekday{time.Tuesday},
+			Count:    10,
+		},
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	got, err := repo.Get(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Rule.Freq != booking.FreqWeekly || len(got.Rule.Weekdays) != 1 || got.Rule.Weekdays[0] != time.Tuesday || got.Rule.Count != 10 {
+		t.Fatalf("правило не сохранилось: %+v", got.Rule)
+	}
+	if got.EndedAt != nil {
+		t.Fatalf("новая серия не должна быть завершённой")
+	}
+
+	ended := time.Now().Truncate(time.Microsecond)
+	if err := repo.End(ctx, created.ID, ended); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if err := repo.End(ctx, created.ID, ended.Add(time.Hour)); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got, _ := repo.Get(ctx, created.ID); got.EndedAt == nil || !got.EndedAt.Equal(ended) {
+		t.Fatalf("серия должна остаться с первым временем завершения: %+v", got.EndedAt)
+	}
+}
+
+func TestRoomPostgresRepo_CRUD(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewRoomPostgresRepo(pool)
+	ctx := context.Background()
+
+	pool.Exec(ctx, `DELETE FROM rooms WHERE number = 310`)
+
+	lounge := booking.RoomInfo{
+		Number:    310,
+		Name:      "Лаунж",
+		Capacity:  12,
+		Amenities: []string{"PS5", "проектор"},
+		Active:    true,
+		Schedule:  booking.Schedule{WeekdayOpen: 10, WeekdayClose: 20, FriSatOpen: 10, FriSatClose: 20, SunOpen: 10, SunClose: 20},
+	}
+	if _, err := repo.Create(ctx, lounge); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := repo.Create(ctx, lounge); !errors.Is(err, booking.ErrRoomExists) {
+		t.Fatalf("ожидалось ErrRoomExists, получено %v", err)
+	}
+
+	got, err := repo.Get(ctx, 310)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Schedule != lounge.Schedule || len(got.Amenities) != 2 {
+		t.Fatalf("комната сохранилась не целиком: %+v", got)
+	}
+
+	if err := repo.Delete(ctx, 310); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+}
+
+func TestPolicyPostgresRepo_SaveAndCurrent(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewPolicyPostgresRepo(pool)
+	ctx := context.Background()
+
+	if _, err := pool.Exec(ctx, `DELETE FROM booking_policies`); err != nil {
+		t.Skipf("нет таблицы booking_policies: %v", err)
+	}
+
+	p, err := repo.Current(ctx)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if p.Default.PrivateDailyLimit != appbooking.DefaultPolicy().Default.PrivateDailyLimit {
+		t.Fatalf("без версий должна действовать политика по умолчанию")
+	}
+
+	p.Default.PrivateDailyLimit = 5
+	p.Rooms = map[booking.Room]appbooking.Rules{booking.Room256: {PrivateDailyLimit: 1}}
+	saved, err := repo.Save(ctx, p)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	got, err := repo.Current(ctx)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if got.Version != saved.Version || got.Default.PrivateDailyLimit != 5 || got.For(booking.Room256).PrivateDailyLimit != 1 {
+		t.Fatalf("прочитали не то, что сохранили: %+v", got)
+	}
+}
+
+func TestUserPostgresRepo_TelegramUnique(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewUserPostgresRepo(pool)
+	ctx := context.Background()
+
+	a, err := repo.Create(ctx, user.User{Email: "a@edu.hse.ru", TelegramID: "same"})
+	if err != nil {
+		t.Fatalf("Create вернул ошибку: %v", err)
+	}
+	if got, err := repo.GetByTelegram(ctx, "same"); err != nil || got.ID != a.ID {
+		t.Fatalf("GetByTelegram: %+v, %v", got, err)
+	}
+
+	b, err := repo.Create(ctx, user.User{Email: "b@edu.hse.ru"})
+	if err != nil {
+		t.Fatalf("Create вернул ошибку: %v", err)
+	}
+	b.TelegramID = "same"
+	if _, err := repo.Update(ctx, b); !errors.Is(err, user.ErrTelegramTaken) {
+		t.Fatalf("ожидали ErrTelegramTaken, получили %v", err)
+	}
+}
+
+func TestTelegramLinkPostgresRepo_SaveAndTake(t *testing.T) {
+	pool := requireTestDB(t)
+	ctx := context.Background()
+	u, err := pgrepo.NewUserPostgresRepo(pool).Create(ctx, user.User{Email: "link@edu.hse.ru"})
+	if err != nil {
+		t.Fatalf("Create вернул ошибку: %v", err)
+	}
+	repo := pgrepo.NewTelegramLinkPostgresRepo(pool)
+	expires := time.Now().Add(time.Hour).Truncate(time.Microsecond)
+
+	if err := repo.Save(ctx, user.TelegramLink{CodeHash: "old", UserID: u.ID, TelegramID: "first", ExpiresAt: expires}); err != nil {
+		t.Fatalf("Save вернул ошибку: %v", err)
+	}
+	// повторный запрос заменяет прежний код
+	if err := repo.Save(ctx, user.TelegramLink{CodeHash: "new", UserID: u.ID, TelegramID: "second", ExpiresAt: expires}); err != nil {
+		t.Fatalf("Save вернул ошибку: %v", err)
+	}
+	if _, err := repo.Take(ctx, "old"); !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("старый код: ожидали ErrNotFound, получили %v", err)
+	}
+	l, err := repo.Take(ctx, "new")
+	if err != nil || l.UserID != u.ID || l.TelegramID != "second" || !l.ExpiresAt.Equal(expires) {
+		t.Fatalf("Take: %+v, %v", l, err)
+	}
+	if _, err := repo.Take(ctx, "new"); !errors.Is(err, user.ErrNotFound) {
+		t.Fatalf("код одноразовый: ожидали ErrNotFound, получили %v", err)
+	}
+}
+
+func TestOutboxPostgresRepo_DedupAndClaim(t *testing.T) {
+	pool := requireTestDB(t)
+	outbox := pgrepo.NewOutboxPostgresRepo(pool)
+	ctx := context.Background()
+
+	now := time.Now()
+	msg := notification.Message{
+		DedupKey:  "reminder:test:telegram",
+		Kind:      notification.KindReminder,
+		Channel:   notification.ChannelTelegram,
+		Recipient: "42",
+		Body:      "Напоминание",
+		SendAfter: now.Add(-time.Minute),
+	}
+	if err := outbox.Enqueue(ctx, msg, msg); err != nil {
+		t.Fatalf("Enqueue вернул ошибку: %v", err)
+	}
+
+	claimed, err := outbox.Claim(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Claim вернул ошибку: %v", err)
+	}
+	if len(claimed) != 1 {
+		t.Fatalf("ожидали одно сообщение, получили %d", len(claimed))
+	}
+
+	// пока сообщение захвачено, второй обработчик его не видит
+	again, err := outbox.Claim(ctx, now, time.Minute, 10)
+	if err != nil || len(again) != 0 {
+		t.Fatalf("повторный Claim: %d сообщений, %v", len(again), err)
+	}
+
+	if err := outbox.MarkSent(ctx, claimed[0].ID); err != nil {
+		t.Fatalf("MarkSent вернул ошибку: %v", err)
+	}
+	if err := outbox.Enqueue(ctx, msg); err != nil {
+		t.Fatalf("Enqueue вернул ошибку: %v", err)
+	}
+	after, err := outbox.Claim(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	if err != nil || len(after) != 0 {
+		t.Fatalf("отправленное сообщение не должно вернуться: %d, %v", len(after), err)
+	}
+}
+
+func TestAuditPostgresRepo_AppendOnly(t *testing.T) {
+	pool := requireTestDB(t)
+	defer pool.Close()
+	ctx := context.Background()
+	log := pgrepo.NewAuditPostgresRepo(pool)
+
+	bookingID := fmt.Sprintf("audit-%d", time.Now().UnixNano())
+	b := booking.Booking{ID: bookingID, Room: booking.Room21, Title: "Кино", TelegramID: "owner"}
+	if _, err := log.Append(ctx, audit.Entry{Actor: "owner", Action: audit.ActionCreate, BookingID: bookingID, After: &b, IP: "10.0.0.1"}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if _, err := log.Append(ctx, audit.Entry{Actor: "admin", Admin: true, Action: audit.ActionAdminOverride, BookingID: bookingID, Before: &b}); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+
+	entries, err := log.Find(ctx, audit.Filter{BookingID: bookingID})
+	if err != nil || len(entries) != 2 {
+		t.Fatalf("ожидали 2 записи, получили %+v, %v", entries, err)
+	}
+	if entries[0].Action != audit.ActionAdminOverride || entries[0].Before == nil || entries[0].Before.Title != "Кино" || entries[0].After != nil {
+		t.Fatalf("неожиданная запись: %+v", entries[0])
+	}
+
+	if _, err := pool.Exec(ctx, `DELETE FROM audit_log WHERE booking_id = $1`, bookingID); err == nil {
+		t.Fatalf("журнал должен запрещать удаление")
+	}
+}
+
+func TestWaitlistPostgresRepo(t *testing.T) {
+	pool := requireTestDB(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := pgrepo.NewWaitlistPostgresRepo(pool)
+
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	first, err := repo.Add(ctx, booking.WaitlistEntry{Start: start, End: start.Add(time.Hour), Room: booking.Room132, Title: "Кино", TelegramID: "first"})
+	if err != nil || first.Status != booking.WaitlistWaiting {
+		t.Fatalf("Add: %+v, %v", first, err)
+	}
+	second, _ := repo.Add(ctx, booking.WaitlistEntry{Start: start, End: start.Add(time.Hour), Room: booking.Room132, Title: "Кино", TelegramID: "second"})
+
+	until := time.Now().Add(time.Hour)
+	first.Status, first.HoldUntil = booking.WaitlistOffered, &until
+	if _, err := repo.Update(ctx, first); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+
+	waiting, err := repo.Find(ctx, booking.WaitlistFilter{Room: booking.Room132, From: start, To: start.Add(time.Hour),
+		Statuses: []booking.WaitlistStatus{booking.WaitlistWaiting}})
+	if err != nil || len(waiting) != 1 || waiting[0].ID != second.ID {
+		t.Fatalf("ожидали только вторую заявку, получили %+v, %v", waiting, err)
+	}
+	got, err := repo.Get(ctx, first.ID)
+	if err != nil || !got.Holds(time.Now()) {
+		t.Fatalf("первая заявка должна держать слот: %+v, %v", got, err)
+	}
+	if _, err := repo.Get(ctx, "missing"); !errors.Is(err, booking.ErrWaitlistNotFound) {
+		t.Fatalf("ожидали ErrWaitlistNotFound, получили %v", err)
+	}
+}
+
+func TestPostgresRepo_CheckInAndNoShows(t *testing.T) {
+	pool := requireTestDB(t)
+	defer pool.Close()
+	ctx := context.Background()
+	repo := pgrepo.NewBookingPostgresRepo(pool)
+	noShows := pgrepo.NewNoShowPostgresRepo(pool)
+
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	created, err := repo.Create(ctx, booking.Booking{Start: start, End: start.Add(time.Hour), Room: booking.Room132, Title: "Кино", TelegramID: "owner"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	first := start.Add(-5 * time.Minute)
+	checked, err := repo.CheckIn(ctx, created.ID, first)
+	if err != nil || checked.CheckedInAt == nil || !checked.CheckedInAt.Equal(first) {
+		t.Fatalf("CheckIn: %+v, %v", checked, err)
+	}
+	// повторная отметка не сдвигает время первой
+	if again, _ := repo.CheckIn(ctx, created.ID, start); !again.CheckedInAt.Equal(first) {
+		t.Fatalf("повторная отметка изменила время: %v", again.CheckedInAt)
+	}
+	if _, err := repo.CheckIn(ctx, "missing", start); !errors.Is(err, booking.ErrNotFound) {
+		t.Fatalf("ожидали ErrNotFound, получили %v", err)
+	}
+
+	n := booking.NoShow{TelegramID: "owner", BookingID: created.ID, Room: booking.Room132, Start: start}
+	if err := noShows.Record(ctx, n); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if err := noShows.Record(ctx, n); err != nil {
+		t.Fatalf("повторный Record: %v", err)
+	}
+	list, err := noShows.Find(ctx, booking.NoShowFilter{Owner: "owner", From: start, To: start.Add(time.Minute)})
+	if err != nil || len(list) != 1 || list[0].BookingID != created.ID {
+		t.Fatalf("ожидали одну неявку, получили %+v, %v", list, err)
+	}
+}
+
+func TestPostgresRepo_DormitoryIsolation(t *testing.T) {
+	pool := requireTestDB(t)
+	ctx := context.Background()
+	if _, err := pgrepo.NewDormitoryPostgresRepo(pool).Create(ctx, dormitory.Dormitory{ID: "east", Name: "Восточное"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := pgrepo.NewRoomPostgresRepo(pool).ForDormitory("east").Create(ctx, booking.DefaultRooms()[0]); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	main := pgrepo.NewBookingPostgresRepo(pool)
+	east := main.ForDormitory("east")
+	b := booking.Booking{
+		Start:      time.Now(),
+		End:        time.Now().Add(time.Hour),
+		Room:       booking.Room21,
+		Title:      "PG Test",
+		TelegramID: "111",
+	}
+
+	mb, err := main.Create(ctx, b)
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	eb, err := east.Create(ctx, b)
+	if err != nil {
+		t.Fatalf("та же комната в другом общежитии: неожиданная ошибка: %v", err)
+	}
+	if eb.Dormitory != "east" {
+		t.Fatalf("ожидали общежитие east, получили %q", eb.Dormitory)
+	}
+	if _, err := east.Create(ctx, b); !errors.Is(err, booking.ErrOverlap) {
+		t.Fatalf("ожидали ErrOverlap в том же общежитии, получили %v", err)
+	}
+	if _, err := east.Get(ctx, mb.ID); !errors.Is(err, booking.ErrNotFound) {
+		t.Fatalf("бронь main через east: ожидали ErrNotFound, получили %v", err)
+	}
+	all, err := main.ForDormitory("").List(ctx)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("срез по всем общежитиям: ожидали 2 брони, получили %d (%v)", len(all), err)
+	}
+}
+
+func TestEventPostgresBus_ListenNotify(t *testing.T) {
+	pool := requireTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bus := pgrepo.NewEventPostgresBus(pool, memory.NewEventBroker())
+	events := bus.Subscribe(ctx)
+	go bus.Listen(ctx)
+	// LISTEN выполняется в фоне, даём ему успеть
+	time.Sleep(200 * time.Millisecond)
+
+	b, err := pgrepo.NewBookingPostgresRepo(pool).Create(ctx, booking.Booking{
+		Start:      time.Now(),
+		End:        time.Now().Add(time.Hour),
+		Room:       booking.Room21,
+		Title:      "PG Test",
+		TelegramID: "111",
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if err := bus.Publish(ctx, booking.Event{Type: booking.EventCreated, Booking: b}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	select {
+	case e := <-events:
+		if e.Type != booking.EventCreated || e.Booking.ID != b.ID || e.Booking.Title != b.Title {
+			t.Fatalf("ожидали created по брони %s, получили %+v", b.ID, e)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("не дождались события через LISTEN/NOTIFY")
+	}
+}
+
+func TestPostgresRepo_Changes(t *testing.T) {
+	pool := requireTestDB(t)
+	repo := pgrepo.NewBookingPostgresRepo(pool)
+	ctx := context.Background()
+
+	a, err := repo.Create(ctx, booking.Booking{
+		Start:      time.Now(),
+		End:        time.Now().Add(time.Hour),
+		Room:       booking.Room21,
+		Title:      "A",
+		TelegramID: "111",
+	})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	head, err := repo.Changes(ctx, a.Revision-1, 0)
+	if err != nil || len(head.Bookings) != 1 || head.Revision != a.Revision {
+		t.Fatalf("изменения после создания: %+v %v", head, err)
+	}
+
+	// повторная отметка ничего не меняет и ревизию не тратит
+	checked, _ := repo.CheckIn(ctx, a.ID, time.Now())
+	again, _ := repo.CheckIn(ctx, a.ID, time.Now())
+	if checked.Revision <= a.Revision || again.Revision != checked.Revision {
+		t.Fatalf("ревизии отметок: %d, %d после %d", checked.Revision, again.Revision, a.Revision)
+	}
+
+	if _, err := repo.Cancel(ctx, a.ID, booking.Cancellation{At: time.Now()}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	set, err := repo.Changes(ctx, checked.Revision, 0)
+	if err != nil || len(set.Bookings) != 1 || !set.Bookings[0].Cancelled() {
+		t.Fatalf("ожидали надгробие: %+v %v", set, err)
+	}
+
+	if n, err := repo.ExpireTombstones(ctx, time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("ожидали 1 устаревшее надгробие, получили %d (%v)", n, err)
+	}
+	if got, err := repo.Get(ctx, a.ID); err != nil || !got.Cancelled() {
+		t.Fatalf("отменённая бронь должна сохраниться: %+v %v", got, err)
+	}
+	if full, err := repo.Changes(ctx, 0, 0); err != nil || len(full.Bookings) != 0 {
+		t.Fatalf("устаревшее надгробие не должно попадать в полную синхронизацию: %+v %v", full, err)
+	}
+	if _, err := repo.Changes(ctx, checked.Revision, 0); !errors.Is(err, booking.ErrCursorExpired) {
+		t.Fatalf("курсор ниже горизонта: ожидали ErrCursorExpired, получили %v", err)
+	}
+	if _, err := repo.Changes(ctx, set.Revision+1000, 0); !errors.Is(err, booking.ErrCursorExpired) {
+		t.Fatalf("курсор из будущего: ожидали ErrCursorExpired, получили %v", err)
+	}
+}
+
+// Ревизия выдаётся под блокировкой общежития до конца транзакции: пока запись в main
+// не закоммичена, другие записи в main ждут, а в east проходят сразу.
+func TestPostgresRepo_RevisionLockPerDormitory(t *testing.T) {
+	pool := requireTestDB(t)
+	ctx := context.Background()
+	if _, err := pgrepo.NewDormitoryPostgresRepo(pool).Create(ctx, dormitory.Dormitory{ID: "east", Name: "Восточное"}); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	if _, err := pgrepo.NewRoomPostgresRepo(pool).ForDormitory("east").Create(ctx, booking.DefaultRooms()[0]); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	main := pgrepo.NewBookingPostgresRepo(pool)
+	at := func(room booking.Room) booking.Booking {
+		return booking.Booking{
+			Start:      time.Now(),
+			End:        time.Now().Add(time.Hour),
+			Room:       room,
+			Title:      "PG Test",
+			TelegramID: "111",
+		}
+	}
+
+	written, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- main.WithRoomLock(ctx, []booking.Room{booking.Room21}, nil, func(repo booking.Repository) error {
+			if _, err := repo.Create(ctx, at(booking.Room21)); err != nil {
+				return err
+			}
+			close(written)
+			<-release
+			return nil
+		})
+	}()
+	select {
+	case <-written:
+	case err := <-done:
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	short, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	if _, err := main.ForDormitory("east").Create(short, at(booking.Room21)); err != nil {
+		t.Fatalf("другое общежитие не должно ждать чужой транзакции: %v", err)
+	}
+	blocked, cancelBlocked := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer cancelBlocked()
+	if _, err := main.Create(blocked, at(booking.Room132)); err == nil || blocked.Err() == nil {
+		t.Fatalf("запись в том же общежитии должна ждать коммита предыдущей, получили %v", err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+}
+
+func TestPostgresService_ReleaseNoShows(t *testing.T) {
+	pool := requireTestDB(t)
+	ctx := context.Background()
+	repo := pgrepo.NewBookingPostgresRepo(pool)
+
+	policies := appbooking.NewStaticPolicyStore()
+	p := appbooking.DefaultPolicy()
+	p.Default.CheckInGraceMinutes = 15
+	if _, err := policies.Save(ctx, p); err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	svc := appbooking.NewService(repo,
+		appbooking.WithPolicyStore(policies),
+		appbooking.WithNoShowRepo(pgrepo.NewNoShowPostgresRepo(pool)),
+	)
+
+	now := time.Now()
+	missed, err := repo.Create(ctx, booking.Booking{Start: now.Add(-30 * time.Minute), End: now.Add(30 * time.Minute), Room: booking.Room132, Title: "Пусто", TelegramID: "lazy"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+	fresh, err := repo.Create(ctx, booking.Booking{Start: now.Add(-5 * time.Minute), End: now.Add(time.Hour), Room: booking.Room21, Title: "Только начали", TelegramID: "eager"})
+	if err != nil {
+		t.Fatalf("неожиданная ошибка: %v", err)
+	}
+
+	if err := svc.ReleaseNoShows(ctx); err != nil {
+		t.Fatalf("ReleaseNoShows: %v", err)
+	}
+	if b, _ := repo.Get(ctx, missed.ID); !b.Cancelled() || b.CancelReason != booking.ReasonNoShow {
+		t.Fatalf("бронь без отметки должна освободиться: %+v", b)
+	}
+	if b, _ := repo.Get(ctx, fresh.ID); b.Cancelled() {
+		t.Fatalf("бронь в льготном окне не должна освобождаться")
+	}
+}
@@ -0,0 +1,181 @@
+package postgres
+
+// В этом файле лежит каталог комнат поверх таблицы rooms. Ключ комнаты - (общежитие, номер).
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RoomPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewRoomPostgresRepo создаёт каталог комнат общежития по умолчанию поверх пула соединений pgx.
+func NewRoomPostgresRepo(pool *pgxpool.Pool) *RoomPostgresRepo {
+	return &RoomPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - каталог комнат другого общежития.
+func (r *RoomPostgresRepo) ForDormitory(id string) *RoomPostgresRepo {
+	return &RoomPostgresRepo{pool: r.pool, dorm: id}
+}
+
+const roomColumns = `number, name, COALESCE(building, ''), floor, capacity, amenities, active, schedule, COALESCE(checkin_token, '')`
+
+func (r *RoomPostgresRepo) List(ctx context.Context) ([]booking.RoomInfo, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+roomColumns+` FROM rooms WHERE dormitory_id = $1 ORDER BY number`, r.dorm)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []booking.RoomInfo
+	for rows.Next() {
+		room, err := scanRoom(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, room)
+	}
+	return out, rows.Err()
+}
+
+func (r *RoomPostgresRepo) Get(ctx context.Context, number booking.Room) (booking.RoomInfo, error) {
+	room, err := scanRoom(r.pool.QueryRow(ctx, `SELECT `+roomColumns+` FROM rooms WHERE number = $1 AND dormitory_id = $2`, int(number), r.dorm))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return booking.RoomInfo{}, booking.ErrNotFound
+		}
+		return booking.RoomInfo{}, err
+	}
+	return room, nil
+}
+
+func (r *RoomPostgresRepo) GetByCheckInToken(ctx context.Context, token string) (booking.RoomInfo, error) {
+	room, err := scanRoom(r.pool.QueryRow(ctx, `SELECT `+roomColumns+` FROM rooms WHERE checkin_token = $1 AND dormitory_id = $2`, token, r.dorm))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return booking.RoomInfo{}, booking.ErrNotFound
+		}
+		return booking.RoomInfo{}, err
+	}
+	return room, nil
+}
+
+func (r *RoomPostgresRepo) Create(ctx context.Context, room booking.RoomInfo) (booking.RoomInfo, error) {
+	schedule, err := json.Marshal(room.Schedule)
+	if err != nil {
+		return booking.RoomInfo{}, err
+	}
+
+	_, err = r.pool.Exec(ctx,
+		`INSERT INTO rooms (number, name, building, floor, capacity, amenities, active, schedule, checkin_token, dormitory_id)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)`,
+		int(room.Number),
+		room.Name,
+		nullIfEmpty(room.Building),
+		room.Floor,
+		room.Capacity,
+		textArray(room.Amenities),
+		room.Active,
+		string(schedule),
+		nullIfEmpty(room.CheckInToken),
+		r.dorm,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return booking.RoomInfo{}, booking.ErrRoomExists
+		}
+		return booking.RoomInfo{}, err
+	}
+	return room, nil
+}
+
+func (r *RoomPostgresRepo) Update(ctx context.Context, room booking.RoomInfo) (booking.RoomInfo, error) {
+	schedule, err := json.Marshal(room.Schedule)
+	if err != nil {
+		return booking.RoomInfo{}, err
+	}
+
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE rooms
+		 SET name = $2, building = $3, floor = $4, capacity = $5, amenities = $6, active = $7, schedule = $8,
+		     checkin_token = $9
+		 WHERE number = $1 AND dormitory_id = $10`,
+		int(room.Number),
+		room.Name,
+		nullIfEmpty(room.Building),
+		room.Floor,
+		room.Capacity,
+		textArray(room.Amenities),
+		room.Active,
+		string(schedule),
+		nullIfEmpty(room.CheckInToken),
+		r.dorm,
+	)
+	if err != nil {
+		return booking.RoomInfo{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return booking.RoomInfo{}, booking.ErrNotFound
+	}
+	return room, nil
+}
+
+func (r *RoomPostgresRepo) Delete(ctx context.Context, number booking.Room) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM rooms WHERE number = $1 AND dormitory_id = $2`, int(number), r.dorm)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return booking.ErrRoomInUse
+		}
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return booking.ErrNotFound
+	}
+	return nil
+}
+
+func scanRoom(row pgx.Row) (booking.RoomInfo, error) {
+	var (
+		room     booking.RoomInfo
+		schedule []byte
+	)
+	if err := row.Scan(
+		&room.Number,
+		&room.Name,
+		&room.Building,
+		&room.Floor,
+		&room.Capacity,
+		&room.Amenities,
+		&room.Active,
+		&schedule,
+		&room.CheckInToken,
+	); err != nil {
+		return booking.RoomInfo{}, err
+	}
+	if err := json.Unmarshal(schedule, &room.Schedule); err != nil {
+		return booking.RoomInfo{}, err
+	}
+	return room, nil
+}
+
+// textArray - значение для колонки TEXT[] NOT NULL: nil в ней не пройдёт.
+func textArray(a []string) []string {
+	if a == nil {
+		return []string{}
+	}
+	return a
+}
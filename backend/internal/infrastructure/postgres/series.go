@@ -0,0 +1,137 @@
+package postgres
+
+// В этом файле лежит хранилище серий бронирований (таблица booking_series).
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SeriesPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewSeriesPostgresRepo создаёт хранилище серий общежития по умолчанию поверх пула соединений pgx.
+func NewSeriesPostgresRepo(pool *pgxpool.Pool) *SeriesPostgresRepo {
+	return &SeriesPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - то же хранилище, но с сериями другого общежития.
+func (r *SeriesPostgresRepo) ForDormitory(id string) *SeriesPostgresRepo {
+	return &SeriesPostgresRepo{pool: r.pool, dorm: id}
+}
+
+func (r *SeriesPostgresRepo) Create(ctx context.Context, s booking.Series) (booking.Series, error) {
+	if s.ID == "" {
+		s.ID = uuid.NewString()
+	}
+
+	weekdays := make([]int32, 0, len(s.Rule.Weekdays))
+	for _, wd := range s.Rule.Weekdays {
+		weekdays = append(weekdays, int32(wd))
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO booking_series (id, start_at, end_at, room, title, description, telegram_id, is_private,
+		                             freq, weekdays, until_at, occurrences, dormitory_id)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		s.ID,
+		s.Start,
+		s.End,
+		int(s.Room),
+		s.Title,
+		nullIfEmpty(s.Description),
+		s.TelegramID,
+		s.IsPrivate,
+		string(s.Rule.Freq),
+		weekdays,
+		nullIfZero(s.Rule.Until),
+		s.Rule.Count,
+		r.dorm,
+	)
+	if err != nil {
+		return booking.Series{}, err
+	}
+
+	return s, nil
+}
+
+func (r *SeriesPostgresRepo) Get(ctx context.Context, id string) (booking.Series, error) {
+	var (
+		s        booking.Series
+		freq     string
+		weekdays []int32
+		until    *time.Time
+	)
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, start_at, end_at, room, title, COALESCE(description, ''), telegram_id, is_private,
+		        freq, weekdays, until_at, occurrences, ended_at
+		 FROM booking_series
+		 WHERE id = $1 AND dormitory_id = $2`,
+		id,
+		r.dorm,
+	).Scan(
+		&s.ID,
+		&s.Start,
+		&s.End,
+		&s.Room,
+		&s.Title,
+		&s.Description,
+		&s.TelegramID,
+		&s.IsPrivate,
+		&freq,
+		&weekdays,
+		&until,
+		&s.Rule.Count,
+		&s.EndedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return booking.Series{}, booking.ErrNotFound
+		}
+		return booking.Series{}, err
+	}
+
+	s.Rule.Freq = booking.Frequency(freq)
+	for _, wd := range weekdays {
+		s.Rule.Weekdays = append(s.Rule.Weekdays, time.Weekday(wd))
+	}
+	if until != nil {
+		s.Rule.Until = *until
+	}
+	return s, nil
+}
+
+func (r *SeriesPostgresRepo) End(ctx context.Context, id string, at time.Time) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE booking_series SET ended_at = COALESCE(ended_at, $3) WHERE id = $1 AND dormitory_id = $2`,
+		id, r.dorm, at,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return booking.ErrNotFound
+	}
+	return nil
+}
+
+func (r *SeriesPostgresRepo) Delete(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM booking_series WHERE id = $1 AND dormitory_id = $2`, id, r.dorm)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return booking.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,229 @@
+package postgres
+
+// В этом файле хранилища пользователей и кодов входа (таблицы users и login_codes).
+
+import (
+	"context"
+	"errors"
+
+	"Dormitory_Booking/internal/domain/user"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewUserPostgresRepo создаёт хранилище пользователей поверх пула соединений pgx.
+func NewUserPostgresRepo(pool *pgxpool.Pool) *UserPostgresRepo {
+	return &UserPostgresRepo{pool: pool}
+}
+
+const userColumns = `id::text, email, COALESCE(telegram_id, ''), COALESCE(telegram_chat_id, 0), COALESCE(calendar_token, ''), created_at`
+
+func (r *UserPostgresRepo) Get(ctx context.Context, id string) (user.User, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return user.User{}, user.ErrNotFound
+	}
+	return r.getBy(ctx, `id = $1`, id)
+}
+
+func (r *UserPostgresRepo) GetByEmail(ctx context.Context, email string) (user.User, error) {
+	return r.getBy(ctx, `email = $1`, email)
+}
+
+func (r *UserPostgresRepo) GetByTelegram(ctx context.Context, telegramID string) (user.User, error) {
+	if telegramID == "" {
+		return user.User{}, user.ErrNotFound
+	}
+	return r.getBy(ctx, `telegram_id = $1`, telegramID)
+}
+
+func (r *UserPostgresRepo) GetByCalendarToken(ctx context.Context, token string) (user.User, error) {
+	if token == "" {
+		return user.User{}, user.ErrNotFound
+	}
+	return r.getBy(ctx, `calendar_token = $1`, token)
+}
+
+func (r *UserPostgresRepo) Create(ctx context.Context, u user.User) (user.User, error) {
+	if u.ID == "" {
+		u.ID = uuid.NewString()
+	}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO users (id, email, telegram_id, telegram_chat_id, calendar_token) VALUES ($1, $2, $3, $4, $5) RETURNING created_at`,
+		u.ID, u.Email, nullIfEmpty(u.TelegramID), nullIfZeroID(u.TelegramChatID), nullIfEmpty(u.CalendarToken),
+	).Scan(&u.CreatedAt)
+	if err != nil {
+		return user.User{}, mapUserError(err)
+	}
+	return u, nil
+}
+
+func (r *UserPostgresRepo) Update(ctx context.Context, u user.User) (user.User, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE users SET email = $2, telegram_id = $3, telegram_chat_id = $4, calendar_token = $5 WHERE id = $1`,
+		u.ID, u.Email, nullIfEmpty(u.TelegramID), nullIfZeroID(u.TelegramChatID), nullIfEmpty(u.CalendarToken),
+	)
+	if err != nil {
+		return user.User{}, mapUserError(err)
+	}
+	if tag.RowsAffected() == 0 {
+		return user.User{}, user.ErrNotFound
+	}
+	return u, nil
+}
+
+func (r *UserPostgresRepo) getBy(ctx context.Context, where string, arg any) (user.User, error) {
+	var u user.User
+	err := r.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE `+where, arg).
+		Scan(&u.ID, &u.Email, &u.TelegramID, &u.TelegramChatID, &u.CalendarToken, &u.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.User{}, user.ErrNotFound
+		}
+		return user.User{}, err
+	}
+	return u, nil
+}
+
+func nullIfZeroID(id int64) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// mapUserError переводит нарушение уникальности telegram_id в доменную ошибку.
+func mapUserError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_telegram_id_key" {
+		return user.ErrTelegramTaken
+	}
+	return err
+}
+
+type LoginCodePostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewLoginCodePostgresRepo создаёт хранилище кодов входа поверх пула соединений pgx.
+func NewLoginCodePostgresRepo(pool *pgxpool.Pool) *LoginCodePostgresRepo {
+	return &LoginCodePostgresRepo{pool: pool}
+}
+
+func (r *LoginCodePostgresRepo) Save(ctx context.Context, c user.LoginCode) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO login_codes (email, code_hash, expires_at, attempts) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (email) DO UPDATE
+		 SET code_hash = EXCLUDED.code_hash, expires_at = EXCLUDED.expires_at, attempts = EXCLUDED.attempts`,
+		c.Email, c.CodeHash, c.ExpiresAt, c.Attempts,
+	)
+	return err
+}
+
+func (r *LoginCodePostgresRepo) Get(ctx context.Context, email string) (user.LoginCode, error) {
+	c := user.LoginCode{Email: email}
+	err := r.pool.QueryRow(ctx,
+		`SELECT code_hash, expires_at, attempts FROM login_codes WHERE email = $1`, email,
+	).Scan(&c.CodeHash, &c.ExpiresAt, &c.Attempts)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.LoginCode{}, user.ErrNotFound
+		}
+		return user.LoginCode{}, err
+	}
+	return c, nil
+}
+
+func (r *LoginCodePostgresRepo) Delete(ctx context.Context, email string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM login_codes WHERE email = $1`, email)
+	return err
+}
+
+type TelegramLinkPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewTelegramLinkPostgresRepo создаёт хранилище неподтверждённых привязок Telegram.
+func NewTelegramLinkPostgresRepo(pool *pgxpool.Pool) *TelegramLinkPostgresRepo {
+	return &TelegramLinkPostgresRepo{pool: pool}
+}
+
+func (r *TelegramLinkPostgresRepo) Save(ctx context.Context, l user.TelegramLink) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO telegram_links (code_hash, user_id, telegram_id, expires_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET code_hash = EXCLUDED.code_hash, telegram_id = EXCLUDED.telegram_id, expires_at = EXCLUDED.expires_at`,
+		l.CodeHash, l.UserID, l.TelegramID, l.ExpiresAt,
+	)
+	return err
+}
+
+func (r *TelegramLinkPostgresRepo) Take(ctx context.Context, codeHash string) (user.TelegramLink, error) {
+	l := user.TelegramLink{CodeHash: codeHash}
+	err := r.pool.QueryRow(ctx,
+		`DELETE FROM telegram_links WHERE code_hash = $1 RETURNING user_id::text, telegram_id, expires_at`, codeHash,
+	).Scan(&l.UserID, &l.TelegramID, &l.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.TelegramLink{}, user.ErrNotFound
+		}
+		return user.TelegramLink{}, err
+	}
+	return l, nil
+}
+
+type SessionPostgresRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewSessionPostgresRepo создаёт хранилище сессий поверх пула соединений pgx.
+func NewSessionPostgresRepo(pool *pgxpool.Pool) *SessionPostgresRepo {
+	return &SessionPostgresRepo{pool: pool}
+}
+
+func (r *SessionPostgresRepo) Create(ctx context.Context, s user.Session) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO sessions (id, user_id, created_at, expires_at) VALUES ($1, $2, $3, $4)`,
+		s.ID, s.UserID, s.CreatedAt, s.ExpiresAt,
+	)
+	return err
+}
+
+func (r *SessionPostgresRepo) Get(ctx context.Context, id string) (user.Session, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return user.Session{}, user.ErrNotFound
+	}
+	s := user.Session{ID: id}
+	err := r.pool.QueryRow(ctx,
+		`SELECT user_id::text, created_at, expires_at, revoked_at IS NOT NULL FROM sessions WHERE id = $1`, id,
+	).Scan(&s.UserID, &s.CreatedAt, &s.ExpiresAt, &s.Revoked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return user.Session{}, user.ErrNotFound
+		}
+		return user.Session{}, err
+	}
+	return s, nil
+}
+
+func (r *SessionPostgresRepo) Revoke(ctx context.Context, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return err
+}
+
+func (r *SessionPostgresRepo) RevokeAll(ctx context.Context, userID string) error {
+	if _, err := uuid.Parse(userID); err != nil {
+		return nil
+	}
+	_, err := r.pool.Exec(ctx, `UPDATE sessions SET revoked_at = now() WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+	return err
+}
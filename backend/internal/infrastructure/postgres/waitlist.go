@@ -0,0 +1,170 @@
+package postgres
+
+// В этом файле очередь ожидания на занятое время (таблица waitlist).
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/domain/dormitory"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WaitlistPostgresRepo struct {
+	pool *pgxpool.Pool
+	dorm string
+}
+
+// NewWaitlistPostgresRepo создаёт очередь ожидания общежития по умолчанию поверх пула соединений pgx.
+func NewWaitlistPostgresRepo(pool *pgxpool.Pool) *WaitlistPostgresRepo {
+	return &WaitlistPostgresRepo{pool: pool, dorm: dormitory.DefaultID}
+}
+
+// ForDormitory - очередь ожидания другого общежития.
+func (r *WaitlistPostgresRepo) ForDormitory(id string) *WaitlistPostgresRepo {
+	return &WaitlistPostgresRepo{pool: r.pool, dorm: id}
+}
+
+const waitlistColumns = `id, start_at, end_at, room, title, COALESCE(description, ''), telegram_id, is_private,
+	created_at, status, hold_until, COALESCE(booking_id, '')`
+
+func waitlistDest(e *booking.WaitlistEntry) []any {
+	return []any{
+		&e.ID,
+		&e.Start,
+		&e.End,
+		&e.Room,
+		&e.Title,
+		&e.Description,
+		&e.TelegramID,
+		&e.IsPrivate,
+		&e.CreatedAt,
+		&e.Status,
+		&e.HoldUntil,
+		&e.BookingID,
+	}
+}
+
+func (r *WaitlistPostgresRepo) Add(ctx context.Context, e booking.WaitlistEntry) (booking.WaitlistEntry, error) {
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	if e.Status == "" {
+		e.Status = booking.WaitlistWaiting
+	}
+
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO waitlist (id, start_at, end_at, room, title, description, telegram_id, is_private,
+		                       created_at, status, hold_until, booking_id, dormitory_id)
+		 VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)`,
+		e.ID,
+		e.Start,
+		e.End,
+		int(e.Room),
+		e.Title,
+		nullIfEmpty(e.Description),
+		e.TelegramID,
+		e.IsPrivate,
+		e.CreatedAt,
+		string(e.Status),
+		e.HoldUntil,
+		nullIfEmpty(e.BookingID),
+		r.dorm,
+	)
+	if err != nil {
+		return booking.WaitlistEntry{}, err
+	}
+	return e, nil
+}
+
+func (r *WaitlistPostgresRepo) Get(ctx context.Context, id string) (booking.WaitlistEntry, error) {
+	var e booking.WaitlistEntry
+	err := r.pool.QueryRow(ctx, `SELECT `+waitlistColumns+` FROM waitlist WHERE id = $1 AND dormitory_id = $2`, id, r.dorm).Scan(waitlistDest(&e)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return booking.WaitlistEntry{}, booking.ErrWaitlistNotFound
+	}
+	if err != nil {
+		return booking.WaitlistEntry{}, err
+	}
+	return e, nil
+}
+
+func (r *WaitlistPostgresRepo) Find(ctx context.Context, f booking.WaitlistFilter) ([]booking.WaitlistEntry, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conds = append(conds, "dormitory_id = "+arg(r.dorm))
+	if !f.From.IsZero() {
+		conds = append(conds, "end_at > "+arg(f.From))
+	}
+	if !f.To.IsZero() {
+		conds = append(conds, "start_at < "+arg(f.To))
+	}
+	if f.Room != 0 {
+		conds = append(conds, "room = "+arg(int(f.Room)))
+	}
+	if f.Owner != "" {
+		conds = append(conds, "telegram_id = "+arg(f.Owner))
+	}
+	if len(f.Statuses) > 0 {
+		statuses := make([]string, 0, len(f.Statuses))
+		for _, s := range f.Statuses {
+			statuses = append(statuses, string(s))
+		}
+		conds = append(conds, "status = ANY("+arg(statuses)+")")
+	}
+
+	query := `SELECT ` + waitlistColumns + ` FROM waitlist WHERE ` + strings.Join(conds, " AND ")
+	query += ` ORDER BY created_at, id`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []booking.WaitlistEntry
+	for rows.Next() {
+		var e booking.WaitlistEntry
+		if err := rows.Scan(waitlistDest(&e)...); err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// Update меняет состояние заявки; сама просьба (время, комната) после постановки не меняется.
+func (r *WaitlistPostgresRepo) Update(ctx context.Context, e booking.WaitlistEntry) (booking.WaitlistEntry, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE waitlist SET status = $2, hold_until = $3, booking_id = $4 WHERE id = $1 AND dormitory_id = $5`,
+		e.ID,
+		string(e.Status),
+		e.HoldUntil,
+		nullIfEmpty(e.BookingID),
+		r.dorm,
+	)
+	if err != nil {
+		return booking.WaitlistEntry{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return booking.WaitlistEntry{}, booking.ErrWaitlistNotFound
+	}
+	return e, nil
+}
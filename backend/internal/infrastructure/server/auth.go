@@ -0,0 +1,186 @@
+package server
+
+// В этом файле HTTP-обработчики входа по почте и сессии пользователя.
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	domainuser "Dormitory_Booking/internal/domain/user"
+)
+
+const sessionCookie = "session"
+
+// RequestLogin отправляет код и ссылку для входа на корпоративную почту.
+func (h *Handlers) RequestLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+	if !h.allowLoginAttempt(w, r) {
+		return
+	}
+
+	if err := h.auth.RequestLogin(r.Context(), body.Email); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// VerifyLogin принимает код из письма и ставит cookie сессии.
+func (h *Handlers) VerifyLogin(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Email string `json:"email"`
+		Code  string `json:"code"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+	if !h.allowLoginAttempt(w, r) {
+		return
+	}
+
+	sess, err := h.auth.VerifyLogin(r.Context(), body.Email, body.Code)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.setSessionCookie(w, sess.Token, sess.ExpiresAt)
+	writeJSON(w, sess.User)
+}
+
+// VerifyLink - переход по ссылке из письма: ставим cookie и уводим на фронтенд.
+func (h *Handlers) VerifyLink(w http.ResponseWriter, r *http.Request) {
+	if !h.allowLoginAttempt(w, r) {
+		return
+	}
+	q := r.URL.Query()
+	sess, err := h.auth.VerifyLogin(r.Context(), q.Get("email"), q.Get("code"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	h.setSessionCookie(w, sess.Token, sess.ExpiresAt)
+	http.Redirect(w, r, h.loginRedirect, http.StatusFound)
+}
+
+// Logout отзывает текущую сессию и стирает cookie.
+func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	if c, err := r.Cookie(sessionCookie); err == nil && c.Value != "" {
+		if err := h.auth.Logout(r.Context(), c.Value); err != nil {
+			writeError(w, r, err)
+			return
+		}
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+	w.WriteHeader(http.StatusOK)
+}
+
+// Me возвращает текущего пользователя.
+func (h *Handlers) Me(w http.ResponseWriter, r *http.Request) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, meDTO{User: u, IsAdmin: h.isAdmin(r)})
+}
+
+type meDTO struct {
+	domainuser.User
+	IsAdmin bool `json:"isAdmin"`
+}
+
+// LinkTelegram начинает привязку Telegram к текущему пользователю: отвечает 202 и кодом,
+// который надо отправить боту. Сама привязка (от неё считается владелец броней)
+// появится, когда бот получит код с этого ника.
+func (h *Handlers) LinkTelegram(w http.ResponseWriter, r *http.Request) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var body struct {
+		TelegramID string `json:"telegramId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	req, err := h.auth.RequestTelegramLink(r.Context(), u.ID, body.TelegramID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	_ = json.NewEncoder(w).Encode(req)
+}
+
+// currentUser достаёт пользователя из cookie сессии.
+func (h *Handlers) currentUser(r *http.Request) (domainuser.User, error) {
+	c, err := r.Cookie(sessionCookie)
+	if err != nil || c.Value == "" {
+		return domainuser.User{}, domainuser.ErrUnauthorized
+	}
+	return h.auth.Authenticate(r.Context(), c.Value)
+}
+
+// requester возвращает Telegram вошедшего пользователя - от его имени создаются и меняются брони.
+func (h *Handlers) requester(r *http.Request) (string, error) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return "", err
+	}
+	if u.TelegramID == "" {
+		return "", domainuser.ErrTelegramNotLinked
+	}
+	return u.TelegramID, nil
+}
+
+// viewer - Telegram того, кто смотрит; пусто у анонима. От него зависит, видны ли
+// подробности частных броней.
+func (h *Handlers) viewer(r *http.Request) string {
+	u, err := h.currentUser(r)
+	if err != nil {
+		return ""
+	}
+	return u.TelegramID
+}
+
+// allowLoginAttempt ограничивает попытки входа с одного IP, чтобы коды нельзя было перебирать.
+func (h *Handlers) allowLoginAttempt(w http.ResponseWriter, r *http.Request) bool {
+	if !h.loginThrottle.Allow(h.clientIP(r)) {
+		writeError(w, r, domainuser.ErrTooManyAttempts)
+		return false
+	}
+	return true
+}
+
+func (h *Handlers) setSessionCookie(w http.ResponseWriter, token string, expires time.Time) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    token,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   h.secureCookies,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
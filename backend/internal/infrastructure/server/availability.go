@@ -0,0 +1,54 @@
+package server
+
+// В этом файле поиск свободного времени для ботов и интеграций.
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// Availability - GET /availability?room=21&date=2025-03-14&minDuration=60&private=true.
+// minDuration в минутах, дата считается в поясе общежития.
+func (h *Handlers) Availability(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	room, err := strconv.Atoi(q.Get("room"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid room")
+		return
+	}
+	day, err := time.ParseInLocation("2006-01-02", q.Get("date"), h.location(r))
+	if err != nil {
+		writeBadRequest(w, r, "invalid date")
+		return
+	}
+	query := appbooking.AvailabilityQuery{Room: domain.Room(room), Day: day}
+	if v := q.Get("minDuration"); v != "" {
+		minutes, err := strconv.Atoi(v)
+		if err != nil || minutes < 0 {
+			writeBadRequest(w, r, "invalid minDuration")
+			return
+		}
+		query.MinDuration = time.Duration(minutes) * time.Minute
+	}
+	if v := q.Get("private"); v != "" {
+		if query.Private, err = strconv.ParseBool(v); err != nil {
+			writeBadRequest(w, r, "invalid private")
+			return
+		}
+	}
+
+	slots, err := h.bookings(r).Availability(r.Context(), query)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if slots == nil {
+		slots = []appbooking.Slot{}
+	}
+	writeJSON(w, slots)
+}
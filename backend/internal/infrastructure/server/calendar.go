@@ -0,0 +1,115 @@
+package server
+
+// В этом файле календарные подписки: личная лента по токену, лента комнаты и .ics одной брони.
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	"Dormitory_Booking/internal/infrastructure/ical"
+)
+
+// окно ленты: месяц истории и год вперёд
+const (
+	feedPast   = 30 * 24 * time.Hour
+	feedFuture = 365 * 24 * time.Hour
+)
+
+// CalendarLink возвращает адрес личной подписки. POST с ?reset=true выпускает новый токен.
+func (h *Handlers) CalendarLink(w http.ResponseWriter, r *http.Request) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	reset := r.Method == http.MethodPost && r.URL.Query().Get("reset") == "true"
+	token, err := h.auth.CalendarToken(r.Context(), u.ID, reset)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]string{"url": h.publicURL + linkPrefix(r) + "/calendar/users/" + token + ".ics"})
+}
+
+// UserFeed - брони пользователя, включая его частные, с названиями.
+func (h *Handlers) UserFeed(w http.ResponseWriter, r *http.Request) {
+	u, err := h.auth.UserByCalendarToken(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if u.TelegramID == "" {
+		writeCalendar(w, ical.Calendar{Name: "Мои брони", Location: h.location(r)}, "")
+		return
+	}
+
+	now := time.Now()
+	list, err := h.bookings(r).FindBookings(r.Context(), domain.ListFilter{From: now.Add(-feedPast), To: now.Add(feedFuture), Owner: u.TelegramID})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	cal := ical.Calendar{Name: "Мои брони", Location: h.location(r)}
+	for _, b := range list {
+		cal.Events = append(cal.Events, ical.FromBooking(appbooking.Project(b, u.TelegramID, false)))
+	}
+	writeCalendar(w, cal, "")
+}
+
+// RoomFeed - публичная лента комнаты. Частные брони в ней только как «Занято».
+func (h *Handlers) RoomFeed(w http.ResponseWriter, r *http.Request) {
+	number, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid room")
+		return
+	}
+	room, err := h.bookings(r).GetRoom(r.Context(), domain.Room(number))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	now := time.Now()
+	list, err := h.bookings(r).FindBookings(r.Context(), domain.ListFilter{From: now.Add(-feedPast), To: now.Add(feedFuture), Room: room.Number})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	cal := ical.Calendar{Name: room.Name, Location: h.location(r)}
+	for _, b := range list {
+		cal.Events = append(cal.Events, ical.FromBooking(appbooking.Project(b, "", false)))
+	}
+	writeCalendar(w, cal, "")
+}
+
+// wantsICS - клиент просит .ics: /bookings/{id}.ics или Accept: text/calendar.
+func wantsICS(r *http.Request, id string) (string, bool) {
+	if strings.HasSuffix(id, ".ics") {
+		return strings.TrimSuffix(id, ".ics"), true
+	}
+	return id, strings.Contains(r.Header.Get("Accept"), "text/calendar")
+}
+
+// writeBookingICS отдаёт одну бронь файлом. Чужая частная бронь - только «Занято».
+func (h *Handlers) writeBookingICS(w http.ResponseWriter, r *http.Request, b domain.Booking) {
+	b = appbooking.Project(b, h.viewer(r), h.isAdmin(r))
+	cal := ical.Calendar{Location: h.location(r), Events: []ical.Event{ical.FromBooking(b)}}
+	writeCalendar(w, cal, "booking-"+b.ID+".ics")
+}
+
+func writeCalendar(w http.ResponseWriter, cal ical.Calendar, filename string) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if filename != "" {
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	}
+	_ = ical.Encode(w, cal)
+}
@@ -0,0 +1,65 @@
+package server
+
+// В этом файле инкрементальная синхронизация броней для бота и скриптов календаря.
+
+import (
+	"net/http"
+	"strconv"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+)
+
+// changesResponse - страница изменений. Revision передаётся в since следующего запроса;
+// при more=true изменения ещё есть и запрашивать надо сразу.
+type changesResponse struct {
+	Revision int64                   `json:"revision"`
+	More     bool                    `json:"more"`
+	Bookings []appbooking.BookingDTO `json:"bookings"` // созданные и изменённые, в том виде, что положен зрителю
+	Deleted  []string                `json:"deleted"`  // ID отменённых броней
+}
+
+// Changes - GET /bookings/changes?since=<revision>&limit=<n>. Без since - всё с начала.
+// Устаревший курсор - 410 CURSOR_EXPIRED: клиент сбрасывает своё состояние и начинает с since=0.
+func (h *Handlers) Changes(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var since int64
+	if v := q.Get("since"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 0 {
+			writeBadRequest(w, r, "invalid since")
+			return
+		}
+		since = n
+	}
+	limit := 0
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeBadRequest(w, r, "invalid limit")
+			return
+		}
+		limit = n
+	}
+
+	set, err := h.bookings(r).Changes(r.Context(), since, limit)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	viewer, isAdmin := h.viewer(r), h.isAdmin(r)
+	out := changesResponse{
+		Revision: set.Revision,
+		More:     set.More,
+		Bookings: make([]appbooking.BookingDTO, 0, len(set.Bookings)),
+		Deleted:  make([]string, 0),
+	}
+	for _, b := range set.Bookings {
+		if b.Cancelled() {
+			out.Deleted = append(out.Deleted, b.ID)
+			continue
+		}
+		out.Bookings = append(out.Bookings, appbooking.ToDTO(b, viewer, isAdmin))
+	}
+	writeJSON(w, out)
+}
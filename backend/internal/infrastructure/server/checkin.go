@@ -0,0 +1,108 @@
+package server
+
+// В этом файле отметка о приходе на бронь, QR-коды комнат и список неявок для админки.
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// CheckIn - POST /bookings/{id}/checkin: владелец отмечается, что пришёл.
+func (h *Handlers) CheckIn(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	b, err := h.bookings(r).CheckIn(r.Context(), chi.URLParam(r, "id"), requesterID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, appbooking.ToDTO(b, requesterID, h.isAdmin(r)))
+}
+
+// CheckInByQR - /checkin/{token}: адрес из QR-кода на двери. Камера телефона открывает
+// его GET-запросом в браузере, где уже есть cookie сессии, поэтому принимаем и GET.
+func (h *Handlers) CheckInByQR(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	b, err := h.bookings(r).CheckInByRoomToken(r.Context(), chi.URLParam(r, "token"), requesterID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, appbooking.ToDTO(b, requesterID, h.isAdmin(r)))
+}
+
+// RoomCheckIn - /admin/rooms/{number}/checkin: адрес для QR-кода комнаты.
+// POST выпускает новый токен, старый QR-код перестаёт работать.
+func (h *Handlers) RoomCheckIn(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	number, ok := roomParam(w, r)
+	if !ok {
+		return
+	}
+
+	token, err := h.bookings(r).RoomCheckInToken(r.Context(), number, r.Method == http.MethodPost)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, map[string]any{
+		"room":  int(number),
+		"token": token,
+		"url":   h.publicURL + linkPrefix(r) + "/checkin/" + token,
+	})
+}
+
+// NoShows - GET /admin/no-shows?user=&from=&to=: неявки, по началу брони.
+func (h *Handlers) NoShows(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+
+	q := r.URL.Query()
+	f := domain.NoShowFilter{Owner: q.Get("user")}
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeBadRequest(w, r, "invalid from")
+			return
+		}
+		f.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeBadRequest(w, r, "invalid to")
+			return
+		}
+		f.To = to
+	}
+
+	list, err := h.bookings(r).NoShows(r.Context(), f)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if list == nil {
+		list = []domain.NoShow{}
+	}
+	writeJSON(w, list)
+}
@@ -0,0 +1,103 @@
+package server
+
+// В этом файле адрес клиента за обратным прокси. Заголовкам X-Forwarded-For и X-Real-IP
+// верим, только если соединение пришло от доверенного прокси (TRUSTED_PROXIES):
+// иначе любой подставит чужой IP и обойдёт ограничение попыток входа.
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// WithTrustedProxies задаёт прокси, которым можно верить в X-Forwarded-For и X-Real-IP.
+// Без них адрес клиента - адрес соединения.
+func WithTrustedProxies(proxies []netip.Prefix) Option {
+	return func(h *Handlers) {
+		h.trustedProxies = proxies
+	}
+}
+
+// ParseTrustedProxies разбирает список через запятую из подсетей и отдельных адресов:
+// "10.0.0.0/8, 127.0.0.1".
+func ParseTrustedProxies(v string) ([]netip.Prefix, error) {
+	var out []netip.Prefix
+	for _, item := range strings.Split(v, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		if strings.Contains(item, "/") {
+			p, err := netip.ParsePrefix(item)
+			if err != nil {
+				return nil, fmt.Errorf("доверенный прокси %q: %w", item, err)
+			}
+			out = append(out, p.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(item)
+		if err != nil {
+			return nil, fmt.Errorf("доверенный прокси %q: %w", item, err)
+		}
+		addr = addr.Unmap()
+		out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+	}
+	return out, nil
+}
+
+// clientIP - адрес клиента. Если соединение от доверенного прокси, идём по
+// X-Forwarded-For справа налево мимо доверенных адресов: первый недоверенный и есть
+// клиент (левее него значения мог подставить сам клиент). Без X-Forwarded-For
+// берём X-Real-IP.
+func (h *Handlers) clientIP(r *http.Request) string {
+	remote := remoteIP(r)
+	if !h.trusted(remote) {
+		return remote
+	}
+
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				break
+			}
+			client = hop
+			if !h.trusted(hop) {
+				break
+			}
+		}
+		return client
+	}
+	if real := strings.TrimSpace(r.Header.Get("X-Real-IP")); real != "" {
+		if _, err := netip.ParseAddr(real); err == nil {
+			return real
+		}
+	}
+	return remote
+}
+
+func (h *Handlers) trusted(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, p := range h.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP - адрес соединения без порта.
+func remoteIP(r *http.Request) string {
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
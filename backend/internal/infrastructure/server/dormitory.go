@@ -0,0 +1,168 @@
+package server
+
+// В этом файле выбор общежития для запроса и каталог общежитий.
+// Общежитие берётся из префикса пути /d/{dormitory}/..., иначе по домену запроса,
+// иначе это общежитие по умолчанию - так старые клиенты работают без изменений.
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	appdorm "Dormitory_Booking/internal/application/dormitory"
+	"Dormitory_Booking/internal/domain/apperror"
+	domaindorm "Dormitory_Booking/internal/domain/dormitory"
+)
+
+// WithDormitories включает несколько общежитий: каждый запрос работает с сервисом
+// бронирования своего общежития, а права админа проверяются по нему же.
+func WithDormitories(d *appdorm.Service) Option {
+	return func(h *Handlers) {
+		h.dorms = d
+	}
+}
+
+type tenantKey struct{}
+
+// tenant - общежитие запроса и его сервис бронирования.
+type tenant struct {
+	dorm     domaindorm.Dormitory
+	bookings *appbooking.Service
+	byPath   bool // общежитие указано в пути, ссылки надо строить с тем же префиксом
+}
+
+// withDormitory выбирает общежитие по префиксу пути или по домену.
+func (h *Handlers) withDormitory(byPath bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := domaindorm.DefaultID
+			if byPath {
+				id = chi.URLParam(r, "dormitory")
+			} else {
+				d, ok, err := h.dorms.ByHost(r.Context(), r.Host)
+				if err != nil {
+					writeError(w, r, err)
+					return
+				}
+				if ok {
+					id = d.ID
+				}
+			}
+
+			svc, d, err := h.dorms.Bookings(r.Context(), id)
+			if err != nil {
+				writeError(w, r, err)
+				return
+			}
+			ctx := context.WithValue(r.Context(), tenantKey{}, tenant{dorm: d, bookings: svc, byPath: byPath})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func tenantOf(r *http.Request) (tenant, bool) {
+	t, ok := r.Context().Value(tenantKey{}).(tenant)
+	return t, ok
+}
+
+// bookings - сервис бронирования общежития, к которому относится запрос.
+func (h *Handlers) bookings(r *http.Request) *appbooking.Service {
+	if t, ok := tenantOf(r); ok {
+		return t.bookings
+	}
+	return h.svc
+}
+
+// location - пояс общежития запроса, в нём разбираются даты и пишутся календари.
+func (h *Handlers) location(r *http.Request) *time.Location {
+	if t, ok := tenantOf(r); ok {
+		return t.bookings.Location()
+	}
+	return h.loc
+}
+
+// linkPrefix - префикс для ссылок, которые отдаются наружу (QR-коды, подписки на календарь).
+func linkPrefix(r *http.Request) string {
+	if t, ok := tenantOf(r); ok && t.byPath {
+		return "/d/" + t.dorm.ID
+	}
+	return ""
+}
+
+// dormitoryAdmin - назначен ли пользователь с этой почтой админом общежития запроса.
+func dormitoryAdmin(r *http.Request, email string) bool {
+	t, ok := tenantOf(r)
+	return ok && t.dorm.IsAdmin(email)
+}
+
+// publicDormitory - общежитие без списка админов: его видят все.
+type publicDormitory struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Hosts    []string `json:"hosts,omitempty"`
+	Timezone string   `json:"timezone,omitempty"`
+}
+
+// ListDormitories - GET /dormitories. Админам всего кампуса - вместе с администраторами.
+func (h *Handlers) ListDormitories(w http.ResponseWriter, r *http.Request) {
+	list, err := h.dorms.List(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if h.isCampusAdmin(r) {
+		writeJSON(w, list)
+		return
+	}
+	out := make([]publicDormitory, 0, len(list))
+	for _, d := range list {
+		out = append(out, publicDormitory{ID: d.ID, Name: d.Name, Hosts: d.Hosts, Timezone: d.Timezone})
+	}
+	writeJSON(w, out)
+}
+
+// CreateDormitory - POST /admin/dormitories, только для админов всего кампуса.
+func (h *Handlers) CreateDormitory(w http.ResponseWriter, r *http.Request) {
+	if !h.isCampusAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	var d domaindorm.Dormitory
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	d, err := h.dorms.Create(r.Context(), d)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, d)
+}
+
+// UpdateDormitory - PUT /admin/dormitories/{id}, только для админов всего кампуса.
+func (h *Handlers) UpdateDormitory(w http.ResponseWriter, r *http.Request) {
+	if !h.isCampusAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	var d domaindorm.Dormitory
+	if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+	d.ID = chi.URLParam(r, "id")
+
+	d, err := h.dorms.Update(r.Context(), d)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, d)
+}
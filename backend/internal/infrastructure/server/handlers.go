@@ -1,191 +1,412 @@
 package server
 
-// В этом файле HTTP-обработчики для бронирований и простая админ-авторизация.
+// В этом файле HTTP-обработчики для бронирований и проверка прав администратора.
+// Админ бывает всего кампуса (ADMINS, X-Admin-Token) и отдельного общежития.
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/netip"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 
+	appauth "Dormitory_Booking/internal/application/auth"
 	appbooking "Dormitory_Booking/internal/application/booking"
+	appdorm "Dormitory_Booking/internal/application/dormitory"
+	appnotify "Dormitory_Booking/internal/application/notify"
+	"Dormitory_Booking/internal/domain/apperror"
 	domain "Dormitory_Booking/internal/domain/booking"
+	domainuser "Dormitory_Booking/internal/domain/user"
 )
 
 type Handlers struct {
-	svc           *appbooking.Service
-	adminPassword string
+	svc            *appbooking.Service
+	auth           *appauth.Service
+	notify         *appnotify.Service // nil, если уведомления не подключены
+	dorms          *appdorm.Service   // nil - одно общежитие, всё идёт в svc
+	events         domain.EventBus    // nil - без живого обновления (/bookings/stream)
+	adminToken     string             // токен для скриптов, заголовок X-Admin-Token
+	loginRedirect  string             // куда вести после входа по ссылке из письма
+	loginThrottle  *appauth.Throttle  // попытки входа с одного IP
+	trustedProxies []netip.Prefix     // прокси, чьим X-Forwarded-For верим
+	loc            *time.Location     // пояс для календарей
+	publicURL      string             // внешний адрес API для ссылок на подписки
+	secureCookies  bool               // Secure у cookie сессии; без HTTPS (локально) SESSION_COOKIE_SECURE=false
 }
 
-func normalizeTG(s string) string {
-	s = strings.TrimSpace(s)
-	s = strings.TrimPrefix(s, "@")
-	s = strings.ToLower(s)
-	return s
-}
-
-func NewHandlers(svc *appbooking.Service) *Handlers {
+func NewHandlers(svc *appbooking.Service, auth *appauth.Service) *Handlers {
+	loginRedirect := os.Getenv("AUTH_REDIRECT_URL")
+	if loginRedirect == "" {
+		loginRedirect = "/"
+	}
+	// без флага Secure браузер отправит cookie сессии и по голому HTTP
+	secureCookies := true
+	if v, err := strconv.ParseBool(os.Getenv("SESSION_COOKIE_SECURE")); err == nil {
+		secureCookies = v
+	}
 	return &Handlers{
 		svc:           svc,
-		adminPassword: os.Getenv("ADMIN_PASSWORD"),
+		auth:          auth,
+		adminToken:    os.Getenv("ADMIN_TOKEN"),
+		loginRedirect: loginRedirect,
+		loginThrottle: appauth.NewThrottle(60, 15*time.Minute),
+		loc:           time.UTC,
+		publicURL:     strings.TrimRight(os.Getenv("PUBLIC_URL"), "/"),
+		secureCookies: secureCookies,
 	}
 }
 
-// Логин в админку
+// Вход в админку: тот же код на почту, но только для адресов из ADMINS
+// и администраторов общежития, в которое идёт запрос.
 
 func (h *Handlers) AdminLogin(w http.ResponseWriter, r *http.Request) {
 	var body struct {
-		Password string `json:"password"`
+		Email string `json:"email"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+		writeBadRequest(w, r, "invalid json")
 		return
 	}
-
-	if h.adminPassword == "" || body.Password != h.adminPassword {
-		http.Error(w, "invalid credentials", http.StatusUnauthorized)
+	if !h.allowLoginAttempt(w, r) {
 		return
 	}
 
-	http.SetCookie(w, &http.Cookie{
-		Name:     "admin_token",
-		Value:    "1",
-		Path:     "/",
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
-	w.WriteHeader(http.StatusOK)
+	login := h.auth.RequestAdminLogin
+	if dormitoryAdmin(r, body.Email) {
+		login = h.auth.RequestLogin
+	}
+	if err := login(r.Context(), body.Email); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
 }
 
-func (h *Handlers) AdminLogout(w http.ResponseWriter, r *http.Request) {
-	http.SetCookie(w, &http.Cookie{
-		Name:     "admin_token",
-		Value:    "",
-		Path:     "/",
-		MaxAge:   -1,
-		HttpOnly: true,
-		SameSite: http.SameSiteLaxMode,
-	})
-	w.WriteHeader(http.StatusOK)
+// RevokeSessions разлогинивает пользователя на всех устройствах.
+func (h *Handlers) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	if err := h.auth.RevokeSessions(r.Context(), chi.URLParam(r, "id")); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
 }
 
+// isAdmin - админ общежития, к которому относится запрос (админ кампуса - админ любого).
 func (h *Handlers) isAdmin(r *http.Request) bool {
-	// 1) header token
-	if tok := r.Header.Get("X-Admin-Token"); tok != "" && tok == os.Getenv("ADMIN_TOKEN") {
+	if h.isCampusAdmin(r) {
 		return true
 	}
-	// 2) cookie
-	c, err := r.Cookie("admin_token")
-	return err == nil && c.Value == "1"
+	// 3) сессия администратора этого общежития
+	u, err := h.currentUser(r)
+	return err == nil && dormitoryAdmin(r, u.Email)
+}
+
+// isCampusAdmin - админ всех общежитий сразу.
+func (h *Handlers) isCampusAdmin(r *http.Request) bool {
+	// 1) токен из окружения - для скриптов и CI
+	if tok := r.Header.Get("X-Admin-Token"); tok != "" && h.adminToken != "" &&
+		subtle.ConstantTimeCompare([]byte(tok), []byte(h.adminToken)) == 1 {
+		return true
+	}
+	// 2) сессия пользователя из списка ADMINS
+	u, err := h.currentUser(r)
+	return err == nil && h.auth.IsAdmin(u)
 }
 
 // Бронирования
 
 func (h *Handlers) GetAll(w http.ResponseWriter, r *http.Request) {
-	list, err := h.svc.ListBookings(r.Context())
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	viewer, isAdmin := h.viewer(r), h.isAdmin(r)
+	filter, ok := restrictOwner(filter, viewer, isAdmin)
+	if !ok {
+		writeJSON(w, []appbooking.BookingDTO{})
+		return
+	}
+
+	list, err := h.bookings(r).FindBookings(r.Context(), filter)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
 	out := make([]appbooking.BookingDTO, 0, len(list))
 	for _, b := range list {
-		out = append(out, appbooking.ToDTO(b, "", h.isAdmin(r)))
+		out = append(out, appbooking.ToDTO(b, viewer, isAdmin))
 	}
 	writeJSON(w, out)
 }
 
+// restrictOwner - по чужому owner частные брони не ищем: иначе фильтр выдаст, чьи они,
+// хоть в ответе и «Занято». ok=false - под фильтр заведомо ничего не попадёт.
+func restrictOwner(f domain.ListFilter, viewer string, isAdmin bool) (domain.ListFilter, bool) {
+	if f.Owner == "" || f.Owner == viewer || isAdmin {
+		return f, true
+	}
+	if f.IsPrivate != nil && *f.IsPrivate {
+		return f, false
+	}
+	public := false
+	f.IsPrivate = &public
+	return f, true
+}
+
+// parseListFilter разбирает query-параметры from, to, room, owner, isPrivate, cancelled.
+func parseListFilter(r *http.Request) (domain.ListFilter, error) {
+	q := r.URL.Query()
+	var f domain.ListFilter
+
+	if v := q.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("invalid from")
+		}
+		f.From = from
+	}
+	if v := q.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("invalid to")
+		}
+		f.To = to
+	}
+	if v := q.Get("room"); v != "" {
+		room, err := strconv.Atoi(v)
+		if err != nil {
+			return f, errors.New("invalid room")
+		}
+		f.Room = domain.Room(room)
+	}
+	// как при привязке Telegram: "@Student" и "student" - один владелец
+	f.Owner = domainuser.NormalizeTelegram(q.Get("owner"))
+	if v := q.Get("isPrivate"); v != "" {
+		isPrivate, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("invalid isPrivate")
+		}
+		f.IsPrivate = &isPrivate
+	}
+	if v := q.Get("cancelled"); v != "" {
+		cancelled, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("invalid cancelled")
+		}
+		f.IncludeCancelled = cancelled
+	}
+
+	return f, nil
+}
+
 func (h *Handlers) GetOne(w http.ResponseWriter, r *http.Request) {
-	id := chi.URLParam(r, "id")
+	id, ics := wantsICS(r, chi.URLParam(r, "id"))
 
-	b, err := h.svc.GetBooking(r.Context(), id)
+	b, err := h.bookings(r).GetBooking(r.Context(), id)
 	if err != nil {
-		if err == domain.ErrNotFound {
-			http.Error(w, err.Error(), http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, appbooking.ToDTO(b, "", h.isAdmin(r)))
+	if ics {
+		h.writeBookingICS(w, r, b)
+		return
+	}
+	writeJSON(w, appbooking.ToDTO(b, h.viewer(r), h.isAdmin(r)))
 }
 
 func (h *Handlers) Create(w http.ResponseWriter, r *http.Request) {
+	input, err := decodeCreateInput(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	input.TelegramID = requesterID
+
+	b, err := h.bookings(r).CreateBooking(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, appbooking.ToDTO(b, requesterID, h.isAdmin(r)))
+}
+
+// Validate - POST /bookings/validate: то же тело, что у Create, но ничего не сохраняет
+// и возвращает все нарушенные правила сразу.
+func (h *Handlers) Validate(w http.ResponseWriter, r *http.Request) {
+	input, err := decodeCreateInput(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	// владелец на правила не влияет, поэтому вход не обязателен
+	input.TelegramID, _ = h.requester(r)
+
+	violations, err := h.bookings(r).CheckBooking(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if violations == nil {
+		violations = []appbooking.Violation{}
+	}
+	if lang := language(r); lang != apperror.LangRU {
+		for i := range violations {
+			violations[i].Message = apperror.Localize(violations[i].Code, lang, violations[i].Message)
+		}
+	}
+	writeJSON(w, map[string]any{
+		"valid":      len(violations) == 0,
+		"violations": violations,
+	})
+}
+
+// decodeCreateInput разбирает тело запроса на создание брони. Владельца проставляет вызывающий.
+func decodeCreateInput(r *http.Request) (appbooking.CreateBookingInput, error) {
 	var body struct {
 		Start       string `json:"start"`
 		End         string `json:"end"`
 		Room        int    `json:"room"`
 		Title       string `json:"title"`
 		Description string `json:"description"`
-		TelegramID  string `json:"telegramId"`
 		IsPrivate   bool   `json:"isPrivate"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
-		return
+		return appbooking.CreateBookingInput{}, errors.New("invalid json")
 	}
 
 	start, err := time.Parse(time.RFC3339, body.Start)
 	if err != nil {
-		http.Error(w, "invalid start time", http.StatusBadRequest)
-		return
+		return appbooking.CreateBookingInput{}, errors.New("invalid start time")
 	}
 	end, err := time.Parse(time.RFC3339, body.End)
 	if err != nil {
-		http.Error(w, "invalid end time", http.StatusBadRequest)
-		return
+		return appbooking.CreateBookingInput{}, errors.New("invalid end time")
 	}
 
-	input := appbooking.CreateBookingInput{
+	return appbooking.CreateBookingInput{
 		Start:       start,
 		End:         end,
 		Room:        domain.Room(body.Room),
 		Title:       body.Title,
 		Description: body.Description,
-		TelegramID:  body.TelegramID,
 		IsPrivate:   body.IsPrivate,
+	}, nil
+}
+
+func (h *Handlers) Update(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	isAdmin := h.isAdmin(r)
+	requesterID, err := h.requester(r)
+	if err != nil && !isAdmin {
+		writeError(w, r, err)
+		return
+	}
+
+	var body struct {
+		Start       *string `json:"start"`
+		End         *string `json:"end"`
+		Room        *int    `json:"room"`
+		Title       *string `json:"title"`
+		Description *string `json:"description"`
+		IsPrivate   *bool   `json:"isPrivate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	var input appbooking.UpdateBookingInput
+	if body.Start != nil {
+		start, err := time.Parse(time.RFC3339, *body.Start)
+		if err != nil {
+			writeBadRequest(w, r, "invalid start time")
+			return
+		}
+		input.Start = &start
 	}
+	if body.End != nil {
+		end, err := time.Parse(time.RFC3339, *body.End)
+		if err != nil {
+			writeBadRequest(w, r, "invalid end time")
+			return
+		}
+		input.End = &end
+	}
+	if body.Room != nil {
+		room := domain.Room(*body.Room)
+		input.Room = &room
+	}
+	input.Title = body.Title
+	input.Description = body.Description
+	input.IsPrivate = body.IsPrivate
 
-	b, err := h.svc.CreateBooking(r.Context(), input)
+	b, err := h.bookings(r).UpdateBooking(r.Context(), id, requesterID, isAdmin, input)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, appbooking.ToDTO(b, body.TelegramID, h.isAdmin(r)))
+	writeJSON(w, appbooking.ToDTO(b, requesterID, isAdmin))
 }
 
+// Delete отменяет бронь; ?reason= попадёт в историю отмен.
 func (h *Handlers) Delete(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 	isAdmin := h.isAdmin(r)
-
-	requesterID := r.URL.Query().Get("tg")
-	if requesterID == "" {
-		requesterID = r.Header.Get("X-User-TelegramID")
+	requesterID, err := h.requester(r)
+	if err != nil && !isAdmin {
+		writeError(w, r, err)
+		return
 	}
 
-	err := h.svc.DeleteBooking(r.Context(), id, requesterID, isAdmin)
+	reason := strings.TrimSpace(r.URL.Query().Get("reason"))
+	err = h.bookings(r).CancelBooking(r.Context(), id, requesterID, isAdmin, reason)
 	if err != nil {
-		if errors.Is(err, domain.ErrForbidden) {
-			http.Error(w, "forbidden", http.StatusForbidden)
-			return
-		}
-		if errors.Is(err, domain.ErrNotFound) {
-			http.Error(w, "not found", http.StatusNotFound)
-			return
-		}
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Restore возвращает отменённую бронь, если её время ещё свободно.
+func (h *Handlers) Restore(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	adminID, _ := h.requester(r) // пусто у входа по X-Admin-Token
+
+	b, err := h.bookings(r).RestoreBooking(r.Context(), chi.URLParam(r, "id"), adminID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, appbooking.ToDTO(b, adminID, true))
+}
+
 func writeJSON(w http.ResponseWriter, v any) {
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(v)
@@ -1,22 +1,150 @@
 package server_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	appauth "Dormitory_Booking/internal/application/auth"
 	appbooking "Dormitory_Booking/internal/application/booking"
+	appdorm "Dormitory_Booking/internal/application/dormitory"
+	"Dormitory_Booking/internal/domain/audit"
+	domain "Dormitory_Booking/internal/domain/booking"
+	domaindorm "Dormitory_Booking/internal/domain/dormitory"
 	"Dormitory_Booking/internal/infrastructure/memory"
 	"Dormitory_Booking/internal/infrastructure/server"
 )
 
-func setupTestServer() http.Handler {
+// fakeMailer запоминает последнее письмо для каждого адреса.
+type fakeMailer struct {
+	mu   sync.Mutex
+	last map[string]appauth.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg appauth.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.last[msg.To] = msg
+	return nil
+}
+
+var codeRe = regexp.MustCompile(`\b\d{6}\b`)
+
+func (m *fakeMailer) code(email string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return codeRe.FindString(m.last[email].Body)
+}
+
+type testServer struct {
+	http.Handler
+	mailer *fakeMailer
+	auth   *appauth.Service
+}
+
+func setupTestServer() *testServer {
+	return setupServer(nil)
+}
+
+// setupDormServer - сервер с двумя общежитиями: main и east (east.example.com, админ warden).
+func setupDormServer() *testServer {
+	bookings := memory.NewInMemoryBookingRepo()
+	dorms := appdorm.NewService(memory.NewInMemoryDormitoryRepo(
+		domaindorm.Dormitory{ID: domaindorm.DefaultID, Name: "Первое"},
+		domaindorm.Dormitory{ID: "east", Name: "Восточное", Hosts: []string{"east.example.com"}, Admins: []string{"warden@edu.hse.ru"}},
+	), func(d domaindorm.Dormitory) *appbooking.Service {
+		return appbooking.NewService(bookings.ForDormitory(d.ID),
+			appbooking.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)),
+			appbooking.WithAuditLog(memory.NewInMemoryAuditLog()),
+		)
+	})
+	return setupServer(dorms)
+}
+
+func setupServer(dorms *appdorm.Service, extra ...server.Option) *testServer {
 	repo := memory.NewInMemoryBookingRepo()
-	svc := appbooking.NewService(repo)
-	return server.NewRouter(svc)
+	events := memory.NewEventBroker()
+	svc := appbooking.NewService(repo,
+		appbooking.WithEvents(events),
+		appbooking.WithChangeLog(repo),
+		appbooking.WithSeriesRepo(memory.NewInMemorySeriesRepo()),
+		appbooking.WithRoomRepo(memory.NewInMemoryRoomRepo(domain.DefaultRooms()...)),
+		appbooking.WithAuditLog(memory.NewInMemoryAuditLog()),
+		appbooking.WithWaitlist(memory.NewInMemoryWaitlistRepo(), time.Hour),
+	)
+	mailer := &fakeMailer{last: make(map[string]appauth.Message)}
+	auth := appauth.NewService(memory.NewInMemoryUserRepo(), memory.NewInMemoryLoginCodeRepo(), memory.NewInMemorySessionRepo(), memory.NewInMemoryTelegramLinkRepo(), mailer, appauth.Config{
+		EmailDomain:   "edu.hse.ru",
+		SessionSecret: []byte("test-secret"),
+		VerifyURL:     "http://localhost/auth/verify",
+		Admins:        []string{"admin@edu.hse.ru"},
+	})
+	opts := []server.Option{server.WithEvents(events)}
+	if dorms != nil {
+		opts = append(opts, server.WithDormitories(dorms))
+	}
+	opts = append(opts, extra...)
+	return &testServer{Handler: server.NewRouter(svc, auth, opts...), mailer: mailer, auth: auth}
+}
+
+// login проходит вход по коду из письма и привязывает Telegram, подтверждая код так же,
+// как это делает бот. Возвращает cookie сессии.
+func (ts *testServer) login(t *testing.T, email, tg string) *http.Cookie {
+	t.Helper()
+
+	raw, _ := json.Marshal(map[string]string{"email": email})
+	w := httptest.NewRecorder()
+	ts.ServeHTTP(w, httptest.NewRequest("POST", "/auth/login", bytes.NewReader(raw)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("вход: ожидали 202, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+
+	raw, _ = json.Marshal(map[string]string{"email": email, "code": ts.mailer.code(email)})
+	w = httptest.NewRecorder()
+	ts.ServeHTTP(w, httptest.NewRequest("POST", "/auth/verify", bytes.NewReader(raw)))
+	if w.Code != 200 {
+		t.Fatalf("проверка кода: ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+	var session *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "session" {
+			session = c
+		}
+	}
+	if session == nil {
+		t.Fatalf("не получили cookie сессии")
+	}
+
+	raw, _ = json.Marshal(map[string]string{"telegramId": tg})
+	w = httptest.NewRecorder()
+	ts.ServeHTTP(w, withCookie(httptest.NewRequest("PUT", "/me/telegram", bytes.NewReader(raw)), session))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("привязка Telegram: ожидали 202, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+	var link appauth.TelegramLinkRequest
+	if err := json.NewDecoder(w.Body).Decode(&link); err != nil || link.Code == "" {
+		t.Fatalf("привязка Telegram: нет кода в ответе: %s", w.Body.String())
+	}
+	if _, err := ts.auth.ConfirmTelegram(context.Background(), link.Code, tg, 0); err != nil {
+		t.Fatalf("подтверждение Telegram: %v", err)
+	}
+	return session
+}
+
+func withCookie(r *http.Request, c *http.Cookie) *http.Request {
+	if c != nil {
+		r.AddCookie(c)
+	}
+	return r
 }
 
 func futureTimes() (string, string) {
@@ -37,7 +165,6 @@ func TestCreateBooking(t *testing.T) {
 		"room":        21,
 		"title":       "Test",
 		"description": "desc",
-		"telegramId":  "11",
 		"isPrivate":   false,
 	}
 
@@ -45,12 +172,27 @@ func TestCreateBooking(t *testing.T) {
 
 	req := httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw))
 	w := httptest.NewRecorder()
-
 	h.ServeHTTP(w, req)
+	if w.Code != 401 {
+		t.Fatalf("без входа: ожидали 401, получили %d", w.Code)
+	}
+
+	session := h.login(t, "student@edu.hse.ru", "@Student")
 
+	req = withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
 	if w.Code != 200 {
 		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
 	}
+
+	var created appbooking.BookingDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("не смогли разобрать ответ: %v", err)
+	}
+	if created.TelegramID != "student" {
+		t.Fatalf("владелец должен браться из сессии, получили %q", created.TelegramID)
+	}
 }
 
 func TestListBookings(t *testing.T) {
@@ -65,3 +207,1022 @@ func TestListBookings(t *testing.T) {
 		t.Fatalf("ожидали 200, получили %d", w.Code)
 	}
 }
+
+func TestUpdateBooking(t *testing.T) {
+	h := setupTestServer()
+
+	start := time.Date(2099, 1, 5, 10, 0, 0, 0, time.UTC)
+	raw, _ := json.Marshal(map[string]any{
+		"start": start.Format(time.RFC3339),
+		"end":   start.Add(time.Hour).Format(time.RFC3339),
+		"room":  21,
+		"title": "Test",
+	})
+	owner := h.login(t, "owner@edu.hse.ru", "11")
+	other := h.login(t, "other@edu.hse.ru", "22")
+
+	req := withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), owner)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	var created appbooking.BookingDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatalf("не смогли разобрать ответ: %v", err)
+	}
+
+	patch, _ := json.Marshal(map[string]any{
+		"start": start.Add(30 * time.Minute).Format(time.RFC3339),
+		"end":   start.Add(90 * time.Minute).Format(time.RFC3339),
+	})
+
+	req = withCookie(httptest.NewRequest("PATCH", "/bookings/"+created.ID, bytes.NewReader(patch)), other)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != 403 {
+		t.Fatalf("чужой пользователь: ожидали 403, получили %d", w.Code)
+	}
+
+	req = withCookie(httptest.NewRequest("PATCH", "/bookings/"+created.ID, bytes.NewReader(patch)), owner)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListBookings_Filtered(t *testing.T) {
+	h := setupTestServer()
+
+	session := h.login(t, "student@edu.hse.ru", "11")
+
+	start := time.Date(2099, 1, 5, 10, 0, 0, 0, time.UTC)
+	for i, room := range []int{21, 132} {
+		raw, _ := json.Marshal(map[string]any{
+			"start": start.AddDate(0, 0, i*7).Format(time.RFC3339),
+			"end":   start.AddDate(0, 0, i*7).Add(time.Hour).Format(time.RFC3339),
+			"room":  room,
+			"title": "Test",
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session))
+		if w.Code != 200 {
+			t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+		}
+	}
+
+	q := "/bookings?from=2099-01-05T00:00:00Z&to=2099-01-12T00:00:00Z"
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", q, nil))
+
+	var list []appbooking.BookingDTO
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("не смогли разобрать ответ: %v", err)
+	}
+	if len(list) != 1 || list[0].Room != 21 {
+		t.Fatalf("ожидали одну бронь в 21, получили %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/bookings?room=abc", nil))
+	if w.Code != 400 {
+		t.Fatalf("ожидали 400, получили %d", w.Code)
+	}
+}
+
+func TestCreateSeries(t *testing.T) {
+	h := setupTestServer()
+
+	session := h.login(t, "club@edu.hse.ru", "club")
+
+	start := time.Date(2099, 1, 6, 19, 0, 0, 0, time.UTC)
+	raw, _ := json.Marshal(map[string]any{
+		"start": start.Format(time.RFC3339),
+		"end":   start.Add(2 * time.Hour).Format(time.RFC3339),
+		"room":  256,
+		"title": "Настолки",
+		"recurrence": map[string]any{
+			"freq":  "weekly",
+			"byDay": []string{"TU"},
+			"count": 3,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/series", bytes.NewReader(raw)), session))
+	if w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+
+	var out struct {
+		Series struct {
+			ID string `json:"id"`
+		} `json:"series"`
+		Occurrences []appbooking.BookingDTO `json:"occurrences"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatalf("не смогли разобрать ответ: %v", err)
+	}
+	if len(out.Occurrences) != 3 {
+		t.Fatalf("ожидали 3 занятия, получили %d", len(out.Occurrences))
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/series/"+out.Series.ID, nil), session))
+	if w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestAdminRooms(t *testing.T) {
+	t.Setenv("ADMIN_TOKEN", "secret")
+	h := setupTestServer()
+
+	raw, _ := json.Marshal(map[string]any{
+		"number":   310,
+		"name":     "Лаунж",
+		"capacity": 12,
+		"active":   true,
+		"schedule": map[string]int{
+			"weekdayOpen": 10, "weekdayClose": 20,
+			"friSatOpen": 10, "friSatClose": 20,
+			"sunOpen": 10, "sunClose": 20,
+		},
+	})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/admin/rooms", bytes.NewReader(raw)))
+	if w.Code != 403 {
+		t.Fatalf("без админки: ожидали 403, получили %d", w.Code)
+	}
+
+	req := httptest.NewRequest("POST", "/admin/rooms", bytes.NewReader(raw))
+	req.Header.Set("X-Admin-Token", "secret")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != 201 {
+		t.Fatalf("ожидали 201, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/rooms", nil))
+	var rooms []domain.RoomInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &rooms); err != nil {
+		t.Fatalf("не смогли разобрать ответ: %v", err)
+	}
+	if len(rooms) != 4 {
+		t.Fatalf("ожидали 4 комнаты, получили %d", len(rooms))
+	}
+}
+
+func TestAdminSession(t *testing.T) {
+	h := setupTestServer()
+
+	forged := &http.Cookie{Name: "admin_token", Value: "1"}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/policy", nil), forged))
+	if w.Code != 403 {
+		t.Fatalf("старый cookie admin_token=1: ожидали 403, получили %d", w.Code)
+	}
+
+	student := h.login(t, "student@edu.hse.ru", "student")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/policy", nil), student))
+	if w.Code != 403 {
+		t.Fatalf("студент: ожидали 403, получили %d", w.Code)
+	}
+
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/policy", nil), admin))
+	if w.Code != 200 {
+		t.Fatalf("администратор: ожидали 200, получили %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/auth/logout", nil), admin))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/policy", nil), admin))
+	if w.Code != 403 {
+		t.Fatalf("после выхода старый cookie не должен работать, получили %d", w.Code)
+	}
+}
+
+func TestSessionCookieSecure(t *testing.T) {
+	session := setupTestServer().login(t, "student@edu.hse.ru", "student")
+	if !session.Secure {
+		t.Fatalf("cookie сессии по умолчанию должна быть Secure")
+	}
+
+	t.Setenv("SESSION_COOKIE_SECURE", "false")
+	h := setupTestServer()
+	session = h.login(t, "student@edu.hse.ru", "student")
+	if session.Secure {
+		t.Fatalf("SESSION_COOKIE_SECURE=false: cookie не должна быть Secure")
+	}
+
+	t.Setenv("SESSION_COOKIE_SECURE", "")
+	h = setupTestServer()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/auth/logout", nil), session))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].Secure || cookies[0].MaxAge >= 0 {
+		t.Fatalf("выход должен стирать cookie с теми же атрибутами, получили %v", cookies)
+	}
+}
+
+func TestCalendarFeeds(t *testing.T) {
+	h := setupTestServer()
+	session := h.login(t, "student@edu.hse.ru", "student")
+
+	start := time.Date(time.Now().Year()+1, 1, 5, 10, 0, 0, 0, time.UTC)
+	var ids []string
+	for i, private := range []bool{false, true} {
+		raw, _ := json.Marshal(map[string]any{
+			"start":     start.Add(time.Duration(i*2) * time.Hour).Format(time.RFC3339),
+			"end":       start.Add(time.Duration(i*2+1) * time.Hour).Format(time.RFC3339),
+			"room":      21,
+			"title":     []string{"Кино", "День рождения"}[i],
+			"isPrivate": private,
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session))
+		if w.Code != 200 {
+			t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+		}
+		var created appbooking.BookingDTO
+		_ = json.Unmarshal(w.Body.Bytes(), &created)
+		ids = append(ids, created.ID)
+	}
+
+	// лента комнаты публичная, частная бронь в ней только «Занято»
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/calendar/rooms/21.ics", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "SUMMARY:Кино") || strings.Contains(w.Body.String(), "День рождения") {
+		t.Fatalf("лента комнаты: %d\n%s", w.Code, w.Body.String())
+	}
+
+	// .ics одной брони: аноним видит «Занято», владелец - название
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/bookings/"+ids[1]+".ics", nil))
+	if !strings.Contains(w.Body.String(), "SUMMARY:Занято") {
+		t.Fatalf("чужая частная бронь раскрыта:\n%s", w.Body.String())
+	}
+	req := withCookie(httptest.NewRequest("GET", "/bookings/"+ids[1], nil), session)
+	req.Header.Set("Accept", "text/calendar")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "SUMMARY:День рождения") {
+		t.Fatalf("владелец должен видеть свою бронь:\n%s", w.Body.String())
+	}
+
+	// личная подписка по токену
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/me/calendar", nil), session))
+	var link struct {
+		URL string `json:"url"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &link)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", link.URL, nil))
+	if w.Code != 200 || strings.Count(w.Body.String(), "BEGIN:VEVENT") != 2 {
+		t.Fatalf("личная лента: %d\n%s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/calendar/users/guess.ics", nil))
+	if w.Code != 404 {
+		t.Fatalf("чужой токен: ожидали 404, получили %d", w.Code)
+	}
+}
+
+func TestAvailability(t *testing.T) {
+	h := setupTestServer()
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/availability?room=21&date=2099-01-02&minDuration=60&private=true", nil))
+	if w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+	var slots []appbooking.Slot
+	if err := json.Unmarshal(w.Body.Bytes(), &slots); err != nil || len(slots) != 1 {
+		t.Fatalf("ожидали один промежуток, получили %s", w.Body.String())
+	}
+	if want := time.Date(2099, 1, 2, 23, 0, 0, 0, time.UTC); !slots[0].End.Equal(want) {
+		t.Fatalf("ЧП должна заканчиваться до тихой ночи, получили %v", slots[0].End)
+	}
+
+	for _, q := range []string{"room=21", "room=x&date=2099-01-02", "room=21&date=2099-01-02&minDuration=-5"} {
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/availability?"+q, nil))
+		if w.Code != 400 {
+			t.Fatalf("%s: ожидали 400, получили %d", q, w.Code)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/availability?room=999&date=2099-01-02", nil))
+	if w.Code != 422 {
+		t.Fatalf("неизвестная комната: ожидали 422, получили %d", w.Code)
+	}
+}
+
+func TestValidateBooking(t *testing.T) {
+	h := setupTestServer()
+
+	raw, _ := json.Marshal(map[string]any{
+		"start":     "2099-01-02T20:00:00Z",
+		"end":       "2099-01-03T02:00:00Z",
+		"room":      21,
+		"title":     "ЧП",
+		"isPrivate": true,
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/bookings/validate", bytes.NewReader(raw)))
+	if w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+
+	var resp struct {
+		Valid      bool                   `json:"valid"`
+		Violations []appbooking.Violation `json:"violations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("не удалось разобрать ответ: %v", err)
+	}
+	if resp.Valid || len(resp.Violations) != 3 || resp.Violations[0].Rule != appbooking.RulePrivateMaxDuration || resp.Violations[0].Suggestion == nil {
+		t.Fatalf("неожиданный ответ: %s", w.Body.String())
+	}
+
+	// ничего не создано
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/bookings", nil))
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Fatalf("validate не должен создавать бронь: %s", w.Body.String())
+	}
+}
+
+func TestProblemResponses(t *testing.T) {
+	h := setupTestServer()
+	session := h.login(t, "student@edu.hse.ru", "student")
+
+	create := func(body map[string]any, lang string) *httptest.ResponseRecorder {
+		raw, _ := json.Marshal(body)
+		req := withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session)
+		if lang != "" {
+			req.Header.Set("Accept-Language", lang)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	decode := func(w *httptest.ResponseRecorder) map[string]any {
+		t.Helper()
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Fatalf("ожидали application/problem+json, получили %q", ct)
+		}
+		var p map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("не смогли разобрать ответ: %v", err)
+		}
+		return p
+	}
+
+	booking := map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T12:00:00Z", "room": 132, "title": "Кино"}
+	if w := create(booking, ""); w.Code != 200 {
+		t.Fatalf("ожидали 200, получили %d, тело: %s", w.Code, w.Body.String())
+	}
+
+	// пересечение - конфликт
+	w := create(booking, "")
+	p := decode(w)
+	if w.Code != 409 || p["code"] != "OVERLAP" || p["status"] != float64(409) || p["error"] != "Бронь пересекается с существующей." {
+		t.Fatalf("пересечение: %d %v", w.Code, p)
+	}
+
+	// нарушение правила - 422 с правилом и параметрами, по-английски
+	w = create(map[string]any{"start": "2099-01-05T13:00:00Z", "end": "2099-01-05T17:00:00Z", "room": 132, "title": "ЧП", "isPrivate": true}, "en-US,en;q=0.9,ru;q=0.8")
+	p = decode(w)
+	if w.Code != 422 || p["code"] != "TOO_LONG_DURATION" || p["rule"] != "private.max_duration" || p["title"] != "The booking is longer than allowed." {
+		t.Fatalf("нарушение правила: %d %v", w.Code, p)
+	}
+	if w.Header().Get("Content-Language") != "en" {
+		t.Fatalf("ожидали Content-Language: en, получили %q", w.Header().Get("Content-Language"))
+	}
+
+	// битый запрос - 400
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", strings.NewReader("{")), session))
+	if p = decode(w); w.Code != 400 || p["code"] != "BAD_REQUEST" || p["detail"] != "invalid json" {
+		t.Fatalf("битый JSON: %d %v", w.Code, p)
+	}
+}
+
+func TestAdminAuditLog(t *testing.T) {
+	h := setupTestServer()
+	student := h.login(t, "student@edu.hse.ru", "student")
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 132, "title": "Кино"})
+	req := withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), student)
+	req.Header.Set("User-Agent", "audit-test")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+created.ID, nil), admin))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("удаление админом: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/audit", nil), student))
+	if w.Code != 403 {
+		t.Fatalf("журнал не для студентов: ожидали 403, получили %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/audit?booking="+created.ID, nil), admin))
+	var entries []audit.Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil || len(entries) != 2 {
+		t.Fatalf("ожидали 2 записи, получили %s", w.Body.String())
+	}
+	if entries[0].Action != audit.ActionAdminOverride || entries[0].Actor != "admin" || entries[1].UserAgent != "audit-test" || entries[1].IP == "" {
+		t.Fatalf("неожиданный журнал: %+v", entries)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/audit?action=create&actor=student", nil), admin))
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil || len(entries) != 1 {
+		t.Fatalf("фильтр: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/audit?action=drop", nil), admin))
+	if w.Code != 400 {
+		t.Fatalf("неизвестное действие: ожидали 400, получили %d", w.Code)
+	}
+}
+
+func TestCancelAndRestoreBooking(t *testing.T) {
+	h := setupTestServer()
+	student := h.login(t, "student@edu.hse.ru", "student")
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 132, "title": "Кино"})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), student))
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if created.Status != domain.StatusActive {
+		t.Fatalf("новая бронь должна быть active: %+v", created)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+created.ID+"?reason=%D0%BF%D0%B5%D1%80%D0%B5%D0%B4%D1%83%D0%BC%D0%B0%D0%BB", nil), student))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("отмена: %d %s", w.Code, w.Body.String())
+	}
+
+	var list []appbooking.BookingDTO
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/bookings", nil))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 0 {
+		t.Fatalf("отменённая бронь попала в список: %+v", list)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/bookings?cancelled=true", nil))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Status != domain.StatusCancelled || list[0].CancelReason != "передумал" {
+		t.Fatalf("ожидали отменённую бронь с причиной, получили %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+created.ID, nil), student))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("повторная отмена: ожидали 409, получили %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/admin/bookings/"+created.ID+"/restore", nil), student))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("восстановление студентом: ожидали 403, получили %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/admin/bookings/"+created.ID+"/restore", nil), admin))
+	var restored appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &restored)
+	if w.Code != 200 || restored.Status != domain.StatusActive || restored.CancelledAt != nil {
+		t.Fatalf("восстановление: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestWaitlist(t *testing.T) {
+	h := setupTestServer()
+	owner := h.login(t, "owner@edu.hse.ru", "owner")
+	student := h.login(t, "student@edu.hse.ru", "student")
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 132, "title": "Кино"})
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/waitlist", bytes.NewReader(raw)), student))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("в очередь на свободное время: ожидали 409, получили %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), owner))
+	var blocking appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &blocking)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/waitlist", bytes.NewReader(raw)), student))
+	var entry domain.WaitlistEntry
+	_ = json.Unmarshal(w.Body.Bytes(), &entry)
+	if w.Code != 200 || entry.Status != domain.WaitlistWaiting {
+		t.Fatalf("постановка в очередь: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+blocking.ID, nil), owner))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("отмена: %d %s", w.Code, w.Body.String())
+	}
+
+	var mine []domain.WaitlistEntry
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/waitlist", nil), student))
+	_ = json.Unmarshal(w.Body.Bytes(), &mine)
+	if len(mine) != 1 || mine[0].Status != domain.WaitlistOffered || mine[0].HoldUntil == nil {
+		t.Fatalf("слот должен быть закреплён за студентом: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/waitlist/"+entry.ID+"/confirm", nil), owner))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("чужое подтверждение: ожидали 403, получили %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/waitlist/"+entry.ID+"/confirm", nil), student))
+	var confirmed appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &confirmed)
+	if w.Code != 200 || confirmed.TelegramID != "student" {
+		t.Fatalf("подтверждение: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestCheckIn(t *testing.T) {
+	h := setupTestServer()
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+	student := h.login(t, "student@edu.hse.ru", "student")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/rooms/132/checkin", nil), student))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("QR-код комнаты не для студента: ожидали 403, получили %d", w.Code)
+	}
+
+	var qr struct {
+		Token string `json:"token"`
+		URL   string `json:"url"`
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/rooms/132/checkin", nil), admin))
+	_ = json.Unmarshal(w.Body.Bytes(), &qr)
+	if w.Code != 200 || qr.Token == "" || !strings.HasSuffix(qr.URL, "/checkin/"+qr.Token) {
+		t.Fatalf("QR-код комнаты: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/checkin/"+qr.Token, nil), student))
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "NOTHING_TO_CHECK_IN") {
+		t.Fatalf("скан без брони: ожидали 404 NOTHING_TO_CHECK_IN, получили %d %s", w.Code, w.Body.String())
+	}
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 132, "title": "Кино"})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), student))
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings/"+created.ID+"/checkin", nil), student))
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "CHECK_IN_CLOSED") {
+		t.Fatalf("отметка задолго до начала: ожидали 409 CHECK_IN_CLOSED, получили %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/no-shows?user=student", nil), admin))
+	if w.Code != 200 || strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Fatalf("неявки: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestMyQuota(t *testing.T) {
+	h := setupTestServer()
+	student := h.login(t, "student@edu.hse.ru", "student")
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/me/quota", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("без входа: ожидали 401, получили %d", w.Code)
+	}
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:30:00Z", "room": 132, "title": "Кино"})
+	h.ServeHTTP(httptest.NewRecorder(), withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), student))
+
+	var usage appbooking.QuotaUsage
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/me/quota", nil), student))
+	_ = json.Unmarshal(w.Body.Bytes(), &usage)
+	if w.Code != 200 || usage.ActiveBookings != 1 || usage.RemainingBookings != nil {
+		t.Fatalf("квота без лимитов: %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestPrivateBookingVisibility(t *testing.T) {
+	h := setupTestServer()
+	owner := h.login(t, "owner@edu.hse.ru", "owner")
+	stranger := h.login(t, "stranger@edu.hse.ru", "stranger")
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 21, "title": "ДР Пети", "description": "секрет", "isPrivate": true})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), owner))
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+	if w.Code != 200 || created.Title != "ДР Пети" {
+		t.Fatalf("создание: %d %s", w.Code, w.Body.String())
+	}
+
+	leaks := func(body string) bool {
+		return strings.Contains(body, "Пети") || strings.Contains(body, "секрет") || strings.Contains(body, `"owner"`)
+	}
+	for name, req := range map[string]*http.Request{
+		"список анониму":    httptest.NewRequest("GET", "/bookings", nil),
+		"список чужому":     withCookie(httptest.NewRequest("GET", "/bookings", nil), stranger),
+		"бронь чужому":      withCookie(httptest.NewRequest("GET", "/bookings/"+created.ID, nil), stranger),
+		"ics чужому":        withCookie(httptest.NewRequest("GET", "/bookings/"+created.ID+".ics", nil), stranger),
+		"лента комнаты":     httptest.NewRequest("GET", "/calendar/rooms/21.ics", nil),
+		"поиск по owner":    httptest.NewRequest("GET", "/bookings?owner=owner", nil),
+		"поиск ЧП по owner": httptest.NewRequest("GET", "/bookings?owner=owner&isPrivate=true", nil),
+		"поиск по @Owner":   httptest.NewRequest("GET", "/bookings?owner=%40Owner", nil),
+	} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		if w.Code != 200 || leaks(w.Body.String()) {
+			t.Fatalf("%s: частная бронь раскрыта: %d %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	var list []appbooking.BookingDTO
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/bookings", nil), stranger))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Title != domain.BusyTitle || list[0].Visibility != domain.VisibilityAnonymousBusy {
+		t.Fatalf("чужая частная бронь должна быть «Занято»: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/bookings?owner=owner", nil), owner))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Title != "ДР Пети" || !list[0].CanManage {
+		t.Fatalf("владелец должен видеть свою бронь целиком: %s", w.Body.String())
+	}
+
+	// owner приводится к виду, в котором ник хранится
+	list = nil
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/bookings?owner=%40Owner", nil), owner))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].Title != "ДР Пети" {
+		t.Fatalf("поиск по @Owner должен найти бронь owner: %s", w.Body.String())
+	}
+}
+
+func TestDormitories(t *testing.T) {
+	h := setupDormServer()
+	student := h.login(t, "student@edu.hse.ru", "student")
+	warden := h.login(t, "warden@edu.hse.ru", "warden")
+
+	start := time.Date(2099, 1, 5, 10, 0, 0, 0, time.UTC)
+	raw, _ := json.Marshal(map[string]any{
+		"start": start.Format(time.RFC3339), "end": start.Add(time.Hour).Format(time.RFC3339),
+		"room": 21, "title": "Кино",
+	})
+	create := func(path string) (int, appbooking.BookingDTO) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", path, bytes.NewReader(raw)), student))
+		var b appbooking.BookingDTO
+		_ = json.Unmarshal(w.Body.Bytes(), &b)
+		return w.Code, b
+	}
+
+	// комната 21 есть в обоих общежитиях, и это разные комнаты
+	code, mainB := create("/bookings")
+	if code != 200 || mainB.Dormitory != domaindorm.DefaultID {
+		t.Fatalf("бронь без префикса: ожидали 200 в main, получили %d %+v", code, mainB)
+	}
+	code, eastB := create("/d/east/bookings")
+	if code != 200 || eastB.Dormitory != "east" {
+		t.Fatalf("та же комната в другом общежитии: ожидали 200, получили %d %+v", code, eastB)
+	}
+	if code, _ := create("/d/main/bookings"); code != http.StatusConflict {
+		t.Fatalf("пересечение в том же общежитии: ожидали 409, получили %d", code)
+	}
+
+	// чужое общежитие брони не видит ни списком, ни по ID
+	var list []appbooking.BookingDTO
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/d/east/bookings", nil))
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].ID != eastB.ID {
+		t.Fatalf("список east: %s", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/d/east/bookings/"+mainB.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("бронь main через east: ожидали 404, получили %d", w.Code)
+	}
+
+	// общежитие по домену
+	req := httptest.NewRequest("GET", "/bookings", nil)
+	req.Host = "east.example.com:443"
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	_ = json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list) != 1 || list[0].ID != eastB.ID {
+		t.Fatalf("список по домену east: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/d/nowhere/bookings", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("неизвестное общежитие: ожидали 404, получили %d", w.Code)
+	}
+
+	// админ общежития - админ только в нём
+	for path, want := range map[string]int{"/d/east/admin/audit": 200, "/admin/audit": 403, "/d/main/admin/audit": 403} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", path, nil), warden))
+		if w.Code != want {
+			t.Fatalf("%s админом east: ожидали %d, получили %d", path, want, w.Code)
+		}
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+mainB.ID, nil), warden))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("отмена брони main админом east: ожидали 403, получили %d", w.Code)
+	}
+
+	// общежития заводит только админ кампуса; админы общежитий в публичном списке не видны
+	dorm, _ := json.Marshal(map[string]any{"id": "west", "name": "Западное", "timezone": "Asia/Yekaterinburg"})
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/admin/dormitories", bytes.NewReader(dorm)), warden))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("создание общежития админом east: ожидали 403, получили %d", w.Code)
+	}
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/admin/dormitories", bytes.NewReader(dorm)), admin))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("создание общежития: ожидали 201, получили %d %s", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/dormitories", nil))
+	if !strings.Contains(w.Body.String(), `"west"`) || strings.Contains(w.Body.String(), "warden") {
+		t.Fatalf("публичный список общежитий: %s", w.Body.String())
+	}
+}
+
+// sseEvent - событие из потока /bookings/stream.
+type sseEvent struct {
+	Type    string
+	Booking appbooking.BookingDTO
+}
+
+// readEvents читает события потока в канал до закрытия соединения.
+func readEvents(t *testing.T, resp *http.Response) <-chan sseEvent {
+	out := make(chan sseEvent, 16)
+	go func() {
+		defer close(out)
+		var e sseEvent
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			line := sc.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				e.Type = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.Booking); err != nil {
+					t.Errorf("не смогли разобрать событие: %v", err)
+				}
+			case line == "" && e.Type != "":
+				out <- e
+				e = sseEvent{}
+			}
+		}
+	}()
+	return out
+}
+
+func TestBookingStream(t *testing.T) {
+	h := setupTestServer()
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/bookings/stream?room=21", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("не смогли подключиться к потоку: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("ожидали 200 и text/event-stream, получили %d %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	events := readEvents(t, resp)
+
+	next := func() sseEvent {
+		t.Helper()
+		select {
+		case e, ok := <-events:
+			if !ok {
+				t.Fatalf("поток закрылся раньше времени")
+			}
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatalf("не дождались события")
+		}
+		return sseEvent{}
+	}
+
+	session := h.login(t, "student@edu.hse.ru", "student")
+	create := func(room int, hour int) appbooking.BookingDTO {
+		start := time.Date(2099, 1, 5, hour, 0, 0, 0, time.UTC)
+		raw, _ := json.Marshal(map[string]any{
+			"start": start.Format(time.RFC3339), "end": start.Add(time.Hour).Format(time.RFC3339),
+			"room": room, "title": "Кино",
+		})
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session))
+		if w.Code != 200 {
+			t.Fatalf("создание брони: ожидали 200, получили %d %s", w.Code, w.Body.String())
+		}
+		var b appbooking.BookingDTO
+		_ = json.Unmarshal(w.Body.Bytes(), &b)
+		return b
+	}
+
+	// бронь в другой комнате под фильтр не попадает, первым придёт событие по 21-й
+	create(132, 10)
+	b := create(21, 12)
+	e := next()
+	if e.Type != "created" || e.Booking.ID != b.ID || e.Booking.Title != "Кино" {
+		t.Fatalf("ожидали created по брони %s, получили %+v", b.ID, e)
+	}
+	if e.Booking.CanManage {
+		t.Fatalf("анонимному зрителю бронь не должна быть доступна для управления")
+	}
+
+	title := "Лекция"
+	raw, _ := json.Marshal(map[string]any{"title": title})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("PATCH", "/bookings/"+b.ID, bytes.NewReader(raw)), session))
+	if e := next(); e.Type != "updated" || e.Booking.Title != title {
+		t.Fatalf("ожидали updated с новым названием, получили %+v", e)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+b.ID, nil), session))
+	if e := next(); e.Type != "deleted" || e.Booking.ID != b.ID || e.Booking.Status != domain.StatusCancelled {
+		t.Fatalf("ожидали deleted по брони %s, получили %+v", b.ID, e)
+	}
+}
+
+func TestBookingChanges(t *testing.T) {
+	h := setupTestServer()
+	session := h.login(t, "student@edu.hse.ru", "student")
+
+	changes := func(since int64) (int, map[string]json.RawMessage) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", fmt.Sprintf("/bookings/changes?since=%d", since), nil))
+		var out map[string]json.RawMessage
+		_ = json.Unmarshal(w.Body.Bytes(), &out)
+		return w.Code, out
+	}
+	var revision int64
+	var deleted []string
+	var bookings []appbooking.BookingDTO
+
+	start := time.Date(2099, 1, 5, 10, 0, 0, 0, time.UTC)
+	raw, _ := json.Marshal(map[string]any{
+		"start": start.Format(time.RFC3339), "end": start.Add(time.Hour).Format(time.RFC3339),
+		"room": 21, "title": "Кино",
+	})
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), session))
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	code, out := changes(0)
+	_ = json.Unmarshal(out["revision"], &revision)
+	_ = json.Unmarshal(out["bookings"], &bookings)
+	if code != 200 || len(bookings) != 1 || bookings[0].ID != created.ID || revision != created.Revision {
+		t.Fatalf("изменения с начала: %d %v", code, out)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("DELETE", "/bookings/"+created.ID, nil), session))
+
+	code, out = changes(revision)
+	_ = json.Unmarshal(out["bookings"], &bookings)
+	_ = json.Unmarshal(out["deleted"], &deleted)
+	if code != 200 || len(bookings) != 0 || len(deleted) != 1 || deleted[0] != created.ID {
+		t.Fatalf("после отмены ожидали только удаление %s: %d %v", created.ID, code, out)
+	}
+
+	code, out = changes(1 << 40)
+	if code != http.StatusGone || string(out["code"]) != `"CURSOR_EXPIRED"` {
+		t.Fatalf("устаревший курсор: ожидали 410 CURSOR_EXPIRED, получили %d %v", code, out)
+	}
+}
+
+func TestLoginThrottle_BehindProxy(t *testing.T) {
+	proxies, err := server.ParseTrustedProxies("10.0.0.0/8")
+	if err != nil {
+		t.Fatalf("ParseTrustedProxies вернул ошибку: %v", err)
+	}
+	ts := setupServer(nil, server.WithTrustedProxies(proxies))
+
+	n := 0
+	login := func(remote string, header http.Header) int {
+		n++
+		raw, _ := json.Marshal(map[string]string{"email": fmt.Sprintf("user%d@edu.hse.ru", n)})
+		r := httptest.NewRequest("POST", "/auth/login", bytes.NewReader(raw))
+		r.RemoteAddr = remote + ":40000"
+		for k, v := range header {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		ts.ServeHTTP(w, r)
+		return w.Code
+	}
+	via := func(client string) http.Header {
+		// клиент подставил свой X-Forwarded-For, nginx дописал настоящий адрес
+		return http.Header{"X-Forwarded-For": {"6.6.6.6, " + client}, "X-Real-IP": {client}}
+	}
+
+	// все ходят через один nginx, но лимит у каждого клиента свой
+	for i := 0; i < 60; i++ {
+		if code := login("10.0.0.2", via("198.51.100.1")); code != http.StatusAccepted {
+			t.Fatalf("попытка %d: ожидали 202, получили %d", i+1, code)
+		}
+	}
+	if code := login("10.0.0.2", via("198.51.100.1")); code != http.StatusTooManyRequests {
+		t.Fatalf("сверх лимита: ожидали 429, получили %d", code)
+	}
+	if code := login("10.0.0.2", via("198.51.100.2")); code != http.StatusAccepted {
+		t.Fatalf("другой клиент за тем же прокси: ожидали 202, получили %d", code)
+	}
+	if code := login("10.0.0.2", http.Header{"X-Real-IP": {"198.51.100.3"}}); code != http.StatusAccepted {
+		t.Fatalf("X-Real-IP от прокси: ожидали 202, получили %d", code)
+	}
+
+	// не от прокси заголовкам не верим: подмена X-Forwarded-For лимит не сбрасывает
+	for i := 0; i < 60; i++ {
+		login("203.0.113.9", via(fmt.Sprintf("192.0.2.%d", i)))
+	}
+	if code := login("203.0.113.9", via("192.0.2.200")); code != http.StatusTooManyRequests {
+		t.Fatalf("подменённый X-Forwarded-For: ожидали 429, получили %d", code)
+	}
+}
+
+func TestAdminAuditLog_BehindProxy(t *testing.T) {
+	proxies, _ := server.ParseTrustedProxies("10.0.0.2")
+	h := setupServer(nil, server.WithTrustedProxies(proxies))
+	student := h.login(t, "student@edu.hse.ru", "student")
+	admin := h.login(t, "admin@edu.hse.ru", "admin")
+
+	raw, _ := json.Marshal(map[string]any{"start": "2099-01-05T10:00:00Z", "end": "2099-01-05T11:00:00Z", "room": 132, "title": "Кино"})
+	req := withCookie(httptest.NewRequest("POST", "/bookings", bytes.NewReader(raw)), student)
+	req.RemoteAddr = "10.0.0.2:40000"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	var created appbooking.BookingDTO
+	_ = json.Unmarshal(w.Body.Bytes(), &created)
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, withCookie(httptest.NewRequest("GET", "/admin/audit?booking="+created.ID, nil), admin))
+	var entries []audit.Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil || len(entries) != 1 {
+		t.Fatalf("ожидали 1 запись, получили %s", w.Body.String())
+	}
+	if entries[0].IP != "198.51.100.7" {
+		t.Fatalf("в журнале адрес прокси, а не клиента: %q", entries[0].IP)
+	}
+}
@@ -0,0 +1,47 @@
+package server
+
+// В этом файле HTTP-обработчики настроек уведомлений текущего пользователя.
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"Dormitory_Booking/internal/domain/notification"
+)
+
+func (h *Handlers) GetNotificationPrefs(w http.ResponseWriter, r *http.Request) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	p, err := h.notify.GetPreferences(r.Context(), u.ID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (h *Handlers) SaveNotificationPrefs(w http.ResponseWriter, r *http.Request) {
+	u, err := h.currentUser(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	var p notification.Preferences
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+	p.UserID = u.ID
+
+	p, err = h.notify.SavePreferences(r.Context(), p)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, p)
+}
@@ -0,0 +1,45 @@
+package server
+
+// В этом файле HTTP-обработчики политики бронирования для админки.
+
+import (
+	"encoding/json"
+	"net/http"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+)
+
+func (h *Handlers) GetPolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+
+	p, err := h.bookings(r).CurrentPolicy(r.Context())
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, p)
+}
+
+func (h *Handlers) SavePolicy(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+
+	var p appbooking.Policy
+	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	saved, err := h.bookings(r).SavePolicy(r.Context(), p)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, saved)
+}
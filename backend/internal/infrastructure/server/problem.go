@@ -0,0 +1,128 @@
+package server
+
+// В этом файле ответы об ошибках в формате RFC 7807 (application/problem+json).
+// Статус выбирается по виду ошибки, текст - по Accept-Language (ru или en).
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+)
+
+type problem struct {
+	Type     string        `json:"type"`
+	Title    string        `json:"title"`
+	Status   int           `json:"status"`
+	Detail   string        `json:"detail,omitempty"`
+	Instance string        `json:"instance,omitempty"`
+	Code     apperror.Code `json:"code"`
+	Error    string        `json:"error"` // текст для показа пользователю, его читает фронтенд
+
+	// для нарушений политики бронирования
+	Rule          string         `json:"rule,omitempty"`
+	Params        map[string]any `json:"params,omitempty"`
+	PolicyVersion *int           `json:"policyVersion,omitempty"`
+}
+
+// writeError отвечает на ошибку сервиса. Ошибки без кода считаются внутренними:
+// клиенту уходит общий текст, подробности - в лог.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	e := apperror.From(err)
+	lang := language(r)
+
+	p := problem{
+		Type:     "/problems/" + strings.ToLower(strings.ReplaceAll(string(e.Code), "_", "-")),
+		Title:    e.Localized(lang),
+		Status:   statusFor(e.Kind),
+		Instance: r.URL.Path,
+		Code:     e.Code,
+	}
+	switch {
+	case e == apperror.Internal:
+		log.Printf("%s %s: %v", r.Method, r.URL.Path, err)
+		p.Detail = p.Title
+	case lang == apperror.LangEN:
+		p.Detail = p.Title
+	default:
+		// по-русски отдаём полный текст, в нём бывают подробности (например, какая комната)
+		p.Detail = err.Error()
+	}
+	p.Error = p.Detail
+
+	var v *appbooking.RuleViolation
+	if errors.As(err, &v) {
+		p.Rule = v.Rule
+		p.Params = v.Params
+		if v.Version > 0 {
+			p.PolicyVersion = &v.Version
+		}
+	}
+
+	writeProblem(w, lang, p)
+}
+
+// writeBadRequest - запрос не разобрать: битый JSON, неверный параметр.
+// detail технический ("invalid start time"), его не переводим.
+func writeBadRequest(w http.ResponseWriter, r *http.Request, detail string) {
+	e := apperror.BadRequest
+	lang := language(r)
+	title := e.Localized(lang)
+	writeProblem(w, lang, problem{
+		Type:     "/problems/bad-request",
+		Title:    title,
+		Status:   http.StatusBadRequest,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     e.Code,
+		Error:    title + " " + detail,
+	})
+}
+
+func writeProblem(w http.ResponseWriter, lang string, p problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("Content-Language", lang)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+func statusFor(k apperror.Kind) int {
+	switch k {
+	case apperror.KindBadRequest:
+		return http.StatusBadRequest
+	case apperror.KindValidation:
+		return http.StatusUnprocessableEntity
+	case apperror.KindUnauthorized:
+		return http.StatusUnauthorized
+	case apperror.KindForbidden:
+		return http.StatusForbidden
+	case apperror.KindNotFound:
+		return http.StatusNotFound
+	case apperror.KindConflict:
+		return http.StatusConflict
+	case apperror.KindRateLimited:
+		return http.StatusTooManyRequests
+	case apperror.KindGone:
+		return http.StatusGone
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// language выбирает язык по Accept-Language: английский, если он идёт раньше русского.
+func language(r *http.Request) string {
+	for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+		tag := strings.ToLower(strings.TrimSpace(strings.SplitN(part, ";", 2)[0]))
+		switch {
+		case strings.HasPrefix(tag, "ru"):
+			return apperror.LangRU
+		case strings.HasPrefix(tag, "en"):
+			return apperror.LangEN
+		}
+	}
+	return apperror.LangRU
+}
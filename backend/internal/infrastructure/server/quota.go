@@ -0,0 +1,24 @@
+package server
+
+// В этом файле остаток личных лимитов бронирования.
+
+import (
+	"net/http"
+	"time"
+)
+
+// MyQuota - GET /me/quota: лимиты из политики и сколько из них уже выбрано на этой неделе.
+func (h *Handlers) MyQuota(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	usage, err := h.bookings(r).MyQuota(r.Context(), requesterID, time.Now().In(h.location(r)))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, usage)
+}
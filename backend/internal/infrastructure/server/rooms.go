@@ -0,0 +1,112 @@
+package server
+
+// В этом файле HTTP-обработчики каталога комнат: публичный просмотр и админский CRUD.
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+func (h *Handlers) ListRooms(w http.ResponseWriter, r *http.Request) {
+	rooms, err := h.bookings(r).ListRooms(r.Context(), h.isAdmin(r))
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, rooms)
+}
+
+func (h *Handlers) GetRoom(w http.ResponseWriter, r *http.Request) {
+	number, ok := roomParam(w, r)
+	if !ok {
+		return
+	}
+
+	room, err := h.bookings(r).GetRoom(r.Context(), number)
+	if err != nil || (!room.Active && !h.isAdmin(r)) {
+		if err == nil {
+			err = apperror.NotFound
+		}
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, room)
+}
+
+func (h *Handlers) CreateRoom(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+
+	var room domain.RoomInfo
+	if err := json.NewDecoder(r.Body).Decode(&room); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	created, err := h.bookings(r).CreateRoom(r.Context(), room)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, created)
+}
+
+func (h *Handlers) UpdateRoom(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	number, ok := roomParam(w, r)
+	if !ok {
+		return
+	}
+
+	var room domain.RoomInfo
+	if err := json.NewDecoder(r.Body).Decode(&room); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+	room.Number = number
+
+	updated, err := h.bookings(r).UpdateRoom(r.Context(), room)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, updated)
+}
+
+func (h *Handlers) DeleteRoom(w http.ResponseWriter, r *http.Request) {
+	if !h.isAdmin(r) {
+		writeError(w, r, apperror.Forbidden)
+		return
+	}
+	number, ok := roomParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.bookings(r).DeleteRoom(r.Context(), number); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func roomParam(w http.ResponseWriter, r *http.Request) (domain.Room, bool) {
+	n, err := strconv.Atoi(chi.URLParam(r, "number"))
+	if err != nil {
+		writeBadRequest(w, r, "invalid room")
+		return 0, false
+	}
+	return domain.Room(n), true
+}
@@ -2,34 +2,150 @@ package server
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
 
+	appauth "Dormitory_Booking/internal/application/auth"
 	appbooking "Dormitory_Booking/internal/application/booking"
+	appnotify "Dormitory_Booking/internal/application/notify"
+	domain "Dormitory_Booking/internal/domain/booking"
 )
 
-func NewRouter(svc *appbooking.Service) http.Handler {
+// Option - необязательная часть API.
+type Option func(*Handlers)
+
+// WithNotifications включает настройки уведомлений (/me/notifications).
+func WithNotifications(n *appnotify.Service) Option {
+	return func(h *Handlers) {
+		h.notify = n
+	}
+}
+
+// WithLocation задаёт часовой пояс календарных лент (по умолчанию UTC).
+func WithLocation(loc *time.Location) Option {
+	return func(h *Handlers) {
+		h.loc = loc
+	}
+}
+
+// WithEvents включает живое обновление броней (/bookings/stream).
+func WithEvents(events domain.EventBus) Option {
+	return func(h *Handlers) {
+		h.events = events
+	}
+}
+
+func NewRouter(svc *appbooking.Service, auth *appauth.Service, opts ...Option) http.Handler {
 	r := chi.NewRouter()
 
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "DELETE"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
 		AllowCredentials: true,
 	}))
 
-	h := NewHandlers(svc)
+	h := NewHandlers(svc, auth)
+	for _, opt := range opts {
+		opt(h)
+	}
+	r.Use(h.withAuditRequest)
+
+	if h.dorms == nil {
+		h.routes(r)
+		return r
+	}
+
+	// каталог общежитий - один на весь кампус
+	r.Get("/dormitories", h.ListDormitories)
+	r.Post("/admin/dormitories", h.CreateDormitory)
+	r.Put("/admin/dormitories/{id}", h.UpdateDormitory)
+
+	// те же маршруты внутри общежития: /d/{dormitory}/bookings или по домену
+	r.Route("/d/{dormitory}", func(r chi.Router) {
+		r.Use(h.withDormitory(true))
+		h.routes(r)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(h.withDormitory(false))
+		h.routes(r)
+	})
+
+	return r
+}
+
+// routes - API одного общежития.
+func (h *Handlers) routes(r chi.Router) {
+	// вход по корпоративной почте
+	r.Post("/auth/login", h.RequestLogin)
+	r.Post("/auth/verify", h.VerifyLogin)
+	r.Get("/auth/verify", h.VerifyLink)
+	r.Post("/auth/logout", h.Logout)
+	r.Get("/me", h.Me)
+	r.Put("/me/telegram", h.LinkTelegram)
+	r.Get("/me/quota", h.MyQuota)
+	if h.notify != nil {
+		r.Get("/me/notifications", h.GetNotificationPrefs)
+		r.Put("/me/notifications", h.SaveNotificationPrefs)
+	}
 
 	// логин в админку
 	r.Post("/admin/login", h.AdminLogin)
-	r.Post("/admin/logout", h.AdminLogout)
+	r.Post("/admin/logout", h.Logout)
+	r.Delete("/admin/users/{id}/sessions", h.RevokeSessions)
+	r.Get("/admin/audit", h.AuditLog)
+
+	// политика бронирования
+	r.Get("/admin/policy", h.GetPolicy)
+	r.Put("/admin/policy", h.SavePolicy)
+
+	// каталог комнат
+	r.Get("/rooms", h.ListRooms)
+	r.Get("/rooms/{number}", h.GetRoom)
+	r.Post("/admin/rooms", h.CreateRoom)
+	r.Put("/admin/rooms/{number}", h.UpdateRoom)
+	r.Delete("/admin/rooms/{number}", h.DeleteRoom)
+	r.Get("/admin/rooms/{number}/checkin", h.RoomCheckIn)
+	r.Post("/admin/rooms/{number}/checkin", h.RoomCheckIn)
+
+	// календари
+	r.Get("/me/calendar", h.CalendarLink)
+	r.Post("/me/calendar", h.CalendarLink)
+	r.Get("/calendar/users/{token}.ics", h.UserFeed)
+	r.Get("/calendar/rooms/{number}.ics", h.RoomFeed)
+
+	// свободное время
+	r.Get("/availability", h.Availability)
 
 	// брони
 	r.Get("/bookings", h.GetAll)
+	r.Get("/bookings/changes", h.Changes)
+	if h.events != nil {
+		r.Get("/bookings/stream", h.Stream)
+	}
 	r.Get("/bookings/{id}", h.GetOne)
 	r.Post("/bookings", h.Create)
+	r.Post("/bookings/validate", h.Validate)
+	r.Patch("/bookings/{id}", h.Update)
 	r.Delete("/bookings/{id}", h.Delete)
+	r.Post("/admin/bookings/{id}/restore", h.Restore)
 
-	return r
+	// отметка о приходе
+	r.Post("/bookings/{id}/checkin", h.CheckIn)
+	r.Get("/checkin/{token}", h.CheckInByQR)
+	r.Post("/checkin/{token}", h.CheckInByQR)
+	r.Get("/admin/no-shows", h.NoShows)
+
+	// очередь ожидания на занятое время
+	r.Post("/waitlist", h.JoinWaitlist)
+	r.Get("/waitlist", h.MyWaitlist)
+	r.Delete("/waitlist/{id}", h.LeaveWaitlist)
+	r.Post("/waitlist/{id}/confirm", h.ConfirmWaitlist)
+
+	// повторяющиеся брони
+	r.Post("/series", h.CreateSeries)
+	r.Get("/series/{id}", h.GetSeries)
+	r.Delete("/series/{id}", h.CancelSeries)
 }
@@ -0,0 +1,164 @@
+package server
+
+// В этом файле HTTP-обработчики для повторяющихся броней.
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+type occurrenceConflictDTO struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Error string    `json:"error"`
+}
+
+type seriesDTO struct {
+	Series      *domain.Series          `json:"series"` // null, если не создалось ни одного занятия или серия чужая и частная
+	Occurrences []appbooking.BookingDTO `json:"occurrences"`
+	Conflicts   []occurrenceConflictDTO `json:"conflicts,omitempty"`
+}
+
+func (h *Handlers) CreateSeries(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Start       string `json:"start"`
+		End         string `json:"end"`
+		Room        int    `json:"room"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		IsPrivate   bool   `json:"isPrivate"`
+		Recurrence  struct {
+			Freq  string   `json:"freq"`  // weekly | biweekly
+			ByDay []string `json:"byDay"` // MO, TU, ...
+			Until string   `json:"until"` // RFC3339, включительно по дате
+			Count int      `json:"count"` // или число занятий
+		} `json:"recurrence"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeBadRequest(w, r, "invalid json")
+		return
+	}
+
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339, body.Start)
+	if err != nil {
+		writeBadRequest(w, r, "invalid start time")
+		return
+	}
+	end, err := time.Parse(time.RFC3339, body.End)
+	if err != nil {
+		writeBadRequest(w, r, "invalid end time")
+		return
+	}
+
+	rule := domain.Recurrence{
+		Freq:  domain.Frequency(body.Recurrence.Freq),
+		Count: body.Recurrence.Count,
+	}
+	for _, code := range body.Recurrence.ByDay {
+		wd, ok := domain.ParseWeekday(code)
+		if !ok {
+			writeBadRequest(w, r, "invalid byDay")
+			return
+		}
+		rule.Weekdays = append(rule.Weekdays, wd)
+	}
+	if body.Recurrence.Until != "" {
+		until, err := time.Parse(time.RFC3339, body.Recurrence.Until)
+		if err != nil {
+			writeBadRequest(w, r, "invalid until")
+			return
+		}
+		rule.Until = until
+	}
+
+	res, err := h.bookings(r).CreateSeries(r.Context(), appbooking.CreateSeriesInput{
+		CreateBookingInput: appbooking.CreateBookingInput{
+			Start:       start,
+			End:         end,
+			Room:        domain.Room(body.Room),
+			Title:       body.Title,
+			Description: body.Description,
+			TelegramID:  requesterID,
+			IsPrivate:   body.IsPrivate,
+		},
+		Rule: rule,
+	})
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	out := seriesDTO{Occurrences: make([]appbooking.BookingDTO, 0, len(res.Created))}
+	if res.Series.ID != "" {
+		out.Series = &res.Series
+	}
+	for _, b := range res.Created {
+		out.Occurrences = append(out.Occurrences, appbooking.ToDTO(b, requesterID, h.isAdmin(r)))
+	}
+	for _, c := range res.Conflicts {
+		out.Conflicts = append(out.Conflicts, occurrenceConflictDTO{Start: c.Start, End: c.End, Error: c.Err.Error()})
+	}
+	writeJSON(w, out)
+}
+
+func (h *Handlers) GetSeries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	series, occurrences, err := h.bookings(r).GetSeries(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	viewer, isAdmin := h.viewer(r), h.isAdmin(r)
+	out := seriesDTO{
+		Series:      appbooking.ProjectSeries(series, viewer, isAdmin),
+		Occurrences: make([]appbooking.BookingDTO, 0, len(occurrences)),
+	}
+	for _, b := range occurrences {
+		out.Occurrences = append(out.Occurrences, appbooking.ToDTO(b, viewer, isAdmin))
+	}
+	writeJSON(w, out)
+}
+
+// CancelSeries отменяет занятия серии с ?from= (RFC3339) и дальше; без from - всю серию.
+// Одно занятие отменяется обычным DELETE /bookings/{id}.
+func (h *Handlers) CancelSeries(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	isAdmin := h.isAdmin(r)
+	requesterID, err := h.requester(r)
+	if err != nil && !isAdmin {
+		writeError(w, r, err)
+		return
+	}
+
+	var from time.Time
+	if v := r.URL.Query().Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			writeBadRequest(w, r, "invalid from")
+			return
+		}
+		from = t
+	}
+
+	n, err := h.bookings(r).CancelSeries(r.Context(), id, from, requesterID, isAdmin)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, map[string]int{"cancelled": n})
+}
@@ -0,0 +1,89 @@
+package server
+
+// В этом файле живое обновление броней через Server-Sent Events.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// streamPing - как часто слать комментарий, чтобы прокси не закрывали тихое соединение.
+const streamPing = 25 * time.Second
+
+// Stream - GET /bookings/stream: события created, updated и deleted по броням под фильтр
+// (те же from, to, room, owner, isPrivate, что у GET /bookings). В data - бронь в том
+// же виде, что отдаёт GET /bookings/{id} этому зрителю. updated приходит и тогда, когда
+// бронь ушла из фильтра: клиент сам убирает её из таблицы.
+func (h *Handlers) Stream(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseListFilter(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+	viewer, isAdmin := h.viewer(r), h.isAdmin(r)
+	filter, visible := restrictOwner(filter, viewer, isAdmin)
+	// удаление приходит уже отменённой бронью
+	filter.IncludeCancelled = true
+
+	dorm := ""
+	if t, ok := tenantOf(r); ok {
+		dorm = t.dorm.ID
+	}
+
+	rc := http.NewResponseController(w)
+	// общий WriteTimeout сервера оборвал бы поток через несколько секунд
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	events := h.events.Subscribe(r.Context())
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(streamPing)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprint(w, ": ping\n\n")
+		case e, open := <-events:
+			if !open {
+				// не успели прочитать: клиент переподключится и перечитает брони
+				return
+			}
+			if !visible || !streamMatches(e, filter, dorm) {
+				continue
+			}
+			data, err := json.Marshal(appbooking.ToDTO(e.Booking, viewer, isAdmin))
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// streamMatches - касается ли событие подписчика: своё общежитие ("" - любое) и бронь
+// под фильтром сейчас или до изменения.
+func streamMatches(e domain.Event, f domain.ListFilter, dorm string) bool {
+	if dorm != "" && e.Booking.Dormitory != dorm {
+		return false
+	}
+	return f.Matches(e.Booking) || (e.Previous != nil && f.Matches(*e.Previous))
+}
@@ -0,0 +1,87 @@
+package server
+
+// В этом файле HTTP-обработчики очереди ожидания на занятое время.
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+)
+
+// JoinWaitlist - POST /waitlist: то же тело, что у POST /bookings, на время, которое занято.
+func (h *Handlers) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	input, err := decodeCreateInput(r)
+	if err != nil {
+		writeBadRequest(w, r, err.Error())
+		return
+	}
+
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	input.TelegramID = requesterID
+
+	e, err := h.bookings(r).JoinWaitlist(r.Context(), input)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	writeJSON(w, e)
+}
+
+// MyWaitlist - GET /waitlist: ждущие и предложенные заявки текущего пользователя.
+func (h *Handlers) MyWaitlist(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	list, err := h.bookings(r).MyWaitlist(r.Context(), requesterID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if list == nil {
+		list = []domain.WaitlistEntry{}
+	}
+	writeJSON(w, list)
+}
+
+// LeaveWaitlist - DELETE /waitlist/{id}.
+func (h *Handlers) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	isAdmin := h.isAdmin(r)
+	requesterID, err := h.requester(r)
+	if err != nil && !isAdmin {
+		writeError(w, r, err)
+		return
+	}
+
+	if err := h.bookings(r).LeaveWaitlist(r.Context(), chi.URLParam(r, "id"), requesterID, isAdmin); err != nil {
+		writeError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ConfirmWaitlist - POST /waitlist/{id}/confirm: забрать закреплённый слот, пока не истёк срок.
+func (h *Handlers) ConfirmWaitlist(w http.ResponseWriter, r *http.Request) {
+	requesterID, err := h.requester(r)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	b, err := h.bookings(r).ConfirmHold(r.Context(), chi.URLParam(r, "id"), requesterID)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	writeJSON(w, appbooking.ToDTO(b, requesterID, h.isAdmin(r)))
+}
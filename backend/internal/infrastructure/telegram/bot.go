@@ -0,0 +1,311 @@
+package telegram
+
+// В этом файле телеграм-бот поверх сервиса бронирований: /book, /my, /cancel, /free.
+// Пользователь опознаётся по нику в Telegram, который он привязал к профилю на сайте
+// и подтвердил здесь кодом (/link).
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	appauth "Dormitory_Booking/internal/application/auth"
+	appbooking "Dormitory_Booking/internal/application/booking"
+	"Dormitory_Booking/internal/domain/apperror"
+	domain "Dormitory_Booking/internal/domain/booking"
+	domainuser "Dormitory_Booking/internal/domain/user"
+)
+
+const helpText = `Команды:
+/book <комната> <ГГГГ-ММ-ДД> <ЧЧ:ММ-ЧЧ:ММ> <название> - забронировать
+/my - мои ближайшие брони
+/cancel <id> - отменить бронь (хватит первых символов id из /my)
+/free <комната> <ГГГГ-ММ-ДД> - свободное время в комнате
+/link <код> - привязать Telegram к профилю (код выдаёт сайт)`
+
+type Bot struct {
+	client      *Client
+	svc         *appbooking.Service
+	auth        *appauth.Service
+	users       domainuser.Repository
+	loc         *time.Location
+	pollTimeout time.Duration
+}
+
+func NewBot(client *Client, svc *appbooking.Service, auth *appauth.Service, users domainuser.Repository, loc *time.Location) *Bot {
+	return &Bot{
+		client:      client,
+		svc:         svc,
+		auth:        auth,
+		users:       users,
+		loc:         loc,
+		pollTimeout: 30 * time.Second,
+	}
+}
+
+// SetPollTimeout меняет таймаут long polling (в тестах его удобно уменьшить).
+func (b *Bot) SetPollTimeout(d time.Duration) {
+	b.pollTimeout = d
+}
+
+// Run опрашивает Bot API, пока не отменят ctx.
+func (b *Bot) Run(ctx context.Context) error {
+	var offset int64
+	for {
+		updates, err := b.client.GetUpdates(ctx, offset, b.pollTimeout)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("telegram: %v", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateID + 1
+			if u.Message == nil || u.Message.Text == "" {
+				continue
+			}
+			reply := b.Handle(ctx, u.Message)
+			if err := b.client.SendMessage(ctx, u.Message.Chat.ID, reply); err != nil {
+				log.Printf("telegram: %v", err)
+			}
+		}
+	}
+}
+
+// Handle выполняет команду из сообщения и возвращает текст ответа.
+func (b *Bot) Handle(ctx context.Context, m *Message) string {
+	fields := strings.Fields(m.Text)
+	if len(fields) == 0 {
+		return helpText
+	}
+	// в группах команда приходит как /book@botname
+	cmd, _, _ := strings.Cut(fields[0], "@")
+	args := fields[1:]
+
+	// ссылка t.me/<бот>?start=<код> приходит как /start <код>
+	if cmd == "/link" || (cmd == "/start" && len(args) == 1) {
+		return b.link(ctx, m, args)
+	}
+	if cmd == "/start" || cmd == "/help" {
+		return helpText
+	}
+
+	owner, err := b.owner(ctx, m)
+	if err != nil {
+		return errorText(err)
+	}
+
+	switch cmd {
+	case "/book":
+		return b.book(ctx, owner, args)
+	case "/my":
+		return b.my(ctx, owner)
+	case "/cancel":
+		return b.cancel(ctx, owner, args)
+	case "/free":
+		return b.free(ctx, args)
+	default:
+		return helpText
+	}
+}
+
+// owner - Telegram-ник отправителя, если он привязан к пользователю на сайте.
+// Заодно запоминаем ID личного чата, чтобы потом слать туда напоминания.
+func (b *Bot) owner(ctx context.Context, m *Message) (string, error) {
+	if m.From == nil || m.From.Username == "" {
+		return "", domainuser.ErrTelegramNotLinked
+	}
+	tg := domainuser.NormalizeTelegram(m.From.Username)
+	u, err := b.users.GetByTelegram(ctx, tg)
+	if err != nil {
+		if errors.Is(err, domainuser.ErrNotFound) {
+			return "", domainuser.ErrTelegramNotLinked
+		}
+		return "", err
+	}
+
+	if m.Chat.ID == m.From.ID && u.TelegramChatID != m.Chat.ID {
+		u.TelegramChatID = m.Chat.ID
+		if _, err := b.users.Update(ctx, u); err != nil {
+			log.Printf("telegram: не удалось сохранить chat_id: %v", err)
+		}
+	}
+	return tg, nil
+}
+
+// link подтверждает привязку Telegram кодом, выданным на сайте. Ник берём из
+// самого сообщения - его подделать нельзя.
+func (b *Bot) link(ctx context.Context, m *Message, args []string) string {
+	if len(args) != 1 {
+		return "Формат: /link <код с сайта>"
+	}
+	if m.From == nil || m.From.Username == "" {
+		return "Задайте имя пользователя в настройках Telegram: профиль привязывается к нему."
+	}
+	var chatID int64
+	if m.Chat.ID == m.From.ID {
+		chatID = m.Chat.ID
+	}
+	u, err := b.auth.ConfirmTelegram(ctx, args[0], m.From.Username, chatID)
+	if err != nil {
+		return errorText(err)
+	}
+	return fmt.Sprintf("Telegram @%s привязан к %s.\n\n%s", u.TelegramID, u.Email, helpText)
+}
+
+func (b *Bot) book(ctx context.Context, owner string, args []string) string {
+	if len(args) < 4 {
+		return "Формат: /book 21 2025-03-14 19:00-21:00 Настолки"
+	}
+
+	room, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "Не понял номер комнаты."
+	}
+	startClock, endClock, ok := strings.Cut(args[2], "-")
+	if !ok {
+		return "Время укажите как 19:00-21:00."
+	}
+	start, err := time.ParseInLocation("2006-01-02 15:04", args[1]+" "+startClock, b.loc)
+	if err != nil {
+		return "Не понял дату или время начала."
+	}
+	end, err := time.ParseInLocation("2006-01-02 15:04", args[1]+" "+endClock, b.loc)
+	if err != nil {
+		return "Не понял время конца."
+	}
+	// 23:00-01:00 - конец уже на следующий день
+	if !end.After(start) {
+		end = end.AddDate(0, 0, 1)
+	}
+
+	created, err := b.svc.CreateBooking(ctx, appbooking.CreateBookingInput{
+		Start:      start,
+		End:        end,
+		Room:       domain.Room(room),
+		Title:      strings.Join(args[3:], " "),
+		TelegramID: owner,
+	})
+	if err != nil {
+		return errorText(err)
+	}
+	return "Готово: " + b.describe(created, owner)
+}
+
+func (b *Bot) my(ctx context.Context, owner string) string {
+	list, err := b.upcoming(ctx, owner)
+	if err != nil {
+		return errorText(err)
+	}
+	if len(list) == 0 {
+		return "Ближайших броней нет."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Ваши брони:\n")
+	for _, bk := range list {
+		sb.WriteString(b.describe(bk, owner))
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (b *Bot) cancel(ctx context.Context, owner string, args []string) string {
+	if len(args) != 1 {
+		return "Формат: /cancel <id>"
+	}
+
+	list, err := b.upcoming(ctx, owner)
+	if err != nil {
+		return errorText(err)
+	}
+	var matched []domain.Booking
+	for _, bk := range list {
+		if strings.HasPrefix(bk.ID, args[0]) {
+			matched = append(matched, bk)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "Среди ваших ближайших броней такой нет."
+	case 1:
+	default:
+		return "Под этот id подходит несколько броней, укажите подлиннее."
+	}
+
+	if err := b.svc.CancelBooking(ctx, matched[0].ID, owner, false, ""); err != nil {
+		return errorText(err)
+	}
+	return "Отменено: " + b.describe(matched[0], owner)
+}
+
+func (b *Bot) free(ctx context.Context, args []string) string {
+	if len(args) != 2 {
+		return "Формат: /free 21 2025-03-14"
+	}
+	room, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "Не понял номер комнаты."
+	}
+	day, err := time.ParseInLocation("2006-01-02", args[1], b.loc)
+	if err != nil {
+		return "Не понял дату."
+	}
+
+	slots, err := b.svc.FreeSlots(ctx, domain.Room(room), day)
+	if err != nil {
+		return errorText(err)
+	}
+	if len(slots) == 0 {
+		return fmt.Sprintf("Комната %d на %s занята целиком.", room, day.Format("02.01"))
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Свободно в %d на %s:\n", room, day.Format("02.01"))
+	for _, s := range slots {
+		fmt.Fprintf(&sb, "%s-%s\n", s.Start.In(b.loc).Format("15:04"), s.End.In(b.loc).Format("15:04"))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func (b *Bot) upcoming(ctx context.Context, owner string) ([]domain.Booking, error) {
+	return b.svc.FindBookings(ctx, domain.ListFilter{From: time.Now(), Owner: owner})
+}
+
+// describe - строка о брони глазами viewer (Telegram отправителя команды). Бот показывает
+// пользователю только его собственные брони, но и они идут через ту же проекцию, что JSON и календари.
+func (b *Bot) describe(bk domain.Booking, viewer string) string {
+	bk = appbooking.Project(bk, viewer, false)
+	start := bk.Start.In(b.loc)
+	return fmt.Sprintf("[%s] %d, %s %s-%s %s",
+		shortID(bk.ID), bk.Room, start.Format("02.01"), start.Format("15:04"), bk.End.In(b.loc).Format("15:04"), bk.Title)
+}
+
+func shortID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+// errorText - текст ошибки для пользователя. Как и HTTP-слой, опираемся на код ошибки
+// (apperror), а не на список известных: у всех доменных ошибок текст уже человеческий.
+func errorText(err error) string {
+	if apperror.From(err) == apperror.Internal {
+		log.Printf("telegram: %v", err)
+		return "Что-то пошло не так, попробуйте позже."
+	}
+	// полный текст, как в русском detail HTTP-ответа: в нём бывают подробности
+	return err.Error()
+}
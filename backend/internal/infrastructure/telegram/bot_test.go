@@ -0,0 +1,207 @@
+package telegram_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	appauth "Dormitory_Booking/internal/application/auth"
+	appbooking "Dormitory_Booking/internal/application/booking"
+	domain "Dormitory_Booking/internal/domain/booking"
+	domainuser "Dormitory_Booking/internal/domain/user"
+	"Dormitory_Booking/internal/infrastructure/memory"
+	"Dormitory_Booking/internal/infrastructure/telegram"
+)
+
+// fakeTelegram - локальный Bot API: отдаёт апдейты из очереди и запоминает ответы бота.
+type fakeTelegram struct {
+	updates chan telegram.Update
+	sent    chan string
+	nextID  int64
+}
+
+func newFakeTelegram(t *testing.T) (*fakeTelegram, *httptest.Server) {
+	f := &fakeTelegram{updates: make(chan telegram.Update, 16), sent: make(chan string, 16)}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botTEST/getUpdates":
+			var result []telegram.Update
+			select {
+			case u := <-f.updates:
+				result = append(result, u)
+			case <-time.After(50 * time.Millisecond):
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": result})
+		case "/botTEST/sendMessage":
+			var body struct {
+				Text string `json:"text"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			f.sent <- body.Text
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true, "result": map[string]any{}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": false, "description": "Not Found"})
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+// say отправляет сообщение от имени username и ждёт ответ бота.
+func (f *fakeTelegram) say(t *testing.T, username, text string) string {
+	t.Helper()
+	f.nextID++
+	f.updates <- telegram.Update{UpdateID: f.nextID, Message: &telegram.Message{
+		MessageID: f.nextID,
+		From:      &telegram.User{ID: 1, Username: username},
+		Chat:      telegram.Chat{ID: 1},
+		Text:      text,
+	}}
+	select {
+	case reply := <-f.sent:
+		return reply
+	case <-time.After(2 * time.Second):
+		t.Fatalf("бот не ответил на %q", text)
+		return ""
+	}
+}
+
+func TestBot_Commands(t *testing.T) {
+	fake, srv := newFakeTelegram(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	users := memory.NewInMemoryUserRepo()
+	users.Create(ctx, domainuser.User{Email: "student@edu.hse.ru", TelegramID: "student"})
+	msk := time.FixedZone("MSK", 3*60*60)
+	svc := appbooking.NewService(memory.NewInMemoryBookingRepo(), appbooking.WithLocation(msk))
+
+	bot := telegram.NewBot(telegram.NewClient(srv.URL, "TEST"), svc, nil, users, msk)
+	bot.SetPollTimeout(0)
+	done := make(chan error, 1)
+	go func() { done <- bot.Run(ctx) }()
+
+	if reply := fake.say(t, "stranger", "/my"); reply != domainuser.ErrTelegramNotLinked.Error() {
+		t.Fatalf("непривязанный ник: неожиданный ответ %q", reply)
+	}
+
+	reply := fake.say(t, "Student", "/book 132 2099-01-05 10:00-12:00 Настолки")
+	if !strings.HasPrefix(reply, "Готово") {
+		t.Fatalf("/book: неожиданный ответ %q", reply)
+	}
+	id := regexp.MustCompile(`\[(\w+)\]`).FindStringSubmatch(reply)[1]
+
+	if reply := fake.say(t, "student", "/book 132 2099-01-05 11:00-13:00 Ещё"); !strings.Contains(reply, "пересекается") {
+		t.Fatalf("пересечение: неожиданный ответ %q", reply)
+	}
+
+	if reply := fake.say(t, "student", "/free 132 2099-01-05"); !strings.Contains(reply, "06:00-10:00") || !strings.Contains(reply, "12:00-22:00") {
+		t.Fatalf("/free: неожиданный ответ %q", reply)
+	}
+
+	if reply := fake.say(t, "student", "/my"); !strings.Contains(reply, id) {
+		t.Fatalf("/my: неожиданный ответ %q", reply)
+	}
+
+	if reply := fake.say(t, "student", "/cancel "+id[:4]); !strings.HasPrefix(reply, "Отменено") {
+		t.Fatalf("/cancel: неожиданный ответ %q", reply)
+	}
+	if reply := fake.say(t, "student", "/my"); reply != "Ближайших броней нет." {
+		t.Fatalf("/my после отмены: неожиданный ответ %q", reply)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run вернул ошибку: %v", err)
+	}
+}
+
+func TestBot_Link(t *testing.T) {
+	fake, srv := newFakeTelegram(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	users := memory.NewInMemoryUserRepo()
+	u, _ := users.Create(ctx, domainuser.User{Email: "student@edu.hse.ru"})
+	auth := appauth.NewService(users, memory.NewInMemoryLoginCodeRepo(), memory.NewInMemorySessionRepo(), memory.NewInMemoryTelegramLinkRepo(), nil, appauth.Config{
+		EmailDomain: "edu.hse.ru", SessionSecret: []byte("secret"),
+	})
+	svc := appbooking.NewService(memory.NewInMemoryBookingRepo())
+
+	bot := telegram.NewBot(telegram.NewClient(srv.URL, "TEST"), svc, auth, users, time.UTC)
+	bot.SetPollTimeout(0)
+	done := make(chan error, 1)
+	go func() { done <- bot.Run(ctx) }()
+
+	req, err := auth.RequestTelegramLink(ctx, u.ID, "student")
+	if err != nil {
+		t.Fatalf("RequestTelegramLink вернул ошибку: %v", err)
+	}
+	if reply := fake.say(t, "student", "/link wrong"); reply != domainuser.ErrInvalidLinkCode.Error() {
+		t.Fatalf("неверный код: неожиданный ответ %q", reply)
+	}
+	// ссылка t.me/<бот>?start=<код> приходит боту как /start <код>
+	if reply := fake.say(t, "Student", "/start "+req.Code); !strings.Contains(reply, "привязан") {
+		t.Fatalf("/start с кодом: неожиданный ответ %q", reply)
+	}
+	if linked, _ := users.Get(ctx, u.ID); linked.TelegramID != "student" || linked.TelegramChatID != 1 {
+		t.Fatalf("привязка не сохранилась: %+v", linked)
+	}
+	if reply := fake.say(t, "student", "/my"); reply != "Ближайших броней нет." {
+		t.Fatalf("/my после привязки: неожиданный ответ %q", reply)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run вернул ошибку: %v", err)
+	}
+}
+
+func TestBot_ErrorTexts(t *testing.T) {
+	fake, srv := newFakeTelegram(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	users := memory.NewInMemoryUserRepo()
+	users.Create(ctx, domainuser.User{Email: "student@edu.hse.ru", TelegramID: "student"})
+	svc := appbooking.NewService(memory.NewInMemoryBookingRepo(), appbooking.WithWaitlist(memory.NewInMemoryWaitlistRepo(), time.Hour))
+
+	// слот освободился и держится для первого в очереди
+	start := time.Date(2099, 1, 5, 18, 0, 0, 0, time.UTC)
+	in := appbooking.CreateBookingInput{Start: start, End: start.Add(time.Hour), Room: domain.Room132, Title: "Кино", TelegramID: "owner"}
+	blocking, err := svc.CreateBooking(ctx, in)
+	if err != nil {
+		t.Fatalf("CreateBooking: %v", err)
+	}
+	in.TelegramID = "first"
+	if _, err := svc.JoinWaitlist(ctx, in); err != nil {
+		t.Fatalf("JoinWaitlist: %v", err)
+	}
+	if err := svc.CancelBooking(ctx, blocking.ID, "owner", false, ""); err != nil {
+		t.Fatalf("CancelBooking: %v", err)
+	}
+
+	bot := telegram.NewBot(telegram.NewClient(srv.URL, "TEST"), svc, nil, users, time.UTC)
+	bot.SetPollTimeout(0)
+	done := make(chan error, 1)
+	go func() { done <- bot.Run(ctx) }()
+
+	if reply := fake.say(t, "student", "/book 132 2099-01-05 18:00-19:00 Тоже кино"); reply != domain.ErrSlotHeld.Error() {
+		t.Fatalf("занятый очередью слот: неожиданный ответ %q", reply)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Run вернул ошибку: %v", err)
+	}
+}
@@ -0,0 +1,46 @@
+import type { Me, TelegramLink } from "../types/auth";
+import { readErrorText } from "./bookings";
+
+const API_BASE = (import.meta as any).env?.VITE_API_BASE || "";
+
+// Сессия живёт в HttpOnly cookie, поэтому все запросы идут с credentials: "include".
+function post(path: string, body?: unknown, method = "POST") {
+    return fetch(`${API_BASE}/api${path}`, {
+        method,
+        credentials: "include",
+        headers: { "Content-Type": "application/json" },
+        body: body === undefined ? undefined : JSON.stringify(body),
+    });
+}
+
+// fetchMe - текущий пользователь или null, если не вошли.
+export async function fetchMe(): Promise<Me | null> {
+    const r = await fetch(`${API_BASE}/api/me`, { credentials: "include" });
+    if (r.status === 401) return null;
+    if (!r.ok) throw new Error(await readErrorText(r));
+    return (await r.json()) as Me;
+}
+
+// requestLogin отправляет код на корпоративную почту.
+export async function requestLogin(email: string): Promise<void> {
+    const r = await post("/auth/login", { email });
+    if (!r.ok) throw new Error(await readErrorText(r));
+}
+
+// verifyLogin обменивает код из письма на cookie сессии.
+export async function verifyLogin(email: string, code: string): Promise<void> {
+    const r = await post("/auth/verify", { email, code });
+    if (!r.ok) throw new Error(await readErrorText(r));
+}
+
+export async function logout(): Promise<void> {
+    const r = await post("/auth/logout");
+    if (!r.ok) throw new Error(await readErrorText(r));
+}
+
+// linkTelegram начинает привязку ника: код из ответа нужно отправить боту.
+export async function linkTelegram(telegramId: string): Promise<TelegramLink> {
+    const r = await post("/me/telegram", { telegramId }, "PUT");
+    if (!r.ok) throw new Error(await readErrorText(r));
+    return (await r.json()) as TelegramLink;
+}
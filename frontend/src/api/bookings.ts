@@ -12,18 +12,18 @@ export function setAdminToken(t: string | null) {
 function withHeaders(extra?: Record<string, string>) {
     return {
         "Content-Type": "application/json",
-        "X-User-Email": "student@edu.hse.ru",
         ...(ADMIN_TOKEN ? { "X-Admin-Token": ADMIN_TOKEN } : {}),
         ...(extra ?? {}),
     };
 }
 
-async function readErrorText(r: Response): Promise<string> {
+export async function readErrorText(r: Response): Promise<string> {
     const ct = r.headers.get("content-type") || "";
     try {
-        if (ct.includes("application/json")) {
+        // API отвечает application/problem+json (RFC 7807)
+        if (ct.includes("json")) {
             const j = await r.json();
-            const msg = j?.error || j?.message || JSON.stringify(j);
+            const msg = j?.error || j?.detail || j?.title || j?.message || JSON.stringify(j);
             return typeof msg === "string" ? msg : String(msg);
         }
     } catch {
@@ -46,6 +46,29 @@ export async function fetchBookings(): Promise<Bookings[]> {
     return Array.isArray(data) ? data : [];
 }
 
+export type BookingEvent = { type: "created" | "updated" | "deleted"; booking: Bookings };
+
+// subscribeBookings - живые изменения броней (SSE). Возвращает функцию отписки.
+// EventSource сам переподключается после обрыва; что пропущено за это время, догружает onReconnect.
+export function subscribeBookings(onEvent: (e: BookingEvent) => void, onReconnect?: () => void): () => void {
+    const es = new EventSource(`${API_BASE}/api/bookings/stream`, { withCredentials: true });
+    let opened = false;
+    es.onopen = () => {
+        if (opened) onReconnect?.();
+        opened = true;
+    };
+    for (const type of ["created", "updated", "deleted"] as const) {
+        es.addEventListener(type, (m) => {
+            try {
+                onEvent({ type, booking: JSON.parse((m as MessageEvent).data) as Bookings });
+            } catch {
+                // ignore
+            }
+        });
+    }
+    return () => es.close();
+}
+
 export async function createBooking(payload: CreateBookingPayload): Promise<Bookings> {
     const r = await fetch(`${API_BASE}/api/bookings`, {
         method: "POST",
@@ -53,7 +76,7 @@ export async function createBooking(payload: CreateBookingPayload): Promise<Book
         headers: withHeaders({}),
         body: JSON.stringify(payload),
     });
-    if (!r.ok) throw new Error(await r.text());
+    if (!r.ok) throw new Error(await readErrorText(r));
     return (await r.json()) as Bookings;
 }
 
@@ -66,5 +89,5 @@ export async function deleteBooking(id: string, telegramId?: string): Promise<vo
         credentials: "include",
         headers: withHeaders({}),
     });
-    if (!r.ok && r.status !== 204) throw new Error(await r.text());
+    if (!r.ok && r.status !== 204) throw new Error(await readErrorText(r));
 }
\ No newline at end of file
@@ -17,6 +17,8 @@ import {Popover} from "../components/ui/Popover";
 import {DatePicker} from "../components/pickers/DatePicker";
 import {useAdmin} from "../admin/useAdmin";
 import {AdminModal} from "../admin/AdminModal";
+import {useSession} from "../auth/useSession";
+import {LoginModal} from "../auth/LoginModal";
 import * as api from "../api/bookings";
 import {setAdminToken} from "../api/bookings";
 import { RulesModal } from "../components/ui/RulesModal";
@@ -73,6 +75,8 @@ export function App() {
 
     const admin = useAdmin();
     const [adminOpen, setAdminOpen] = useState(false);
+    const session = useSession();
+    const [loginOpen, setLoginOpen] = useState(false);
 
     useEffect(() => {
         setAdminToken(admin.token);
@@ -110,7 +114,6 @@ export function App() {
         endTime: `${pad2(dE.getHours())}:${step5(dE.getMinutes())}`,
         room: 21 as 21 | 132 | 256,
         title: "",
-        telegramId: "",
         isPrivate: false,
         description: "",
     });
@@ -129,6 +132,17 @@ export function App() {
         fetchData();
     }, []);
 
+    // чужие брони появляются и пропадают без перезагрузки; после входа или выхода
+    // переподключаемся - поток отдаёт брони в том виде, что положен зрителю
+    useEffect(() => {
+        return api.subscribeBookings(({ type, booking }) => {
+            setBookings((prev) => {
+                const rest = (prev ?? []).filter((b) => b.id !== booking.id);
+                return type === "deleted" ? rest : [...rest, booking];
+            });
+        }, fetchData);
+    }, [session.me?.id]);
+
     const sorted = useMemo(
         () => (bookings ? [...bookings].sort((a, b) => +new Date(a.start) - +new Date(b.start)) : []),
         [bookings],
@@ -195,7 +209,6 @@ export function App() {
 
     function validate(): string | null {
         if (!form.title.trim()) return "Заполни поле «Название».";
-        if (!form.telegramId.trim()) return "Укажи Telegram ID.";
 
         let start: Date, end: Date;
         try {
@@ -218,8 +231,27 @@ export function App() {
         return null;
     }
 
+    // бронь записывается на Telegram из сессии, поэтому без входа и привязки сначала логин
+    function handleToggleAdd() {
+        if (!adding && !session.canBook) return setLoginOpen(true);
+        setAdding((v) => !v);
+    }
+
+    async function handleSessionRefresh() {
+        const u = await session.refresh();
+        await fetchData(); // после входа видны подробности своих частных броней
+        return u;
+    }
+
+    async function handleLogout() {
+        await session.logout();
+        setAdding(false);
+        await fetchData();
+    }
+
     async function handleCreate() {
         setErrMsg("");
+        if (!session.canBook) return setLoginOpen(true);
         const e = validate();
         if (e) return setErrMsg(e);
 
@@ -229,7 +261,6 @@ export function App() {
             end: end.toISOString(),
             room: form.room,
             title: form.title.trim(),
-            telegramId: form.telegramId.trim(),
             isPrivate: form.isPrivate,
             description: form.description?.trim() || undefined,
         };
@@ -261,7 +292,8 @@ export function App() {
                 loading={loading}
                 view={view}
                 isAdmin={admin.isAdmin}
-                onToggleAdd={() => setAdding((v) => !v)}
+                user={session.me ? session.me.telegramId || session.me.email : null}
+                onToggleAdd={handleToggleAdd}
                 onRefresh={fetchData}
                 onToggleView={() => {
                     setView((v) => (v === "cards" ? "table" : "cards"));
@@ -270,6 +302,8 @@ export function App() {
                 onAdminClick={() => setAdminOpen(true)}
                 onAdminLogout={admin.logout}
                 onRulesClick={() => setRulesOpen(true)}
+                onLoginClick={() => setLoginOpen(true)}
+                onLogout={handleLogout}
             />
 
 
@@ -334,11 +368,10 @@ export function App() {
                 </span>
                             </label>
 
-                            <label className="flex flex-col gap-1 text-sm">
+                            <div className="flex flex-col gap-1 text-sm">
                                 <span className="lbl">Telegram ID</span>
-                                <input className="field" placeholder="@username" value={form.telegramId}
-                                       onChange={(e) => setForm((f) => ({...f, telegramId: e.target.value}))}/>
-                            </label>
+                                <div className="field opacity-80">{session.me?.telegramId}</div>
+                            </div>
 
                             <label className="md:col-span-2 flex flex-col gap-1 text-sm">
                                 <span className="lbl">Описание (необязательно)</span>
@@ -578,6 +611,8 @@ export function App() {
                     </div>
                 )}
             </Modal>
+            <LoginModal open={loginOpen} me={session.me} onClose={() => setLoginOpen(false)}
+                        onRefresh={handleSessionRefresh}/>
             <AdminModal open={adminOpen} onClose={() => setAdminOpen(false)} onLogin={(t) => admin.login(t)}/>
             <RulesModal open={rulesOpen} onClose={() => setRulesOpen(false)}/>
         </div>
@@ -1,4 +1,4 @@
-import {LayoutList, LogOut, RefreshCw, Shield, Table} from "lucide-react";
+import {LayoutList, LogIn, LogOut, RefreshCw, Shield, Table} from "lucide-react";
 import type {ViewMode} from "../types/bookings";
 import {cn} from "../utils/cn";
 
@@ -6,22 +6,28 @@ export function AppHeader({
                               loading,
                               view,
                               isAdmin,
+                              user,
                               onToggleAdd,
                               onRefresh,
                               onToggleView,
                               onAdminClick,
                               onAdminLogout,
                               onRulesClick,
+                              onLoginClick,
+                              onLogout,
                           }: {
     loading: boolean;
     view: ViewMode;
     isAdmin: boolean;
+    user: string | null; // ник или почта вошедшего, null - не вошли
     onToggleAdd: () => void;
     onRefresh: () => void;
     onToggleView: () => void;
     onAdminClick: () => void;
     onAdminLogout: () => void;
     onRulesClick: () => void;
+    onLoginClick: () => void;
+    onLogout: () => void;
 }) {
     return (
         <header className="sticky top-0 z-20 border-b border-zinc-700 bg-[#1e1f22]/80 backdrop-blur">
@@ -50,6 +56,18 @@ export function AppHeader({
                     </button>
                     */}
 
+                    {!user ? (
+                        <button onClick={onLoginClick} className="btn">
+                            <LogIn className="h-4 w-4 mr-2"/>
+                            Войти
+                        </button>
+                    ) : (
+                        <button onClick={onLogout} className="btn" title="Выйти">
+                            <LogOut className="h-4 w-4 mr-2"/>
+                            {user}
+                        </button>
+                    )}
+
                     {!isAdmin ? (
                         <button onClick={onAdminClick} className="btn btn-primary">
                             <Shield className="h-4 w-4 mr-2"/>
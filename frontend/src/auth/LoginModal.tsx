@@ -0,0 +1,173 @@
+import React, { useEffect, useState } from "react";
+import { AlertTriangle } from "lucide-react";
+
+import { Modal } from "../components/ui/Modal";
+import { cn } from "../utils/cn";
+import { normalizeApiError } from "../utils/errors";
+import * as api from "../api/auth";
+import type { Me, TelegramLink } from "../types/auth";
+
+type Step = "email" | "code" | "telegram" | "confirm";
+
+// LoginModal - вход по коду из письма и привязка Telegram через бота.
+// Без привязанного Telegram бэкенд не даёт создавать брони, поэтому после входа
+// окно сразу переходит к привязке.
+export function LoginModal({
+                               open,
+                               me,
+                               onClose,
+                               onRefresh,
+                           }: {
+    open: boolean;
+    me: Me | null;
+    onClose: () => void;
+    onRefresh: () => Promise<Me | null>;
+}) {
+    const [step, setStep] = useState<Step>("email");
+    const [email, setEmail] = useState("");
+    const [code, setCode] = useState("");
+    const [telegramId, setTelegramId] = useState("");
+    const [link, setLink] = useState<TelegramLink | null>(null);
+    const [busy, setBusy] = useState(false);
+    const [err, setErr] = useState("");
+
+    useEffect(() => {
+        if (!open) return;
+        setErr("");
+        setCode("");
+        setLink(null);
+        setStep(me ? "telegram" : "email");
+    }, [open]);
+
+    async function run(fn: () => Promise<void>) {
+        setErr("");
+        setBusy(true);
+        try {
+            await fn();
+        } catch (e: any) {
+            setErr(normalizeApiError(e));
+        } finally {
+            setBusy(false);
+        }
+    }
+
+    const submit = () =>
+        run(async () => {
+            switch (step) {
+                case "email":
+                    if (!email.trim()) throw new Error("Укажи корпоративную почту.");
+                    await api.requestLogin(email.trim());
+                    setStep("code");
+                    break;
+                case "code": {
+                    if (!code.trim()) throw new Error("Введи код из письма.");
+                    await api.verifyLogin(email.trim(), code.trim());
+                    const u = await onRefresh();
+                    if (u?.telegramId) onClose();
+                    else setStep("telegram");
+                    break;
+                }
+                case "telegram":
+                    if (!telegramId.trim()) throw new Error("Укажи Telegram ID.");
+                    setLink(await api.linkTelegram(telegramId.trim()));
+                    setStep("confirm");
+                    break;
+                case "confirm": {
+                    const u = await onRefresh();
+                    if (!u?.telegramId) throw new Error("Бот ещё не получил код. Отправь его и попробуй снова.");
+                    onClose();
+                    break;
+                }
+            }
+        });
+
+    return (
+        <Modal open={open} onClose={onClose}>
+            <div className="space-y-3">
+                <div className="text-lg font-semibold">
+                    {step === "email" || step === "code" ? "Вход" : "Привязка Telegram"}
+                </div>
+                <div className="text-sm text-zinc-400">
+                    {step === "email" && "Пришлём код на корпоративную почту."}
+                    {step === "code" && `Код отправлен на ${email.trim()}. Можно и просто перейти по ссылке из письма.`}
+                    {step === "telegram" && "Брони записываются на твой Telegram. Укажи ник - бот попросит его подтвердить."}
+                    {step === "confirm" && link && (
+                        <>
+                            Отправь боту команду <span className="font-mono text-zinc-200">/link {link.code}</span>
+                            {link.url && (
+                                <>
+                                    {" "}или <a className="underline" href={link.url} target="_blank" rel="noreferrer">открой бота по ссылке</a>
+                                </>
+                            )}
+                            {" "}до {new Date(link.expiresAt).toLocaleTimeString("ru-RU", { hour: "2-digit", minute: "2-digit" })}, затем нажми «Готово».
+                        </>
+                    )}
+                </div>
+
+                {err && (
+                    <div className="flex items-start gap-2 rounded-xl border border-red-300/30 bg-rose-950/20 text-rose-200 px-3 py-2 text-sm">
+                        <AlertTriangle className="h-4 w-4 mt-0.5 shrink-0" />
+                        <div>{err}</div>
+                    </div>
+                )}
+
+                {step === "email" && (
+                    <label className="flex flex-col gap-1 text-sm">
+                        <span className="lbl">Почта</span>
+                        <input
+                            className="field"
+                            type="email"
+                            value={email}
+                            onChange={(e) => setEmail(e.target.value)}
+                            placeholder="student@edu.hse.ru"
+                            onKeyDown={(e) => e.key === "Enter" && submit()}
+                        />
+                    </label>
+                )}
+                {step === "code" && (
+                    <label className="flex flex-col gap-1 text-sm">
+                        <span className="lbl">Код из письма</span>
+                        <input
+                            className={cn("field", "font-mono")}
+                            inputMode="numeric"
+                            value={code}
+                            onChange={(e) => setCode(e.target.value)}
+                            onKeyDown={(e) => e.key === "Enter" && submit()}
+                        />
+                    </label>
+                )}
+                {step === "telegram" && (
+                    <label className="flex flex-col gap-1 text-sm">
+                        <span className="lbl">Telegram ID</span>
+                        <input
+                            className="field"
+                            value={telegramId}
+                            onChange={(e) => setTelegramId(e.target.value)}
+                            placeholder="@username"
+                            onKeyDown={(e) => e.key === "Enter" && submit()}
+                        />
+                    </label>
+                )}
+
+                <div className="flex gap-2 pt-2">
+                    <button className="btn btn-primary" onClick={submit} disabled={busy}>
+                        {step === "email" ? "Получить код" : step === "confirm" ? "Готово" : "Продолжить"}
+                    </button>
+                    {step === "code" && (
+                        <button className="btn" onClick={() => setStep("email")} disabled={busy}>
+                            Другая почта
+                        </button>
+                    )}
+                    {step === "confirm" && (
+                        <button className="btn" onClick={() => setStep("telegram")} disabled={busy}>
+                            Другой ник
+                        </button>
+                    )}
+                    <button className="btn" onClick={onClose}>
+                        Отмена
+                    </button>
+                </div>
+            </div>
+        </Modal>
+    );
+}
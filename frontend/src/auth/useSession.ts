@@ -0,0 +1,39 @@
+import { useCallback, useEffect, useMemo, useState } from "react";
+
+import * as api from "../api/auth";
+import type { Me } from "../types/auth";
+
+export function useSession() {
+    const [me, setMe] = useState<Me | null>(null);
+
+    const refresh = useCallback(async () => {
+        try {
+            const u = await api.fetchMe();
+            setMe(u);
+            return u;
+        } catch {
+            setMe(null);
+            return null;
+        }
+    }, []);
+
+    useEffect(() => {
+        refresh();
+    }, [refresh]);
+
+    const logout = useCallback(async () => {
+        try {
+            await api.logout();
+        } finally {
+            setMe(null);
+        }
+    }, []);
+
+    // бронировать можно только с подтверждённым в боте Telegram
+    const canBook = !!me?.telegramId;
+
+    return useMemo(
+        () => ({ me, canBook, refresh, logout }),
+        [me, canBook, refresh, logout],
+    );
+}
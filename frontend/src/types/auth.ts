@@ -0,0 +1,13 @@
+export type Me = {
+    id: string;
+    email: string;
+    // владелец броней; пока ник не подтверждён в боте, бронировать нельзя
+    telegramId?: string;
+    isAdmin: boolean;
+};
+
+export type TelegramLink = {
+    code: string;
+    url?: string; // t.me/<бот>?start=<код>, если бэкенд знает имя бота
+    expiresAt: string;
+};
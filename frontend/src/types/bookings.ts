@@ -10,6 +10,8 @@ export type Bookings = {
     isPrivate: boolean;
     description?: string;
     canManage?: boolean;
+    // anonymous-busy: чужая частная бронь, вместо названия «Занято», владельца нет
+    visibility?: "public" | "private" | "anonymous-busy";
 };
 
 export type RoomFilter = "all" | Room;
@@ -21,7 +23,6 @@ export type CreateBookingPayload = {
     end: string;
     room: Room;
     title: string;
-    telegramId: string;
     isPrivate: boolean;
     description?: string;
 };